@@ -0,0 +1,186 @@
+package apikeys
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"ecommerce-backend/internal/database"
+	"ecommerce-backend/internal/models"
+	"ecommerce-backend/internal/utils"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// keyPrefix marks API keys so they are recognisable in configs and secret scanners
+const keyPrefix = "ek_"
+
+// displayPrefixLength is how much of a key is kept in the clear to tell keys apart
+const displayPrefixLength = len(keyPrefix) + 8
+
+// lastUsedInterval limits how often last-used tracking writes to the database
+const lastUsedInterval = time.Minute
+
+var (
+	// ErrInvalidKey is returned for unknown, expired and revoked keys
+	ErrInvalidKey = errors.New("Invalid API key")
+	// ErrNotFound is returned when revoking a key that does not exist
+	ErrNotFound = errors.New("API key not found")
+)
+
+// Generate returns a new key, the prefix shown in listings and the digest to store
+func Generate() (key, prefix, hash string, err error) {
+	token, err := utils.GenerateRandomToken()
+	if err != nil {
+		return "", "", "", err
+	}
+	key = keyPrefix + token
+	return key, key[:displayPrefixLength], utils.HashToken(key), nil
+}
+
+// Store issues, checks and revokes API keys
+type Store struct {
+	db *database.Client
+}
+
+// NewStore creates a new API key store
+func NewStore(db *database.Client) *Store {
+	return &Store{db: db}
+}
+
+// Create issues a key and returns it with its record. The key cannot be recovered later.
+func (s *Store) Create(ctx context.Context, name string, permissions []models.Permission, createdBy primitive.ObjectID, expiresAt *time.Time) (models.APIKey, string, error) {
+	key, prefix, hash, err := Generate()
+	if err != nil {
+		return models.APIKey{}, "", err
+	}
+
+	apiKey := models.APIKey{
+		ID:          primitive.NewObjectID(),
+		Name:        name,
+		Prefix:      prefix,
+		KeyHash:     hash,
+		Permissions: permissions,
+		CreatedBy:   createdBy,
+		CreatedAt:   time.Now(),
+		ExpiresAt:   expiresAt,
+	}
+	if _, err := s.db.GetCollection("api_keys").InsertOne(ctx, apiKey); err != nil {
+		return models.APIKey{}, "", err
+	}
+	return apiKey, key, nil
+}
+
+// Authenticate returns the active key matching the presented key and records its use.
+// Expired and revoked keys and keys issued by a deactivated user are rejected.
+func (s *Store) Authenticate(ctx context.Context, key, ip string) (*models.APIKey, error) {
+	now := time.Now()
+	filter := bson.M{
+		"key_hash":   utils.HashToken(key),
+		"revoked_at": bson.M{"$exists": false},
+		"$or": bson.A{
+			bson.M{"expires_at": nil},
+			bson.M{"expires_at": bson.M{"$gt": now}},
+		},
+	}
+
+	var apiKey models.APIKey
+	err := s.db.GetCollection("api_keys").FindOne(ctx, filter).Decode(&apiKey)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrInvalidKey
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Keys stop working when the admin who issued them is deactivated
+	var issuer models.User
+	err = s.db.GetCollection("users").FindOne(ctx, bson.M{"_id": apiKey.CreatedBy, "is_active": true}).Decode(&issuer)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrInvalidKey
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// A key never holds more than its issuer does now, so it loses permissions taken from
+	// the issuer's role after it was issued
+	rolePermissions, err := s.rolePermissions(ctx, issuer.Role)
+	if err != nil {
+		return nil, err
+	}
+	apiKey.Permissions = grantedPermissions(apiKey.Permissions, rolePermissions)
+
+	// Busy integrations would otherwise write on every request
+	if apiKey.LastUsedAt == nil || now.Sub(*apiKey.LastUsedAt) >= lastUsedInterval {
+		update := bson.M{"$set": bson.M{"last_used_at": now, "last_used_ip": ip}}
+		if _, err := s.db.GetCollection("api_keys").UpdateOne(ctx, bson.M{"_id": apiKey.ID}, update); err != nil {
+			return nil, err
+		}
+	}
+
+	return &apiKey, nil
+}
+
+// rolePermissions returns the permissions of a role. The admin role has every permission;
+// unknown roles have none.
+func (s *Store) rolePermissions(ctx context.Context, role models.Role) ([]models.Permission, error) {
+	if role == models.RoleAdmin {
+		return models.AllPermissions, nil
+	}
+
+	var definition models.RoleDefinition
+	err := s.db.GetCollection("roles").FindOne(ctx, bson.M{"name": role}).Decode(&definition)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	return definition.Permissions, err
+}
+
+// grantedPermissions returns the key's permissions that the issuer's role still grants
+func grantedPermissions(key, role []models.Permission) []models.Permission {
+	granted := []models.Permission{}
+	for _, permission := range key {
+		for _, held := range role {
+			if permission == held {
+				granted = append(granted, permission)
+				break
+			}
+		}
+	}
+	return granted
+}
+
+// List returns every key, including expired and revoked ones, most recent first
+func (s *Store) List(ctx context.Context) ([]models.APIKey, error) {
+	findOptions := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+	cursor, err := s.db.GetCollection("api_keys").Find(ctx, bson.M{}, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	keys := []models.APIKey{}
+	if err := cursor.All(ctx, &keys); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// Revoke stops a key from being accepted. Revoking a revoked key is not an error.
+func (s *Store) Revoke(ctx context.Context, id primitive.ObjectID) error {
+	result, err := s.db.GetCollection("api_keys").UpdateOne(ctx,
+		bson.M{"_id": id},
+		[]bson.M{{"$set": bson.M{"revoked_at": bson.M{"$ifNull": bson.A{"$revoked_at", time.Now()}}}}},
+	)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package apikeys
+
+import (
+	"strings"
+	"testing"
+
+	"ecommerce-backend/internal/models"
+	"ecommerce-backend/internal/utils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerate(t *testing.T) {
+	key, prefix, hash, err := Generate()
+	require.NoError(t, err)
+
+	assert.True(t, strings.HasPrefix(key, "ek_"))
+	assert.Len(t, key, len("ek_")+43)
+	assert.Len(t, prefix, displayPrefixLength)
+	assert.True(t, strings.HasPrefix(key, prefix))
+	assert.Equal(t, utils.HashToken(key), hash)
+	assert.NotContains(t, hash, key)
+
+	other, _, _, err := Generate()
+	require.NoError(t, err)
+	assert.NotEqual(t, key, other)
+}
+
+func TestGrantedPermissions(t *testing.T) {
+	key := []models.Permission{models.PermProductsWrite, models.PermOrdersRefund}
+
+	assert.Equal(t, key, grantedPermissions(key, models.AllPermissions))
+	assert.Equal(t, []models.Permission{models.PermProductsWrite}, grantedPermissions(key, []models.Permission{models.PermDashboardRead, models.PermProductsWrite}))
+	assert.Empty(t, grantedPermissions(key, nil))
+}
@@ -0,0 +1,286 @@
+package audit
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"reflect"
+	"time"
+
+	"ecommerce-backend/internal/database"
+	"ecommerce-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// resourceIDKey is the context key handlers set to the ID of a resource they created
+const resourceIDKey = "audit_resource_id"
+
+// redacted replaces the values of secret fields in recorded changes
+const redacted = "[redacted]"
+
+// secretFields are never written to the audit log, only the fact that they changed
+var secretFields = map[string]bool{
+	"password":           true,
+	"mfa_secret":         true,
+	"mfa_pending_secret": true,
+	"recovery_codes":     true,
+	"token_hash":         true,
+	"key_hash":           true,
+}
+
+// ignoredFields change on every write and are left out of diffs
+var ignoredFields = map[string]bool{
+	"updated_at": true,
+}
+
+// KeyFunc returns the filter selecting the document with a resource ID, or nil if the ID
+// does not identify a document
+type KeyFunc func(id string) bson.M
+
+// ByObjectID selects documents by their ObjectID
+func ByObjectID(id string) bson.M {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil
+	}
+	return bson.M{"_id": objID}
+}
+
+// ByField selects documents whose field equals the resource ID
+func ByField(field string) KeyFunc {
+	return func(id string) bson.M {
+		if id == "" {
+			return nil
+		}
+		return bson.M{field: id}
+	}
+}
+
+// SetResourceID records the ID of a resource created by the request, for routes that
+// have no ID in their path
+func SetResourceID(c *gin.Context, id string) {
+	c.Set(resourceIDKey, id)
+}
+
+// Logger writes the append-only audit log of admin changes
+type Logger struct {
+	db  *database.Client
+	log *slog.Logger
+}
+
+// NewLogger creates a new audit logger
+func NewLogger(db *database.Client, log *slog.Logger) *Logger {
+	return &Logger{db: db, log: log}
+}
+
+// Track records every successful write to a resource. The document is read before and
+// after the request, from the collection and with the key built from the path parameter,
+// and the changed fields are stored with the actor, IP and request ID.
+func (l *Logger) Track(resource, collection, param string, key KeyFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			c.Next()
+			return
+		}
+
+		var id string
+		if param != "" {
+			id = c.Param(param)
+		}
+		before := l.snapshot(collection, key(id))
+
+		c.Next()
+
+		// Rejected requests change nothing. Handler errors are only rendered once the
+		// error middleware further out sees them, so check for those as well.
+		if c.Writer.Status() >= http.StatusBadRequest || len(c.Errors) > 0 {
+			return
+		}
+
+		if id == "" {
+			id = c.GetString(resourceIDKey)
+		}
+		after := l.snapshot(collection, key(id))
+
+		actorID, _ := primitive.ObjectIDFromHex(c.GetString("user_id"))
+		entry := models.AuditEntry{
+			ID:         primitive.NewObjectID(),
+			ActorID:    actorID,
+			ActorEmail: c.GetString("user_email"),
+			APIKeyID:   c.GetString("api_key_id"),
+			Action:     c.Request.Method + " " + c.FullPath(),
+			Resource:   resource,
+			ResourceID: id,
+			Changes:    Diff(before, after),
+			Status:     c.Writer.Status(),
+			IP:         c.ClientIP(),
+			RequestID:  c.GetString("request_id"),
+			CreatedAt:  time.Now(),
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		if _, err := l.db.GetCollection("audit_log").InsertOne(ctx, entry); err != nil {
+			l.log.Error("Failed to write audit log", "action", entry.Action, "resource_id", id, "error", err)
+		}
+	}
+}
+
+// snapshot reads the document matching the filter, or returns nil if there is none
+func (l *Logger) snapshot(collection string, filter bson.M) bson.M {
+	if filter == nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var document bson.M
+	err := l.db.GetCollection(collection).FindOne(ctx, filter).Decode(&document)
+	if err != nil {
+		if err != mongo.ErrNoDocuments {
+			l.log.Error("Failed to read document for audit log", "collection", collection, "error", err)
+		}
+		return nil
+	}
+	return normalize(document).(bson.M)
+}
+
+// Query filters the audit log
+type Query struct {
+	ActorID    primitive.ObjectID // Zero matches any actor
+	ActorEmail string
+	Resource   string
+	ResourceID string
+	From       time.Time // Zero means no lower bound
+	To         time.Time // Zero means no upper bound
+	Page       int
+	Limit      int
+}
+
+// List returns the entries matching the query, newest first, and the total number of matches
+func (l *Logger) List(ctx context.Context, q Query) ([]models.AuditEntry, int64, error) {
+	filter := bson.M{}
+	if !q.ActorID.IsZero() {
+		filter["actor_id"] = q.ActorID
+	}
+	if q.ActorEmail != "" {
+		filter["actor_email"] = q.ActorEmail
+	}
+	if q.Resource != "" {
+		filter["resource"] = q.Resource
+	}
+	if q.ResourceID != "" {
+		filter["resource_id"] = q.ResourceID
+	}
+	if !q.From.IsZero() || !q.To.IsZero() {
+		createdAt := bson.M{}
+		if !q.From.IsZero() {
+			createdAt["$gte"] = q.From
+		}
+		if !q.To.IsZero() {
+			createdAt["$lt"] = q.To
+		}
+		filter["created_at"] = createdAt
+	}
+
+	collection := l.db.GetCollection("audit_log")
+	total, err := collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	findOptions := options.Find().
+		SetSkip(int64((q.Page - 1) * q.Limit)).
+		SetLimit(int64(q.Limit)).
+		SetSort(bson.D{{Key: "created_at", Value: -1}})
+
+	cursor, err := collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	entries := []models.AuditEntry{}
+	if err := cursor.All(ctx, &entries); err != nil {
+		return nil, 0, err
+	}
+
+	// Nested documents decode as ordered key/value lists; turn them back into objects
+	for i := range entries {
+		for field, change := range entries[i].Changes {
+			entries[i].Changes[field] = models.AuditChange{
+				Before: normalize(change.Before),
+				After:  normalize(change.After),
+			}
+		}
+	}
+	return entries, total, nil
+}
+
+// Diff returns the fields that differ between two versions of a document. Secret fields
+// are reported as changed without their values.
+func Diff(before, after bson.M) map[string]models.AuditChange {
+	changes := make(map[string]models.AuditChange)
+	for field, value := range before {
+		if ignoredFields[field] {
+			continue
+		}
+		if next, ok := after[field]; !ok || !reflect.DeepEqual(value, next) {
+			changes[field] = models.AuditChange{Before: value, After: next}
+		}
+	}
+	for field, value := range after {
+		if _, ok := before[field]; !ok && !ignoredFields[field] {
+			changes[field] = models.AuditChange{After: value}
+		}
+	}
+
+	for field, change := range changes {
+		if secretFields[field] {
+			if change.Before != nil {
+				change.Before = redacted
+			}
+			if change.After != nil {
+				change.After = redacted
+			}
+			changes[field] = change
+		}
+	}
+
+	if len(changes) == 0 {
+		return nil
+	}
+	return changes
+}
+
+// normalize converts decoded BSON documents and arrays into maps and slices
+func normalize(value interface{}) interface{} {
+	switch v := value.(type) {
+	case bson.M:
+		for key, item := range v {
+			v[key] = normalize(item)
+		}
+		return v
+	case primitive.D:
+		m := make(bson.M, len(v))
+		for _, element := range v {
+			m[element.Key] = normalize(element.Value)
+		}
+		return m
+	case primitive.A:
+		for i, item := range v {
+			v[i] = normalize(item)
+		}
+		return []interface{}(v)
+	default:
+		return v
+	}
+}
@@ -0,0 +1,99 @@
+package audit
+
+import (
+	"testing"
+
+	"ecommerce-backend/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		before   bson.M
+		after    bson.M
+		expected map[string]models.AuditChange
+	}{
+		{
+			name:   "changed field",
+			before: bson.M{"name": "Phone", "price": int64(1000), "updated_at": 1},
+			after:  bson.M{"name": "Phone", "price": int64(1200), "updated_at": 2},
+			expected: map[string]models.AuditChange{
+				"price": {Before: int64(1000), After: int64(1200)},
+			},
+		},
+		{
+			name:  "created",
+			after: bson.M{"name": "Phone"},
+			expected: map[string]models.AuditChange{
+				"name": {After: "Phone"},
+			},
+		},
+		{
+			name:   "deleted",
+			before: bson.M{"name": "Phone"},
+			expected: map[string]models.AuditChange{
+				"name": {Before: "Phone"},
+			},
+		},
+		{
+			name:   "added and removed fields",
+			before: bson.M{"old": true},
+			after:  bson.M{"new": true},
+			expected: map[string]models.AuditChange{
+				"old": {Before: true},
+				"new": {After: true},
+			},
+		},
+		{
+			name:   "nested documents",
+			before: bson.M{"price": bson.M{"amount": int64(1000), "currency": "USD"}},
+			after:  bson.M{"price": bson.M{"amount": int64(1000), "currency": "USD"}},
+		},
+		{
+			name:   "secret fields",
+			before: bson.M{"password": "old-hash", "is_active": true},
+			after:  bson.M{"password": "", "is_active": true},
+			expected: map[string]models.AuditChange{
+				"password": {Before: redacted, After: redacted},
+			},
+		},
+		{
+			name:   "nothing changed",
+			before: bson.M{"name": "Phone", "updated_at": 1},
+			after:  bson.M{"name": "Phone", "updated_at": 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Diff(tt.before, tt.after))
+		})
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	value := bson.M{
+		"price": primitive.D{{Key: "amount", Value: int64(1000)}, {Key: "currency", Value: "USD"}},
+		"tags":  primitive.A{"a", primitive.D{{Key: "b", Value: 1}}},
+	}
+
+	assert.Equal(t, bson.M{
+		"price": bson.M{"amount": int64(1000), "currency": "USD"},
+		"tags":  []interface{}{"a", bson.M{"b": 1}},
+	}, normalize(value))
+}
+
+func TestKeyFuncs(t *testing.T) {
+	id := primitive.NewObjectID()
+	assert.Equal(t, bson.M{"_id": id}, ByObjectID(id.Hex()))
+	assert.Nil(t, ByObjectID("not-an-id"))
+	assert.Nil(t, ByObjectID(""))
+
+	byName := ByField("name")
+	assert.Equal(t, bson.M{"name": "support"}, byName("support"))
+	assert.Nil(t, byName(""))
+}
@@ -3,22 +3,73 @@ package config
 import (
 	"os"
 	"strconv"
+	"strings"
 	"time"
 )
 
 // Config holds all configuration for our application
 type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
-	JWT      JWTConfig
+	Environment Environment
+	Server      ServerConfig
+	Database    DatabaseConfig
+	JWT         JWTConfig
+	Auth        AuthConfig
+	Payment     PaymentConfig
+	Catalog     CatalogConfig
+	Tax         TaxConfig
+	Mail        MailConfig
+	OIDC        OIDCConfig
+	RateLimit   RateLimitConfig
+	CORS        CORSConfig
+	Security    SecurityConfig
+
+	settings []Setting
+}
+
+// Environment is the mode the application runs in. It decides the gin mode, the log
+// format and level, whether error details reach clients and whether insecure defaults
+// are allowed.
+type Environment string
+
+const (
+	EnvDevelopment Environment = "development"
+	EnvStaging     Environment = "staging"
+	EnvProduction  Environment = "production"
+	EnvTest        Environment = "test"
+)
+
+// IsValid checks if the environment exists
+func (e Environment) IsValid() bool {
+	switch e {
+	case EnvDevelopment, EnvStaging, EnvProduction, EnvTest:
+		return true
+	}
+	return false
+}
+
+// IsProduction reports whether the application serves real customers
+func (e Environment) IsProduction() bool {
+	return e == EnvProduction
+}
+
+// ExposeErrors reports whether responses may include internal error details
+func (e Environment) ExposeErrors() bool {
+	return e == EnvDevelopment || e == EnvTest
+}
+
+// AllowsInsecureDefaults reports whether default secrets and development settings may
+// be used. Staging is reachable from outside, so it is held to production standards.
+func (e Environment) AllowsInsecureDefaults() bool {
+	return e == EnvDevelopment || e == EnvTest
 }
 
 // ServerConfig holds server configuration
 type ServerConfig struct {
-	Port         string
-	ReadTimeout  time.Duration
-	WriteTimeout time.Duration
-	IdleTimeout  time.Duration
+	Port           string
+	ReadTimeout    time.Duration
+	WriteTimeout   time.Duration
+	IdleTimeout    time.Duration
+	TrustedProxies []string // Proxies whose X-Forwarded-For header is trusted for the client IP
 }
 
 // DatabaseConfig holds database configuration
@@ -35,49 +86,265 @@ type JWTConfig struct {
 	Expiration time.Duration
 }
 
-// Load loads configuration from environment variables
-func Load() *Config {
-	return &Config{
+// AuthConfig holds account security configuration
+type AuthConfig struct {
+	RequireVerifiedEmail bool          // Block checkout until the user's email address is verified
+	LoginMaxFailures     int           // Failed logins that lock an account
+	LoginLockoutDuration time.Duration // How long a locked account stays locked
+	RequireAdminMFA      bool          // Refuse admin routes to sessions that did not pass two-factor authentication
+	MFAIssuer            string        // Name shown in authenticator apps
+}
+
+// PaymentConfig holds payment provider configuration
+type PaymentConfig struct {
+	Provider          string
+	Currency          string
+	FakeWebhookSecret string
+	OrderTimeout      time.Duration // Unpaid orders are cancelled and their stock given back after this long
+}
+
+// CatalogConfig holds product catalog configuration
+type CatalogConfig struct {
+	InitialStock int // Stock given to products on sale that were created before stock was tracked
+}
+
+// TaxConfig holds tax calculation configuration
+type TaxConfig struct {
+	PricesIncludeTax bool
+	DefaultCountry   string
+	DefaultRegion    string
+}
+
+// MailConfig holds outgoing email configuration
+type MailConfig struct {
+	Driver string // "log" or "file"
+	Dir    string // Output directory of the file driver
+	From   string
+	AppURL string // Base URL of the storefront, used for links in emails
+}
+
+// OIDCConfig holds the OpenID Connect providers users can sign in with
+type OIDCConfig struct {
+	Providers []OIDCProviderConfig
+}
+
+// OIDCProviderConfig holds the client registration with one OpenID Connect provider
+type OIDCProviderConfig struct {
+	Name         string // Used in URLs, e.g. /api/auth/oidc/google/login
+	Issuer       string // Base URL of the provider's discovery document
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string // Storefront page that receives the code and state
+	Scopes       []string
+}
+
+// RateLimitConfig holds the request rates allowed per route group. 0 disables a limit.
+type RateLimitConfig struct {
+	AuthPerMinute   int // Sign in, registration and password reset, per client IP
+	PublicPerMinute int // Public catalog, sliders and shipping quotes, per client IP
+	APIPerMinute    int // Signed-in and admin requests, per user or API key
+}
+
+// CORSConfig holds the browser origins allowed to call the API
+type CORSConfig struct {
+	AllowedOrigins   []string // Exact origins, "https://*.example.com" for any subdomain, or "*"
+	AllowedMethods   []string
+	AllowedHeaders   []string // Request headers preflights may ask for
+	ExposedHeaders   []string // Response headers pages may read
+	AllowCredentials bool
+	MaxAge           time.Duration // How long browsers may cache a preflight
+}
+
+// SecurityConfig holds the security headers sent with every response. Empty values
+// leave a header out.
+type SecurityConfig struct {
+	HSTSMaxAge            time.Duration // How long browsers must only use HTTPS; 0 disables HSTS
+	HSTSIncludeSubdomains bool
+	ReferrerPolicy        string
+	FrameOptions          string // X-Frame-Options
+	ContentSecurityPolicy string
+}
+
+// Load loads configuration from environment variables, layered over the optional YAML or
+// TOML file named by CONFIG_FILE. It reports every malformed or unknown setting, and
+// insecure defaults in production. The configuration is returned even when invalid, so
+// it can be inspected.
+func Load() (*Config, error) {
+	l, err := newLoader(os.Getenv("CONFIG_FILE"))
+	if err != nil {
+		return nil, err
+	}
+
+	// Unset, the environment is production, so that a deployment missing ENV refuses the
+	// insecure defaults instead of accepting them
+	env := Environment(l.getEnv("ENV", string(EnvProduction)))
+
+	// Browsers remember HSTS, so it is off by default where plain HTTP is expected
+	hstsMaxAge := 180 * 24 * time.Hour
+	if env.AllowsInsecureDefaults() {
+		hstsMaxAge = 0
+	}
+
+	cfg := &Config{
+		Environment: env,
 		Server: ServerConfig{
-			Port:         getEnv("PORT", "8080"),
-			ReadTimeout:  getDurationEnv("READ_TIMEOUT", 10*time.Second),
-			WriteTimeout: getDurationEnv("WRITE_TIMEOUT", 10*time.Second),
-			IdleTimeout:  getDurationEnv("IDLE_TIMEOUT", 120*time.Second),
+			Port:           l.getEnv("PORT", "8080"),
+			ReadTimeout:    l.getDurationEnv("READ_TIMEOUT", 10*time.Second),
+			WriteTimeout:   l.getDurationEnv("WRITE_TIMEOUT", 10*time.Second),
+			IdleTimeout:    l.getDurationEnv("IDLE_TIMEOUT", 120*time.Second),
+			TrustedProxies: l.getListEnv("TRUSTED_PROXIES", "127.0.0.1,::1"),
 		},
 		Database: DatabaseConfig{
-			URI:         getEnv("MONGODB_URI", "mongodb://localhost:27017"),
-			Database:    getEnv("DATABASE_NAME", "ecommerce"),
-			Timeout:     getDurationEnv("DB_TIMEOUT", 10*time.Second),
-			MaxPoolSize: getUint64Env("DB_MAX_POOL_SIZE", 100),
+			URI:         l.getEnv("MONGODB_URI", "mongodb://localhost:27017"),
+			Database:    l.getEnv("DATABASE_NAME", "ecommerce"),
+			Timeout:     l.getDurationEnv("DB_TIMEOUT", 10*time.Second),
+			MaxPoolSize: l.getUint64Env("DB_MAX_POOL_SIZE", 100),
 		},
 		JWT: JWTConfig{
-			Secret:     getEnv("JWT_SECRET", "default-secret-key"),
-			Expiration: getDurationEnv("JWT_EXPIRATION", 24*time.Hour),
+			Secret:     l.getEnv("JWT_SECRET", "default-secret-key"),
+			Expiration: l.getDurationEnv("JWT_EXPIRATION", 24*time.Hour),
+		},
+		Auth: AuthConfig{
+			RequireVerifiedEmail: l.getBoolEnv("REQUIRE_VERIFIED_EMAIL", true),
+			LoginMaxFailures:     l.getIntEnv("LOGIN_MAX_FAILURES", 10),
+			LoginLockoutDuration: l.getDurationEnv("LOGIN_LOCKOUT_DURATION", 15*time.Minute),
+			RequireAdminMFA:      l.getBoolEnv("REQUIRE_ADMIN_MFA", false),
+			MFAIssuer:            l.getEnv("MFA_ISSUER", "E-commerce"),
+		},
+		Payment: PaymentConfig{
+			Provider:          l.getEnv("PAYMENT_PROVIDER", "fake"),
+			Currency:          l.getEnv("PAYMENT_CURRENCY", "USD"),
+			FakeWebhookSecret: l.getEnv("FAKE_PAYMENT_WEBHOOK_SECRET", "fake-webhook-secret"),
+			OrderTimeout:      l.getDurationEnv("PAYMENT_ORDER_TIMEOUT", time.Hour),
+		},
+		Catalog: CatalogConfig{
+			InitialStock: l.getIntEnv("CATALOG_INITIAL_STOCK", 100),
+		},
+		Tax: TaxConfig{
+			PricesIncludeTax: l.getBoolEnv("TAX_PRICES_INCLUDE_TAX", false),
+			DefaultCountry:   l.getEnv("TAX_DEFAULT_COUNTRY", "US"),
+			DefaultRegion:    l.getEnv("TAX_DEFAULT_REGION", ""),
+		},
+		Mail: MailConfig{
+			Driver: l.getEnv("MAIL_DRIVER", "log"),
+			Dir:    l.getEnv("MAIL_DIR", "./mail"),
+			From:   l.getEnv("MAIL_FROM", "no-reply@localhost"),
+			AppURL: l.getEnv("APP_URL", "http://localhost:3000"),
+		},
+		OIDC: OIDCConfig{
+			Providers: l.getOIDCProviders(),
+		},
+		RateLimit: RateLimitConfig{
+			AuthPerMinute:   l.getIntEnv("RATE_LIMIT_AUTH_PER_MINUTE", 20),
+			PublicPerMinute: l.getIntEnv("RATE_LIMIT_PUBLIC_PER_MINUTE", 300),
+			APIPerMinute:    l.getIntEnv("RATE_LIMIT_API_PER_MINUTE", 600),
+		},
+		CORS: CORSConfig{
+			AllowedOrigins:   l.getListEnv("CORS_ALLOWED_ORIGINS", l.getEnv("APP_URL", "http://localhost:3000")),
+			AllowedMethods:   l.getListEnv("CORS_ALLOWED_METHODS", "GET,POST,PUT,PATCH,DELETE,OPTIONS"),
+			AllowedHeaders:   l.getListEnv("CORS_ALLOWED_HEADERS", "Content-Type,Authorization,Accept-Currency,X-API-Key,X-Request-ID"),
+			ExposedHeaders:   l.getListEnv("CORS_EXPOSED_HEADERS", "X-Request-ID,X-RateLimit-Limit,X-RateLimit-Remaining,X-RateLimit-Reset,Retry-After"),
+			AllowCredentials: l.getBoolEnv("CORS_ALLOW_CREDENTIALS", false),
+			MaxAge:           l.getDurationEnv("CORS_MAX_AGE", 12*time.Hour),
+		},
+		Security: SecurityConfig{
+			HSTSMaxAge:            l.getDurationEnv("HSTS_MAX_AGE", hstsMaxAge),
+			HSTSIncludeSubdomains: l.getBoolEnv("HSTS_INCLUDE_SUBDOMAINS", false),
+			ReferrerPolicy:        l.getEnv("REFERRER_POLICY", "strict-origin-when-cross-origin"),
+			FrameOptions:          l.getEnv("FRAME_OPTIONS", "DENY"),
+			ContentSecurityPolicy: l.getEnv("CONTENT_SECURITY_POLICY", "default-src 'none'; frame-ancestors 'none'"),
 		},
 	}
+
+	l.checkUnknown()
+	cfg.validate(l)
+	cfg.settings = l.settings()
+	return cfg, l.err()
 }
 
-func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
+// getOIDCProviders reads the providers named in OIDC_PROVIDERS, each configured with
+// OIDC_<NAME>_ISSUER, _CLIENT_ID, _CLIENT_SECRET, _REDIRECT_URL and _SCOPES
+func (l *loader) getOIDCProviders() []OIDCProviderConfig {
+	var providers []OIDCProviderConfig
+	for _, name := range l.getListEnv("OIDC_PROVIDERS", "") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		prefix := "OIDC_" + strings.ToUpper(name) + "_"
+		providers = append(providers, OIDCProviderConfig{
+			Name:         name,
+			Issuer:       l.getEnv(prefix+"ISSUER", ""),
+			ClientID:     l.getEnv(prefix+"CLIENT_ID", ""),
+			ClientSecret: l.getEnv(prefix+"CLIENT_SECRET", ""),
+			RedirectURL:  l.getEnv(prefix+"REDIRECT_URL", ""),
+			Scopes:       strings.Fields(l.getEnv(prefix+"SCOPES", "openid email profile")),
+		})
 	}
-	return defaultValue
+	return providers
+}
+
+// Settings returns every setting with its effective value and source, sorted by key,
+// with secrets redacted
+func (c *Config) Settings() []Setting {
+	return c.settings
 }
 
-func getDurationEnv(key string, defaultValue time.Duration) time.Duration {
-	if value := os.Getenv(key); value != "" {
-		if duration, err := time.ParseDuration(value); err == nil {
-			return duration
+// validate checks values that parse but make no sense, and refuses insecure defaults
+// in staging and production
+func (c *Config) validate(l *loader) {
+	if port, err := strconv.Atoi(c.Server.Port); err != nil || port < 1 || port > 65535 {
+		l.errorf("PORT: invalid port %q", c.Server.Port)
+	}
+	if c.Payment.OrderTimeout <= 0 {
+		l.errorf("PAYMENT_ORDER_TIMEOUT: must be positive, got %s", c.Payment.OrderTimeout)
+	}
+	if c.Catalog.InitialStock < 0 {
+		l.errorf("CATALOG_INITIAL_STOCK: must not be negative, got %d", c.Catalog.InitialStock)
+	}
+	if !c.Environment.IsValid() {
+		l.errorf("ENV: must be development, staging, production or test, got %q", c.Environment)
+	}
+	if c.Mail.Driver != "log" && c.Mail.Driver != "file" {
+		l.errorf("MAIL_DRIVER: must be log or file, got %q", c.Mail.Driver)
+	}
+	if c.JWT.Expiration <= 0 {
+		l.errorf("JWT_EXPIRATION: must be positive")
+	}
+	if c.Auth.LoginMaxFailures < 0 {
+		l.errorf("LOGIN_MAX_FAILURES: must not be negative")
+	}
+	if c.RateLimit.AuthPerMinute < 0 || c.RateLimit.PublicPerMinute < 0 || c.RateLimit.APIPerMinute < 0 {
+		l.errorf("RATE_LIMIT_*_PER_MINUTE: must not be negative")
+	}
+	for _, provider := range c.OIDC.Providers {
+		prefix := "OIDC_" + strings.ToUpper(provider.Name) + "_"
+		if provider.Issuer == "" || provider.ClientID == "" || provider.RedirectURL == "" {
+			l.errorf("%sISSUER, %sCLIENT_ID and %sREDIRECT_URL are required", prefix, prefix, prefix)
+		}
+	}
+
+	for _, origin := range c.CORS.AllowedOrigins {
+		if origin == "*" && c.CORS.AllowCredentials {
+			l.errorf("CORS_ALLOW_CREDENTIALS: cannot be used with CORS_ALLOWED_ORIGINS \"*\"")
 		}
 	}
-	return defaultValue
-}
 
-func getUint64Env(key string, defaultValue uint64) uint64 {
-	if value := os.Getenv(key); value != "" {
-		if parsed, err := strconv.ParseUint(value, 10, 64); err == nil {
-			return parsed
+	if c.Environment.AllowsInsecureDefaults() {
+		return
+	}
+	if !l.isSet("JWT_SECRET") || len(c.JWT.Secret) < 32 {
+		l.errorf("JWT_SECRET: must be set to at least 32 characters in %s", c.Environment)
+	}
+	if c.Payment.Provider == "fake" && !l.isSet("FAKE_PAYMENT_WEBHOOK_SECRET") {
+		l.errorf("FAKE_PAYMENT_WEBHOOK_SECRET: must be set in %s", c.Environment)
+	}
+	for _, origin := range c.CORS.AllowedOrigins {
+		if origin == "*" {
+			l.errorf("CORS_ALLOWED_ORIGINS: \"*\" cannot be used in %s", c.Environment)
 		}
 	}
-	return defaultValue
+	if !l.isSet("APP_URL") {
+		l.errorf("APP_URL: must be set in %s", c.Environment)
+	}
 }
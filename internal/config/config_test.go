@@ -0,0 +1,244 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeConfigFile writes a config file and points CONFIG_FILE at it
+func writeConfigFile(t *testing.T, name, content string) {
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	t.Setenv("CONFIG_FILE", path)
+}
+
+func findSetting(cfg *Config, key string) Setting {
+	for _, setting := range cfg.Settings() {
+		if setting.Key == key {
+			return setting
+		}
+	}
+	return Setting{}
+}
+
+func TestLoad_Defaults(t *testing.T) {
+	t.Setenv("CONFIG_FILE", "")
+	t.Setenv("ENV", "development")
+
+	cfg, err := Load()
+	require.NoError(t, err)
+
+	assert.Equal(t, "8080", cfg.Server.Port)
+	assert.Equal(t, 24*time.Hour, cfg.JWT.Expiration)
+	assert.Equal(t, EnvDevelopment, cfg.Environment)
+	assert.Zero(t, cfg.Security.HSTSMaxAge)
+	assert.Equal(t, Setting{Key: "PORT", Value: "8080", Source: SourceDefault}, findSetting(cfg, "PORT"))
+}
+
+func TestLoad_FileLayeredUnderEnv(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+	}{
+		{
+			name: "yaml",
+			file: "config.yaml",
+			content: `
+port: 9090
+jwt_expiration: 2h
+rate_limit:
+  auth_per_minute: 5
+cors_allowed_origins:
+  - https://shop.example.com
+  - https://*.example.com
+mail_from: file@example.com
+`,
+		},
+		{
+			name: "toml",
+			file: "config.toml",
+			content: `
+PORT = "9090"
+JWT_EXPIRATION = "2h"
+CORS_ALLOWED_ORIGINS = ["https://shop.example.com", "https://*.example.com"]
+MAIL_FROM = "file@example.com"
+
+[rate_limit]
+auth_per_minute = 5
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			writeConfigFile(t, tt.file, tt.content)
+			t.Setenv("ENV", "development")
+			t.Setenv("MAIL_FROM", "env@example.com")
+
+			cfg, err := Load()
+			require.NoError(t, err)
+
+			assert.Equal(t, "9090", cfg.Server.Port)
+			assert.Equal(t, 2*time.Hour, cfg.JWT.Expiration)
+			assert.Equal(t, 5, cfg.RateLimit.AuthPerMinute)
+			assert.Equal(t, []string{"https://shop.example.com", "https://*.example.com"}, cfg.CORS.AllowedOrigins)
+			assert.Equal(t, "env@example.com", cfg.Mail.From)
+			assert.Equal(t, SourceFile, findSetting(cfg, "PORT").Source)
+			assert.Equal(t, SourceEnv, findSetting(cfg, "MAIL_FROM").Source)
+		})
+	}
+}
+
+func TestLoad_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		env      map[string]string
+		file     string
+		expected []string
+	}{
+		{
+			name:     "invalid values",
+			env:      map[string]string{"JWT_EXPIRATION": "soon", "LOGIN_MAX_FAILURES": "many", "PORT": "http"},
+			expected: []string{`JWT_EXPIRATION: invalid duration "soon"`, `LOGIN_MAX_FAILURES: invalid number "many"`, `PORT: invalid port "http"`},
+		},
+		{
+			name:     "unknown environment variable",
+			env:      map[string]string{"JWT_TIMEOUT": "1h"},
+			expected: []string{"unknown setting JWT_TIMEOUT (env)"},
+		},
+		{
+			name:     "unknown file key",
+			file:     "jwt:\n  timeout: 1h\n",
+			expected: []string{"unknown setting JWT_TIMEOUT (file)"},
+		},
+		{
+			name: "insecure defaults in production",
+			env:  map[string]string{"ENV": "production"},
+			expected: []string{
+				"JWT_SECRET: must be set to at least 32 characters in production",
+				"FAKE_PAYMENT_WEBHOOK_SECRET: must be set in production",
+				"APP_URL: must be set in production",
+			},
+		},
+		{
+			name:     "environment defaults to production",
+			expected: []string{"JWT_SECRET: must be set to at least 32 characters in production"},
+		},
+		{
+			name:     "any origin with credentials",
+			env:      map[string]string{"CORS_ALLOWED_ORIGINS": "*", "CORS_ALLOW_CREDENTIALS": "true"},
+			expected: []string{`CORS_ALLOW_CREDENTIALS: cannot be used with CORS_ALLOWED_ORIGINS "*"`},
+		},
+		{
+			name:     "unknown environment",
+			env:      map[string]string{"ENV": "prod"},
+			expected: []string{`ENV: must be development, staging, production or test, got "prod"`},
+		},
+		{
+			name:     "insecure defaults in staging",
+			env:      map[string]string{"ENV": "staging"},
+			expected: []string{"JWT_SECRET: must be set to at least 32 characters in staging"},
+		},
+		{
+			name: "short secret in production",
+			env: map[string]string{
+				"ENV":                         "production",
+				"JWT_SECRET":                  "too-short",
+				"FAKE_PAYMENT_WEBHOOK_SECRET": "webhook-secret",
+				"APP_URL":                     "https://shop.example.com",
+				"CORS_ALLOWED_ORIGINS":        "*",
+			},
+			expected: []string{
+				"JWT_SECRET: must be set to at least 32 characters in production",
+				`CORS_ALLOWED_ORIGINS: "*" cannot be used in production`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("CONFIG_FILE", "")
+			if tt.file != "" {
+				writeConfigFile(t, "config.yaml", tt.file)
+			}
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			cfg, err := Load()
+			require.Error(t, err)
+			assert.NotNil(t, cfg)
+			for _, message := range tt.expected {
+				assert.Contains(t, err.Error(), message)
+			}
+		})
+	}
+}
+
+func TestLoad_Production(t *testing.T) {
+	t.Setenv("CONFIG_FILE", "")
+	t.Setenv("ENV", "production")
+	t.Setenv("JWT_SECRET", "a-production-secret-of-32-characters")
+	t.Setenv("FAKE_PAYMENT_WEBHOOK_SECRET", "webhook-secret")
+	t.Setenv("APP_URL", "https://shop.example.com")
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.True(t, cfg.Environment.IsProduction())
+	assert.Equal(t, 180*24*time.Hour, cfg.Security.HSTSMaxAge)
+}
+
+func TestLoad_InvalidFile(t *testing.T) {
+	t.Setenv("CONFIG_FILE", filepath.Join(t.TempDir(), "config.json"))
+	_, err := Load()
+	assert.Error(t, err)
+
+	writeConfigFile(t, "config.yaml", "port: [unclosed")
+	_, err = Load()
+	assert.Error(t, err)
+}
+
+func TestSettings_Redacted(t *testing.T) {
+	t.Setenv("CONFIG_FILE", "")
+	t.Setenv("ENV", "development")
+	t.Setenv("JWT_SECRET", "a-production-secret-of-32-characters")
+	t.Setenv("MONGODB_URI", "mongodb://admin:hunter2@db:27017")
+
+	cfg, err := Load()
+	require.NoError(t, err)
+
+	assert.Equal(t, redacted, findSetting(cfg, "JWT_SECRET").Value)
+	assert.Equal(t, redacted, findSetting(cfg, "FAKE_PAYMENT_WEBHOOK_SECRET").Value)
+	assert.NotContains(t, findSetting(cfg, "MONGODB_URI").Value, "hunter2")
+	assert.Contains(t, findSetting(cfg, "MONGODB_URI").Value, "admin")
+}
+
+func TestEnvironment(t *testing.T) {
+	tests := []struct {
+		env                    Environment
+		exposeErrors           bool
+		allowsInsecureDefaults bool
+	}{
+		{env: EnvDevelopment, exposeErrors: true, allowsInsecureDefaults: true},
+		{env: EnvTest, exposeErrors: true, allowsInsecureDefaults: true},
+		{env: EnvStaging, exposeErrors: false, allowsInsecureDefaults: false},
+		{env: EnvProduction, exposeErrors: false, allowsInsecureDefaults: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.env), func(t *testing.T) {
+			assert.True(t, tt.env.IsValid())
+			assert.Equal(t, tt.exposeErrors, tt.env.ExposeErrors())
+			assert.Equal(t, tt.allowsInsecureDefaults, tt.env.AllowsInsecureDefaults())
+			assert.Equal(t, tt.env == EnvProduction, tt.env.IsProduction())
+		})
+	}
+
+	assert.False(t, Environment("prod").IsValid())
+}
@@ -0,0 +1,251 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// Sources of a setting, from highest to lowest precedence
+const (
+	SourceEnv     = "env"
+	SourceFile    = "file"
+	SourceDefault = "default"
+)
+
+// redacted replaces secret values in Settings
+const redacted = "[redacted]"
+
+// envPrefixes are the prefixes of environment variables owned by this application.
+// Unknown variables with these prefixes are reported as typos.
+var envPrefixes = []string{"JWT_", "LOGIN_", "MFA_", "PAYMENT_", "FAKE_PAYMENT_", "TAX_", "MAIL_", "RATE_LIMIT_", "CORS_", "HSTS_"}
+
+// Setting is one configuration value and where it came from
+type Setting struct {
+	Key    string
+	Value  string
+	Source string
+}
+
+// loader reads settings from the environment, then the config file, then defaults,
+// and collects every problem instead of stopping at the first
+type loader struct {
+	file   map[string]string
+	used   map[string]Setting
+	errors []error
+}
+
+// newLoader reads the config file at path, if any. File keys are the names of the
+// environment variables, in any case; nested tables are joined with underscores, so
+// rate_limit: {auth_per_minute: 20} sets RATE_LIMIT_AUTH_PER_MINUTE.
+func newLoader(path string) (*loader, error) {
+	l := &loader{file: map[string]string{}, used: map[string]Setting{}}
+	if path == "" {
+		return l, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var values map[string]interface{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &values)
+	case ".toml":
+		err = toml.Unmarshal(data, &values)
+	default:
+		return nil, fmt.Errorf("config file %s: must be .yaml, .yml or .toml", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	flatten("", values, l.file)
+	return l, nil
+}
+
+// flatten turns nested tables into upper case keys joined with underscores, and lists
+// into comma separated values
+func flatten(prefix string, values map[string]interface{}, out map[string]string) {
+	for key, value := range values {
+		key = strings.ToUpper(prefix + key)
+		switch v := value.(type) {
+		case map[string]interface{}:
+			flatten(key+"_", v, out)
+		case []interface{}:
+			items := make([]string, 0, len(v))
+			for _, item := range v {
+				items = append(items, fmt.Sprint(item))
+			}
+			out[key] = strings.Join(items, ",")
+		case nil:
+			out[key] = ""
+		default:
+			out[key] = fmt.Sprint(v)
+		}
+	}
+}
+
+// lookup returns the raw value of a setting and records where it came from. Empty
+// environment variables count as unset.
+func (l *loader) lookup(key, defaultValue string) (string, bool) {
+	setting := Setting{Key: key, Value: defaultValue, Source: SourceDefault}
+	if value := os.Getenv(key); value != "" {
+		setting = Setting{Key: key, Value: value, Source: SourceEnv}
+	} else if value, ok := l.file[key]; ok && value != "" {
+		setting = Setting{Key: key, Value: value, Source: SourceFile}
+	}
+	l.used[key] = setting
+	return setting.Value, setting.Source != SourceDefault
+}
+
+// isSet reports whether a setting was given rather than defaulted
+func (l *loader) isSet(key string) bool {
+	setting, ok := l.used[key]
+	return ok && setting.Source != SourceDefault
+}
+
+func (l *loader) errorf(format string, args ...interface{}) {
+	l.errors = append(l.errors, fmt.Errorf(format, args...))
+}
+
+func (l *loader) getEnv(key, defaultValue string) string {
+	value, _ := l.lookup(key, defaultValue)
+	return value
+}
+
+// getListEnv splits a comma separated value, ignoring blank entries
+func (l *loader) getListEnv(key, defaultValue string) []string {
+	var values []string
+	for _, value := range strings.Split(l.getEnv(key, defaultValue), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+func (l *loader) getDurationEnv(key string, defaultValue time.Duration) time.Duration {
+	value, ok := l.lookup(key, defaultValue.String())
+	if !ok {
+		return defaultValue
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		l.errorf("%s: invalid duration %q", key, value)
+		return defaultValue
+	}
+	return duration
+}
+
+func (l *loader) getUint64Env(key string, defaultValue uint64) uint64 {
+	value, ok := l.lookup(key, strconv.FormatUint(defaultValue, 10))
+	if !ok {
+		return defaultValue
+	}
+	parsed, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		l.errorf("%s: invalid number %q", key, value)
+		return defaultValue
+	}
+	return parsed
+}
+
+func (l *loader) getIntEnv(key string, defaultValue int) int {
+	value, ok := l.lookup(key, strconv.Itoa(defaultValue))
+	if !ok {
+		return defaultValue
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		l.errorf("%s: invalid number %q", key, value)
+		return defaultValue
+	}
+	return parsed
+}
+
+func (l *loader) getBoolEnv(key string, defaultValue bool) bool {
+	value, ok := l.lookup(key, strconv.FormatBool(defaultValue))
+	if !ok {
+		return defaultValue
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		l.errorf("%s: invalid boolean %q", key, value)
+		return defaultValue
+	}
+	return parsed
+}
+
+// checkUnknown reports file keys and application environment variables that no
+// setting reads, which are most likely typos
+func (l *loader) checkUnknown() {
+	var unknown []string
+	for key := range l.file {
+		if _, ok := l.used[key]; !ok {
+			unknown = append(unknown, key+" (file)")
+		}
+	}
+	for _, entry := range os.Environ() {
+		key, _, _ := strings.Cut(entry, "=")
+		if _, ok := l.used[key]; ok {
+			continue
+		}
+		for _, prefix := range envPrefixes {
+			if strings.HasPrefix(key, prefix) {
+				unknown = append(unknown, key+" (env)")
+				break
+			}
+		}
+	}
+
+	sort.Strings(unknown)
+	for _, key := range unknown {
+		l.errorf("unknown setting %s", key)
+	}
+}
+
+// err joins every problem found, or returns nil
+func (l *loader) err() error {
+	return errors.Join(l.errors...)
+}
+
+// settings returns the settings read, sorted by key, with secrets redacted
+func (l *loader) settings() []Setting {
+	settings := make([]Setting, 0, len(l.used))
+	for _, setting := range l.used {
+		setting.Value = redact(setting.Key, setting.Value)
+		settings = append(settings, setting)
+	}
+	sort.Slice(settings, func(i, j int) bool { return settings[i].Key < settings[j].Key })
+	return settings
+}
+
+// redact hides secrets and the password of connection strings
+func redact(key, value string) string {
+	if value == "" {
+		return value
+	}
+	if strings.Contains(key, "SECRET") || strings.Contains(key, "PASSWORD") {
+		return redacted
+	}
+	if strings.HasSuffix(key, "_URI") {
+		u, err := url.Parse(value)
+		if err != nil {
+			return redacted
+		}
+		return u.Redacted()
+	}
+	return value
+}
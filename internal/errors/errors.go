@@ -1,15 +1,30 @@
 package errors
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
 )
 
 // AppError represents an application error
 type AppError struct {
-	Code    int    `json:"code"`
+	Status  int          `json:"-"`
+	Code    string       `json:"code"`
+	Message string       `json:"message"`
+	Details []FieldError `json:"details,omitempty"`
+	Err     error        `json:"-"`
+}
+
+// FieldError describes why a single request field was rejected
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
 	Message string `json:"message"`
-	Err     error  `json:"-"`
 }
 
 // Error implements the error interface
@@ -20,22 +35,217 @@ func (e *AppError) Error() string {
 	return e.Message
 }
 
-// New creates a new AppError
-func New(code int, message string, err error) *AppError {
+// Unwrap exposes the underlying cause to errors.Is and errors.As
+func (e *AppError) Unwrap() error {
+	return e.Err
+}
+
+// WithCode returns a copy of the error with a more specific machine-readable code
+func (e *AppError) WithCode(code string) *AppError {
+	copied := *e
+	copied.Code = code
+	return &copied
+}
+
+// Wrap returns a copy of the error that records err as its cause
+func (e *AppError) Wrap(err error) *AppError {
+	copied := *e
+	copied.Err = err
+	return &copied
+}
+
+// New creates a new AppError whose code is derived from the HTTP status
+func New(status int, message string, err error) *AppError {
 	return &AppError{
-		Code:    code,
+		Status:  status,
+		Code:    codeForStatus(status),
 		Message: message,
 		Err:     err,
 	}
 }
 
+// BadRequest reports a request the client has to correct
+func BadRequest(message string) *AppError {
+	return New(http.StatusBadRequest, message, nil)
+}
+
+// Unauthorized reports missing or invalid credentials
+func Unauthorized(message string) *AppError {
+	return New(http.StatusUnauthorized, message, nil)
+}
+
+// Forbidden reports an authenticated caller without access
+func Forbidden(message string) *AppError {
+	return New(http.StatusForbidden, message, nil)
+}
+
+// NotFound reports a missing resource
+func NotFound(message string) *AppError {
+	return New(http.StatusNotFound, message, nil)
+}
+
+// Conflict reports a request that clashes with the current state
+func Conflict(message string) *AppError {
+	return New(http.StatusConflict, message, nil)
+}
+
+// Unprocessable reports a well-formed request that cannot be carried out
+func Unprocessable(message string) *AppError {
+	return New(http.StatusUnprocessableEntity, message, nil)
+}
+
+// TooManyRequests reports a throttled caller
+func TooManyRequests(message string) *AppError {
+	return New(http.StatusTooManyRequests, message, nil)
+}
+
+// Internal reports a server-side failure; err is logged but never shown to clients in production
+func Internal(message string, err error) *AppError {
+	return New(http.StatusInternalServerError, message, err)
+}
+
+// Validation turns a binding or validation error into a 400 with per-field details
+func Validation(err error) *AppError {
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		appErr := ErrValidation.Wrap(err)
+		for _, fe := range validationErrs {
+			appErr.Details = append(appErr.Details, FieldError{
+				Field:   fieldPath(fe),
+				Rule:    fe.Tag(),
+				Message: fieldMessage(fe),
+			})
+		}
+		return appErr
+	}
+
+	appErr := ErrInvalidPayload.Wrap(err)
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		appErr.Details = []FieldError{{
+			Field:   typeErr.Field,
+			Rule:    "type",
+			Message: "must be of type " + typeErr.Type.String(),
+		}}
+	}
+	return appErr
+}
+
+// As returns err as an AppError, treating anything untyped as an internal error
+func As(err error) *AppError {
+	var appErr *AppError
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+	return ErrInternal.Wrap(err)
+}
+
 // Predefined errors
 var (
-	ErrInvalidCredentials = New(http.StatusUnauthorized, "Invalid credentials", nil)
-	ErrUserNotFound       = New(http.StatusNotFound, "User not found", nil)
-	ErrUserAlreadyExists  = New(http.StatusConflict, "User already exists", nil)
-	ErrInvalidToken       = New(http.StatusUnauthorized, "Invalid token", nil)
+	ErrInvalidCredentials = New(http.StatusUnauthorized, "Invalid credentials", nil).WithCode("invalid_credentials")
+	ErrUserNotFound       = New(http.StatusNotFound, "User not found", nil).WithCode("user_not_found")
+	ErrUserAlreadyExists  = New(http.StatusConflict, "User already exists", nil).WithCode("user_already_exists")
+	ErrInvalidToken       = New(http.StatusUnauthorized, "Invalid token", nil).WithCode("invalid_token")
 	ErrForbidden          = New(http.StatusForbidden, "Access forbidden", nil)
-	ErrValidation         = New(http.StatusBadRequest, "Validation error", nil)
+	ErrValidation         = New(http.StatusBadRequest, "Validation error", nil).WithCode("validation_failed")
+	ErrInvalidPayload     = New(http.StatusBadRequest, "Invalid request payload", nil).WithCode("invalid_payload")
 	ErrInternal           = New(http.StatusInternalServerError, "Internal server error", nil)
 )
+
+// codeForStatus turns a status text such as "Not Found" into "not_found"
+func codeForStatus(status int) string {
+	text := http.StatusText(status)
+	if text == "" {
+		return "error"
+	}
+	return strings.ToLower(strings.NewReplacer(" ", "_", "-", "_", "'", "").Replace(text))
+}
+
+// fieldPath turns the validator namespace into the path of the field in the request.
+// The top-level struct is dropped, and so are intermediate fields that have no JSON
+// name of their own: those are embedded structs, whose fields JSON flattens.
+func fieldPath(fe validator.FieldError) string {
+	names := strings.Split(fe.Namespace(), ".")
+	goNames := strings.Split(fe.StructNamespace(), ".")
+	if len(names) < 2 || len(names) != len(goNames) {
+		return fe.Field()
+	}
+
+	var path []string
+	for i := 1; i < len(names)-1; i++ {
+		if names[i] != goNames[i] {
+			path = append(path, names[i])
+		}
+	}
+	return strings.Join(append(path, names[len(names)-1]), ".")
+}
+
+// fieldMessage renders a validation failure in words a client can show to a user
+func fieldMessage(fe validator.FieldError) string {
+	param := fe.Param()
+	switch fe.Tag() {
+	case "required", "required_if", "required_unless", "required_with", "required_without":
+		return "is required"
+	case "email":
+		return "must be a valid email address"
+	case "url", "http_url":
+		return "must be a valid URL"
+	case "alpha":
+		return "must contain only letters"
+	case "alphanum":
+		return "must contain only letters and digits"
+	case "numeric":
+		return "must contain only digits"
+	case "oneof":
+		return "must be one of: " + strings.Join(strings.Fields(param), ", ")
+	case "len":
+		return sizeVerb(fe.Kind()) + " exactly " + param + sizeUnit(fe.Kind(), param)
+	case "min":
+		return sizeVerb(fe.Kind()) + " at least " + param + sizeUnit(fe.Kind(), param)
+	case "max":
+		return sizeVerb(fe.Kind()) + " at most " + param + sizeUnit(fe.Kind(), param)
+	case "gt":
+		return "must be greater than " + param
+	case "gte":
+		return "must be greater than or equal to " + param
+	case "lt":
+		return "must be less than " + param
+	case "lte":
+		return "must be less than or equal to " + param
+	case "eqfield":
+		return "must match " + param
+	case "dive":
+		return "contains an invalid value"
+	case "category":
+		return "must be a valid product category"
+	case "objectid":
+		return "must be a valid ID"
+	case "currency":
+		return "must be a supported ISO 4217 currency code"
+	}
+	return fmt.Sprintf("failed the %q rule", fe.Tag())
+}
+
+// sizeVerb words a size rule so it reads naturally for collections
+func sizeVerb(kind reflect.Kind) string {
+	switch kind {
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return "must contain"
+	}
+	return "must be"
+}
+
+// sizeUnit names what min, max and len count for the given kind
+func sizeUnit(kind reflect.Kind, param string) string {
+	unit := ""
+	switch kind {
+	case reflect.String:
+		unit = " character"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		unit = " item"
+	}
+	if unit != "" && param != "1" {
+		unit += "s"
+	}
+	return unit
+}
@@ -0,0 +1,104 @@
+package errors
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name         string
+		err          *AppError
+		expectedCode string
+	}{
+		{name: "bad request", err: BadRequest("Invalid product ID"), expectedCode: "bad_request"},
+		{name: "not found", err: NotFound("Product not found"), expectedCode: "not_found"},
+		{name: "unprocessable", err: Unprocessable("Out of stock"), expectedCode: "unprocessable_entity"},
+		{name: "internal", err: Internal("Failed to fetch products", nil), expectedCode: "internal_server_error"},
+		{name: "other status", err: New(http.StatusBadGateway, "Provider is unavailable", nil), expectedCode: "bad_gateway"},
+		{name: "custom code", err: ErrInvalidCredentials, expectedCode: "invalid_credentials"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expectedCode, tt.err.Code)
+		})
+	}
+}
+
+func TestWrapLeavesPredefinedErrorsAlone(t *testing.T) {
+	cause := errors.New("connection refused")
+	wrapped := ErrInternal.Wrap(cause)
+
+	assert.Nil(t, ErrInternal.Err)
+	assert.ErrorIs(t, wrapped, cause)
+	assert.Equal(t, "Internal server error: connection refused", wrapped.Error())
+}
+
+func TestAs(t *testing.T) {
+	appErr := As(NotFound("Order not found"))
+	assert.Equal(t, http.StatusNotFound, appErr.Status)
+
+	appErr = As(errors.New("boom"))
+	assert.Equal(t, http.StatusInternalServerError, appErr.Status)
+	assert.Equal(t, "Internal server error", appErr.Message)
+}
+
+func TestValidation(t *testing.T) {
+	type item struct {
+		Quantity int `json:"quantity" validate:"min=1"`
+	}
+	type cart struct {
+		Items []item `json:"items" validate:"dive"`
+	}
+	type request struct {
+		cart
+		Email  string `json:"email" validate:"required,email"`
+		Name   string `json:"name" validate:"min=2"`
+		Status string `json:"status" validate:"oneof=active archived"`
+	}
+
+	v := validator.New()
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		return name
+	})
+	err := v.Struct(request{cart: cart{Items: []item{{Quantity: 0}}}, Email: "nope", Name: "a", Status: "x"})
+	appErr := Validation(err)
+
+	assert.Equal(t, http.StatusBadRequest, appErr.Status)
+	assert.Equal(t, "validation_failed", appErr.Code)
+	assert.Equal(t, []FieldError{
+		{Field: "items[0].quantity", Rule: "min", Message: "must be at least 1"},
+		{Field: "email", Rule: "email", Message: "must be a valid email address"},
+		{Field: "name", Rule: "min", Message: "must be at least 2 characters"},
+		{Field: "status", Rule: "oneof", Message: "must be one of: active, archived"},
+	}, appErr.Details)
+}
+
+func TestValidation_MalformedPayload(t *testing.T) {
+	var target struct {
+		Price float64 `json:"price"`
+	}
+
+	err := json.Unmarshal([]byte(`{"price": "free"}`), &target)
+	require.Error(t, err)
+	appErr := Validation(err)
+	assert.Equal(t, "invalid_payload", appErr.Code)
+	assert.Equal(t, []FieldError{{Field: "price", Rule: "type", Message: "must be of type float64"}}, appErr.Details)
+
+	err = json.Unmarshal([]byte(`{"price":`), &target)
+	require.Error(t, err)
+	appErr = Validation(err)
+	assert.Equal(t, "invalid_payload", appErr.Code)
+	assert.Equal(t, "Invalid request payload", appErr.Message)
+	assert.Empty(t, appErr.Details)
+}
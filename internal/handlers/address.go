@@ -0,0 +1,298 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"ecommerce-backend/internal/database"
+	apperrors "ecommerce-backend/internal/errors"
+	"ecommerce-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// errAddressNotFound is returned when a saved address does not exist or belongs to another user
+var errAddressNotFound = errors.New("Address not found")
+
+// AddressHandler handles the current user's address book
+type AddressHandler struct {
+	db *database.Client
+}
+
+// NewAddressHandler creates a new AddressHandler
+func NewAddressHandler(db *database.Client) *AddressHandler {
+	return &AddressHandler{
+		db: db,
+	}
+}
+
+// GetAddresses lists the current user's saved addresses, default first
+func (h *AddressHandler) GetAddresses(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperrors.Unauthorized("Unauthorized"))
+		return
+	}
+
+	userObjID, err := primitive.ObjectIDFromHex(userID.(string))
+	if err != nil {
+		c.Error(apperrors.BadRequest("Invalid user ID"))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	findOptions := options.Find().SetSort(bson.D{{Key: "is_default", Value: -1}, {Key: "created_at", Value: 1}})
+	cursor, err := h.db.GetCollection("addresses").Find(ctx, bson.M{"user_id": userObjID}, findOptions)
+	if err != nil {
+		c.Error(apperrors.Internal("Failed to fetch addresses", err))
+		return
+	}
+	defer cursor.Close(ctx)
+
+	addresses := []models.SavedAddress{}
+	if err := cursor.All(ctx, &addresses); err != nil {
+		c.Error(apperrors.Internal("Failed to decode addresses", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"addresses": addresses})
+}
+
+// CreateAddress adds an address to the current user's address book. The first address
+// saved becomes the default.
+func (h *AddressHandler) CreateAddress(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperrors.Unauthorized("Unauthorized"))
+		return
+	}
+
+	userObjID, err := primitive.ObjectIDFromHex(userID.(string))
+	if err != nil {
+		c.Error(apperrors.BadRequest("Invalid user ID"))
+		return
+	}
+
+	req, ok := h.bindAddressRequest(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	collection := h.db.GetCollection("addresses")
+	count, err := collection.CountDocuments(ctx, bson.M{"user_id": userObjID})
+	if err != nil {
+		c.Error(apperrors.Internal("Failed to count addresses", err))
+		return
+	}
+	if count >= models.MaxSavedAddresses {
+		c.Error(apperrors.Conflict("Address book is full"))
+		return
+	}
+
+	now := time.Now()
+	address := models.SavedAddress{
+		ID:        primitive.NewObjectID(),
+		UserID:    userObjID,
+		Label:     strings.TrimSpace(req.Label),
+		Address:   req.Address,
+		IsDefault: req.IsDefault || count == 0,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if address.IsDefault {
+		if err := clearDefaultAddress(ctx, h.db, userObjID); err != nil {
+			c.Error(apperrors.Internal("Failed to update default address", err))
+			return
+		}
+	}
+
+	if _, err := collection.InsertOne(ctx, address); err != nil {
+		c.Error(apperrors.Internal("Failed to create address", err))
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Address created successfully",
+		"address": address,
+	})
+}
+
+// UpdateAddress replaces one of the current user's saved addresses
+func (h *AddressHandler) UpdateAddress(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperrors.Unauthorized("Unauthorized"))
+		return
+	}
+
+	userObjID, err := primitive.ObjectIDFromHex(userID.(string))
+	if err != nil {
+		c.Error(apperrors.BadRequest("Invalid user ID"))
+		return
+	}
+
+	addressID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.Error(apperrors.BadRequest("Invalid address ID"))
+		return
+	}
+
+	req, ok := h.bindAddressRequest(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	fields := bson.M{
+		"label":      strings.TrimSpace(req.Label),
+		"address":    req.Address,
+		"updated_at": time.Now(),
+	}
+
+	// An address stops being the default only when another one is made the default
+	if req.IsDefault {
+		if err := clearDefaultAddress(ctx, h.db, userObjID); err != nil {
+			c.Error(apperrors.Internal("Failed to update default address", err))
+			return
+		}
+		fields["is_default"] = true
+	}
+
+	var address models.SavedAddress
+	after := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	filter := bson.M{"_id": addressID, "user_id": userObjID}
+	err = h.db.GetCollection("addresses").FindOneAndUpdate(ctx, filter, bson.M{"$set": fields}, after).Decode(&address)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.Error(apperrors.NotFound(errAddressNotFound.Error()))
+			return
+		}
+		c.Error(apperrors.Internal("Failed to update address", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Address updated successfully",
+		"address": address,
+	})
+}
+
+// DeleteAddress removes one of the current user's saved addresses. When the default is
+// removed, the oldest remaining address becomes the default.
+func (h *AddressHandler) DeleteAddress(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperrors.Unauthorized("Unauthorized"))
+		return
+	}
+
+	userObjID, err := primitive.ObjectIDFromHex(userID.(string))
+	if err != nil {
+		c.Error(apperrors.BadRequest("Invalid user ID"))
+		return
+	}
+
+	addressID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.Error(apperrors.BadRequest("Invalid address ID"))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	collection := h.db.GetCollection("addresses")
+
+	var deleted models.SavedAddress
+	err = collection.FindOneAndDelete(ctx, bson.M{"_id": addressID, "user_id": userObjID}).Decode(&deleted)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.Error(apperrors.NotFound(errAddressNotFound.Error()))
+			return
+		}
+		c.Error(apperrors.Internal("Failed to delete address", err))
+		return
+	}
+
+	if deleted.IsDefault {
+		oldest := options.FindOneAndUpdate().SetSort(bson.D{{Key: "created_at", Value: 1}})
+		err := collection.FindOneAndUpdate(ctx, bson.M{"user_id": userObjID}, bson.M{"$set": bson.M{"is_default": true}}, oldest).Err()
+		if err != nil && err != mongo.ErrNoDocuments {
+			c.Error(apperrors.Internal("Failed to update default address", err))
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Address deleted successfully"})
+}
+
+// bindAddressRequest binds and validates a saved address, normalizing it first so that
+// the country format checks see canonical values
+func (h *AddressHandler) bindAddressRequest(c *gin.Context) (models.SavedAddressRequest, bool) {
+	var req models.SavedAddressRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperrors.Validation(err))
+		return req, false
+	}
+
+	req.Address = req.Address.Normalized()
+	if err := req.Address.Validate(); err != nil {
+		c.Error(apperrors.BadRequest(err.Error()))
+		return req, false
+	}
+	return req, true
+}
+
+// Helper functions
+
+// clearDefaultAddress unsets the default flag on all of the user's addresses
+func clearDefaultAddress(ctx context.Context, db *database.Client, userID primitive.ObjectID) error {
+	_, err := db.GetCollection("addresses").UpdateMany(ctx,
+		bson.M{"user_id": userID, "is_default": true},
+		bson.M{"$set": bson.M{"is_default": false}},
+	)
+	return err
+}
+
+// resolveAddress returns a copy of either the inline address or the user's saved address
+// with the given ID. The copy is what orders store, so later edits to the address book
+// do not change past orders.
+func resolveAddress(ctx context.Context, db *database.Client, userID primitive.ObjectID, inline *models.Address, addressID string) (*models.Address, error) {
+	if addressID == "" {
+		if inline == nil {
+			return nil, nil
+		}
+		address := inline.Normalized()
+		return &address, nil
+	}
+
+	objID, err := primitive.ObjectIDFromHex(addressID)
+	if err != nil || userID.IsZero() {
+		return nil, errAddressNotFound
+	}
+
+	var saved models.SavedAddress
+	err = db.GetCollection("addresses").FindOne(ctx, bson.M{"_id": objID, "user_id": userID}).Decode(&saved)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errAddressNotFound
+		}
+		return nil, err
+	}
+	return &saved.Address, nil
+}
@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"ecommerce-backend/internal/apikeys"
+	"ecommerce-backend/internal/audit"
+	apperrors "ecommerce-backend/internal/errors"
+	"ecommerce-backend/internal/middleware"
+	"ecommerce-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// APIKeyHandler handles admin management of API keys for integrations
+type APIKeyHandler struct {
+	keys *apikeys.Store
+}
+
+// NewAPIKeyHandler creates a new APIKeyHandler
+func NewAPIKeyHandler(keys *apikeys.Store) *APIKeyHandler {
+	return &APIKeyHandler{
+		keys: keys,
+	}
+}
+
+// GetAPIKeys lists every API key, without the keys themselves (requires api_keys:manage)
+func (h *APIKeyHandler) GetAPIKeys(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	keys, err := h.keys.List(ctx)
+	if err != nil {
+		c.Error(apperrors.Internal("Failed to fetch API keys", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"api_keys": keys})
+}
+
+// CreateAPIKey issues an API key acting for the current admin with a subset of their
+// permissions. The key is returned once and cannot be retrieved again. (requires api_keys:manage)
+func (h *APIKeyHandler) CreateAPIKey(c *gin.Context) {
+	// A leaked key must not be able to mint more keys
+	if c.GetString("api_key_id") != "" {
+		c.Error(apperrors.Forbidden("API keys cannot issue API keys"))
+		return
+	}
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperrors.Validation(err))
+		return
+	}
+
+	for _, permission := range req.Permissions {
+		if !permission.IsValid() {
+			c.Error(apperrors.BadRequest("Unknown permission: " + string(permission)))
+			return
+		}
+		if !middleware.HasPermission(c, permission) {
+			c.Error(apperrors.Forbidden("Cannot grant a permission you do not hold: " + string(permission)))
+			return
+		}
+	}
+
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		c.Error(apperrors.BadRequest("Expiry must be in the future"))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	apiKey, key, err := h.keys.Create(ctx, req.Name, req.Permissions, userID, req.ExpiresAt)
+	if err != nil {
+		c.Error(apperrors.Internal("Failed to create API key", err))
+		return
+	}
+	audit.SetResourceID(c, apiKey.ID.Hex())
+
+	c.JSON(http.StatusCreated, models.APIKeyCreatedResponse{
+		APIKey: apiKey,
+		Key:    key,
+	})
+}
+
+// RevokeAPIKey stops an API key from being accepted (requires api_keys:manage)
+func (h *APIKeyHandler) RevokeAPIKey(c *gin.Context) {
+	objID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.Error(apperrors.BadRequest("Invalid API key ID"))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := h.keys.Revoke(ctx, objID); err != nil {
+		if err == apikeys.ErrNotFound {
+			c.Error(apperrors.NotFound(err.Error()))
+			return
+		}
+		c.Error(apperrors.Internal("Failed to revoke API key", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "API key revoked successfully"})
+}
@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"ecommerce-backend/internal/audit"
+	apperrors "ecommerce-backend/internal/errors"
+	"ecommerce-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// AuditHandler handles reading the audit log of admin changes
+type AuditHandler struct {
+	audit *audit.Logger
+}
+
+// NewAuditHandler creates a new AuditHandler
+func NewAuditHandler(auditLogger *audit.Logger) *AuditHandler {
+	return &AuditHandler{
+		audit: auditLogger,
+	}
+}
+
+// GetAuditLog lists audit entries, newest first, filtered by actor, resource and time (requires audit:read)
+func (h *AuditHandler) GetAuditLog(c *gin.Context) {
+	// Parse query parameters
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 50
+	}
+
+	query := audit.Query{
+		Resource:   c.Query("resource"),
+		ResourceID: c.Query("resource_id"),
+		Page:       page,
+		Limit:      limit,
+	}
+
+	// The actor is given by user ID or email address
+	if actor := c.Query("actor"); actor != "" {
+		if objID, err := primitive.ObjectIDFromHex(actor); err == nil {
+			query.ActorID = objID
+		} else {
+			query.ActorEmail = actor
+		}
+	}
+
+	var err error
+	if query.From, err = parseTimeQuery(c, "from"); err != nil {
+		c.Error(apperrors.BadRequest("Invalid from time, expected RFC 3339"))
+		return
+	}
+	if query.To, err = parseTimeQuery(c, "to"); err != nil {
+		c.Error(apperrors.BadRequest("Invalid to time, expected RFC 3339"))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	entries, total, err := h.audit.List(ctx, query)
+	if err != nil {
+		c.Error(apperrors.Internal("Failed to fetch audit log", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.AuditListResponse{
+		Entries: entries,
+		Total:   total,
+		Page:    page,
+		Limit:   limit,
+	})
+}
+
+// Helper functions
+
+// parseTimeQuery parses an optional RFC 3339 query parameter
+func parseTimeQuery(c *gin.Context, key string) (time.Time, error) {
+	value := c.Query(key)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
@@ -1,30 +1,84 @@
 package handlers
 
 import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
 	"net/http"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
+	"ecommerce-backend/internal/config"
 	"ecommerce-backend/internal/database"
+	apperrors "ecommerce-backend/internal/errors"
+	"ecommerce-backend/internal/lockout"
+	"ecommerce-backend/internal/mail"
 	"ecommerce-backend/internal/models"
+	"ecommerce-backend/internal/oidc"
+	"ecommerce-backend/internal/sessions"
 	"ecommerce-backend/internal/utils"
 
 	"github.com/gin-gonic/gin"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	// passwordResetTTL is how long a password reset link stays valid
+	passwordResetTTL = time.Hour
+	// emailVerificationTTL is how long an email verification link stays valid
+	emailVerificationTTL = 48 * time.Hour
+	// verificationResendInterval is the minimum time between verification emails
+	verificationResendInterval = time.Minute
+	// mfaChallengeTTL is how long the second step of a login stays open
+	mfaChallengeTTL = 5 * time.Minute
+	// oidcStateTTL is how long a login at an OpenID Connect provider may take
+	oidcStateTTL = 10 * time.Minute
 )
 
 // AuthHandler handles authentication requests
 type AuthHandler struct {
 	db         *database.Client
 	jwtManager *utils.JWTManager
+	sessions   *sessions.Store
+	mailer     mail.Sender
+	mail       config.MailConfig
+	providers  *oidc.Registry
+
+	// Failed login throttling
+	accountPolicy lockout.Policy
+	ipPolicy      lockout.Policy
 }
 
 // NewAuthHandler creates a new auth handler
-func NewAuthHandler(db *database.Client, jwtManager *utils.JWTManager) *AuthHandler {
+func NewAuthHandler(db *database.Client, jwtManager *utils.JWTManager, sessionStore *sessions.Store, mailer mail.Sender, mailConfig config.MailConfig, authConfig config.AuthConfig, providers *oidc.Registry) *AuthHandler {
 	return &AuthHandler{
 		db:         db,
 		jwtManager: jwtManager,
+		sessions:   sessionStore,
+		mailer:     mailer,
+		mail:       mailConfig,
+		providers:  providers,
+		accountPolicy: lockout.Policy{
+			FreeAttempts: 3,
+			BaseDelay:    time.Second,
+			MaxDelay:     time.Minute,
+			LockoutAfter: authConfig.LoginMaxFailures,
+			LockoutFor:   authConfig.LoginLockoutDuration,
+			Window:       time.Hour,
+		},
+		// IPs may be shared behind NAT, so they back off but are never locked out
+		ipPolicy: lockout.Policy{
+			FreeAttempts: 20,
+			BaseDelay:    time.Second,
+			MaxDelay:     5 * time.Minute,
+			Window:       time.Hour,
+		},
 	}
 }
 
@@ -32,7 +86,7 @@ func NewAuthHandler(db *database.Client, jwtManager *utils.JWTManager) *AuthHand
 func (h *AuthHandler) Register(c *gin.Context) {
 	var req models.RegisterRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(apperrors.Validation(err))
 		return
 	}
 
@@ -41,30 +95,17 @@ func (h *AuthHandler) Register(c *gin.Context) {
 	var existingUser models.User
 	err := collection.FindOne(c, bson.M{"email": req.Email}).Decode(&existingUser)
 	if err == nil {
-		c.JSON(http.StatusConflict, gin.H{"error": "User already exists"})
+		c.Error(apperrors.Conflict("User already exists"))
 		return
 	}
 
 	// Hash password
 	hashedPassword, err := utils.HashPassword(req.Password)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
+		c.Error(apperrors.Internal("Failed to hash password", err))
 		return
 	}
 
-	// Set default role if not provided
-	role := models.RoleUser
-	if req.Role != "" {
-		// Convert string to Role and validate
-		requestedRole := models.Role(req.Role)
-		if requestedRole.IsValid() {
-			role = requestedRole
-		} else {
-			// If invalid role provided, use default
-			role = models.RoleUser
-		}
-	}
-
 	// Create user
 	user := models.User{
 		ID:        primitive.NewObjectID(),
@@ -72,7 +113,7 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		Password:  hashedPassword,
 		FirstName: req.FirstName,
 		LastName:  req.LastName,
-		Role:      role,
+		Role:      models.RoleUser, // Other roles are only granted by an administrator
 		IsActive:  true,
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
@@ -80,60 +121,189 @@ func (h *AuthHandler) Register(c *gin.Context) {
 
 	_, err = collection.InsertOne(c, user)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create user"})
+		c.Error(apperrors.Internal("Failed to create user", err))
 		return
 	}
 
 	// Generate token
-	token, err := h.jwtManager.GenerateToken(user.ID.Hex(), user.Email, user.Role.String())
+	token, err := h.startSession(c, user, false)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		c.Error(apperrors.Internal("Failed to generate token", err))
 		return
 	}
 
+	// Registration succeeds even if the email fails; the user can ask for a new link
+	if err := h.sendEmailVerification(user); err != nil {
+		slog.Error("Failed to send verification email", "user_id", user.ID.Hex(), "error", err)
+	}
+
 	c.JSON(http.StatusCreated, models.AuthResponse{
 		Token: token,
 		User:  user.ToResponse(),
 	})
 }
 
-// Login handles user login
+// Login handles user login. Failed attempts are throttled per account and per IP, and
+// the response time does not depend on whether the account exists.
 func (h *AuthHandler) Login(c *gin.Context) {
 	var req models.LoginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(apperrors.Validation(err))
+		return
+	}
+
+	accountKey := "account:" + strings.ToLower(strings.TrimSpace(req.Email))
+	ipKey := "ip:" + c.ClientIP()
+
+	// The attempt is counted before the password is checked, so that concurrent attempts
+	// cannot get past the backoff or lockout together
+	retryAfter, err := h.beginLoginAttempt(c, accountKey, ipKey)
+	if err != nil {
+		c.Error(apperrors.Internal("Database error", err))
+		return
+	}
+	if retryAfter > 0 {
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+		c.Error(apperrors.TooManyRequests("Too many failed login attempts, try again later"))
 		return
 	}
 
 	// Find user
 	collection := h.db.GetCollection("users")
 	var user models.User
-	err := collection.FindOne(c, bson.M{"email": req.Email}).Decode(&user)
-	if err != nil {
-		if err == mongo.ErrNoDocuments {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+	err = collection.FindOne(c, bson.M{"email": req.Email}).Decode(&user)
+	if err != nil && err != mongo.ErrNoDocuments {
+		c.Error(apperrors.Internal("Database error", err))
+		return
+	}
+	found := err == nil
+
+	// Check password. Unknown accounts are checked against a dummy hash so that bcrypt
+	// runs either way.
+	hash := dummyPasswordHash()
+	if found {
+		hash = user.Password
+	}
+	if !utils.CheckPasswordHash(req.Password, hash) || !found {
+		c.Error(apperrors.Unauthorized("Invalid credentials"))
+		return
+	}
+
+	// The password was right, so the attempt is not a failure
+	if err := h.releaseLoginAttempt(c, accountKey, ipKey); err != nil {
+		c.Error(apperrors.Internal("Database error", err))
 		return
 	}
 
-	// Check if user is active
+	// Check if user is active, only once the password is known to be right
 	if !user.IsActive {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Account is deactivated"})
+		c.Error(apperrors.Unauthorized("Account is deactivated"))
+		return
+	}
+
+	// With two-factor authentication the login continues at VerifyMFA. Failed attempts are
+	// kept until then, so that they also count against second factor guesses.
+	if user.MFAEnabled {
+		h.startMFAChallenge(c, user)
+		return
+	}
+
+	h.completeLogin(c, user, accountKey, false)
+}
+
+// VerifyMFA completes a login with an authenticator or recovery code and the challenge
+// token returned by Login
+func (h *AuthHandler) VerifyMFA(c *gin.Context) {
+	var req models.MFALoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperrors.Validation(err))
 		return
 	}
 
-	// Check password
-	if !utils.CheckPasswordHash(req.Password, user.Password) {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// The challenge is consumed before the code is checked, so each challenge allows a
+	// single guess and a mistyped code means logging in again
+	challenge, err := consumeUserToken(ctx, h.db, models.TokenPurposeMFAChallenge, req.MFAToken)
+	if err == errInvalidUserToken {
+		c.Error(apperrors.Unauthorized(err.Error()))
+		return
+	}
+	if err != nil {
+		c.Error(apperrors.Internal("Database error", err))
+		return
+	}
+
+	var user models.User
+	err = h.db.GetCollection("users").FindOne(ctx, bson.M{"_id": challenge.UserID}).Decode(&user)
+	if err == mongo.ErrNoDocuments || (err == nil && (!user.IsActive || !user.MFAEnabled)) {
+		c.Error(apperrors.Unauthorized(errInvalidUserToken.Error()))
+		return
+	}
+	if err != nil {
+		c.Error(apperrors.Internal("Database error", err))
+		return
+	}
+
+	accountKey := "account:" + strings.ToLower(strings.TrimSpace(user.Email))
+	ipKey := "ip:" + c.ClientIP()
+
+	retryAfter, err := h.beginLoginAttempt(ctx, accountKey, ipKey)
+	if err != nil {
+		c.Error(apperrors.Internal("Database error", err))
+		return
+	}
+	if retryAfter > 0 {
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+		c.Error(apperrors.TooManyRequests("Too many failed login attempts, try again later"))
+		return
+	}
+
+	ok, err := verifySecondFactor(ctx, h.db, user, req.Code)
+	if err != nil {
+		c.Error(apperrors.Internal("Database error", err))
+		return
+	}
+	if !ok {
+		c.Error(apperrors.Unauthorized("Invalid authentication code"))
+		return
+	}
+
+	if err := h.releaseLoginAttempt(ctx, accountKey, ipKey); err != nil {
+		c.Error(apperrors.Internal("Database error", err))
+		return
+	}
+
+	h.completeLogin(c, user, accountKey, true)
+}
+
+// startMFAChallenge responds with a token for the second step of the login
+func (h *AuthHandler) startMFAChallenge(c *gin.Context, user models.User) {
+	challenge, err := issueUserToken(c, h.db, user.ID, models.TokenPurposeMFAChallenge, "", mfaChallengeTTL)
+	if err != nil {
+		c.Error(apperrors.Internal("Failed to start two-factor authentication", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.MFAChallengeResponse{
+		MFARequired: true,
+		MFAToken:    challenge,
+		ExpiresAt:   time.Now().Add(mfaChallengeTTL),
+	})
+}
+
+// completeLogin clears the account's failed attempts and responds with a new session token
+func (h *AuthHandler) completeLogin(c *gin.Context, user models.User, accountKey string, mfa bool) {
+	if _, err := h.db.GetCollection("login_attempts").DeleteOne(c, bson.M{"_id": accountKey}); err != nil {
+		c.Error(apperrors.Internal("Database error", err))
 		return
 	}
 
 	// Generate token
-	token, err := h.jwtManager.GenerateToken(user.ID.Hex(), user.Email, user.Role.String())
+	token, err := h.startSession(c, user, mfa)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		c.Error(apperrors.Internal("Failed to generate token", err))
 		return
 	}
 
@@ -143,17 +313,267 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	})
 }
 
+// UnlockUser clears the failed login attempts and lockout of an account (requires users:manage)
+func (h *AuthHandler) UnlockUser(c *gin.Context) {
+	objID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.Error(apperrors.BadRequest("Invalid user ID"))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var user models.User
+	if err := h.db.GetCollection("users").FindOne(ctx, bson.M{"_id": objID}).Decode(&user); err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.Error(apperrors.NotFound("User not found"))
+			return
+		}
+		c.Error(apperrors.Internal("Database error", err))
+		return
+	}
+
+	key := "account:" + strings.ToLower(strings.TrimSpace(user.Email))
+	if _, err := h.db.GetCollection("login_attempts").DeleteOne(ctx, bson.M{"_id": key}); err != nil {
+		c.Error(apperrors.Internal("Failed to unlock user", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "User unlocked successfully"})
+}
+
+// beginLoginAttempt counts a login attempt against both the account and the IP and
+// returns how long until the attempt may be made, or 0 if it may go ahead now. Each key
+// is updated in a single step, so the decision is based on every earlier attempt.
+func (h *AuthHandler) beginLoginAttempt(ctx context.Context, accountKey, ipKey string) (time.Duration, error) {
+	attempts := []struct {
+		key    string
+		policy lockout.Policy
+	}{
+		{key: accountKey, policy: h.accountPolicy},
+		{key: ipKey, policy: h.ipPolicy},
+	}
+
+	now := time.Now()
+	collection := h.db.GetCollection("login_attempts")
+	updateOptions := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.Before)
+
+	var wait time.Duration
+	for _, attempt := range attempts {
+		var before lockout.Record
+		err := collection.FindOneAndUpdate(ctx, bson.M{"_id": attempt.key}, attempt.policy.AttemptUpdate(now), updateOptions).Decode(&before)
+		if err != nil && err != mongo.ErrNoDocuments {
+			return 0, err
+		}
+		if _, keyWait := attempt.policy.Attempt(before, now); keyWait > wait {
+			wait = keyWait
+		}
+	}
+	return wait, nil
+}
+
+// releaseLoginAttempt takes back an attempt counted by beginLoginAttempt once it has
+// turned out not to be a failure
+func (h *AuthHandler) releaseLoginAttempt(ctx context.Context, accountKey, ipKey string) error {
+	_, err := h.db.GetCollection("login_attempts").UpdateMany(ctx,
+		bson.M{"_id": bson.M{"$in": bson.A{accountKey, ipKey}}, "failures": bson.M{"$gt": 0}},
+		bson.M{"$inc": bson.M{"failures": -1}},
+	)
+	return err
+}
+
+// VerifyEmail consumes the token from a verification link and marks the email as verified
+func (h *AuthHandler) VerifyEmail(c *gin.Context) {
+	tokenString := c.Query("token")
+	if tokenString == "" {
+		c.Error(apperrors.BadRequest("Token is required"))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	token, err := consumeUserToken(ctx, h.db, models.TokenPurposeVerifyEmail, tokenString)
+	if err != nil {
+		if err == errInvalidUserToken {
+			c.Error(apperrors.BadRequest(err.Error()))
+			return
+		}
+		c.Error(apperrors.Internal("Failed to check token", err))
+		return
+	}
+
+	// The link only verifies the address it was sent to
+	now := time.Now()
+	filter := bson.M{"_id": token.UserID, "email": token.Email}
+	update := bson.M{"$set": bson.M{"email_verified": true, "email_verified_at": now, "updated_at": now}}
+	result, err := h.db.GetCollection("users").UpdateOne(ctx, filter, update)
+	if err != nil {
+		c.Error(apperrors.Internal("Failed to verify email", err))
+		return
+	}
+	if result.MatchedCount == 0 {
+		c.Error(apperrors.BadRequest(errInvalidUserToken.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Email verified successfully"})
+}
+
+// ResendVerification emails a new verification link to the current user, at most once
+// per resend interval
+func (h *AuthHandler) ResendVerification(c *gin.Context) {
+	userObjID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var user models.User
+	if err := h.db.GetCollection("users").FindOne(ctx, bson.M{"_id": userObjID}).Decode(&user); err != nil {
+		c.Error(apperrors.NotFound("User not found"))
+		return
+	}
+
+	if user.EmailVerified {
+		c.Error(apperrors.Conflict("Email is already verified"))
+		return
+	}
+
+	wait, err := userTokenCooldown(ctx, h.db, user.ID, models.TokenPurposeVerifyEmail, verificationResendInterval)
+	if err != nil {
+		c.Error(apperrors.Internal("Database error", err))
+		return
+	}
+	if wait > 0 {
+		c.Header("Retry-After", strconv.Itoa(int(wait.Seconds()+0.5)))
+		c.Error(apperrors.TooManyRequests("Please wait before requesting another verification email"))
+		return
+	}
+
+	if err := h.sendEmailVerification(user); err != nil {
+		c.Error(apperrors.New(http.StatusBadGateway, "Failed to send verification email", err))
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "Verification email sent"})
+}
+
+// sendEmailVerification issues a verification token for the user's current address and
+// emails the link
+func (h *AuthHandler) sendEmailVerification(user models.User) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	token, err := issueUserToken(ctx, h.db, user.ID, models.TokenPurposeVerifyEmail, user.Email, emailVerificationTTL)
+	if err != nil {
+		return err
+	}
+
+	return h.mailer.Send(ctx, mail.Message{
+		To:      user.Email,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf("Hi %s,\n\nVerify your email address by opening this link within 48 hours:\n\n%s\n",
+			user.FirstName, appLink(h.mail.AppURL, "/verify-email", token)),
+	})
+}
+
+// ForgotPassword emails a single-use password reset link. The response is the same
+// whether or not an account exists, so it cannot be used to discover accounts.
+func (h *AuthHandler) ForgotPassword(c *gin.Context) {
+	var req models.ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperrors.Validation(err))
+		return
+	}
+
+	// Look up the account and send the email in the background so that the response
+	// time does not depend on whether the account exists
+	go h.sendPasswordReset(req.Email)
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "If an account exists for this email, a password reset link has been sent"})
+}
+
+// ResetPassword consumes a reset token, sets the new password and signs out every session
+func (h *AuthHandler) ResetPassword(c *gin.Context) {
+	var req models.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperrors.Validation(err))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	token, err := consumeUserToken(ctx, h.db, models.TokenPurposePasswordReset, req.Token)
+	if err != nil {
+		if err == errInvalidUserToken {
+			c.Error(apperrors.BadRequest(err.Error()))
+			return
+		}
+		c.Error(apperrors.Internal("Failed to check token", err))
+		return
+	}
+
+	hashedPassword, err := utils.HashPassword(req.NewPassword)
+	if err != nil {
+		c.Error(apperrors.Internal("Failed to hash password", err))
+		return
+	}
+
+	update := bson.M{"$set": bson.M{"password": hashedPassword, "updated_at": time.Now()}}
+	result, err := h.db.GetCollection("users").UpdateOne(ctx, bson.M{"_id": token.UserID}, update)
+	if err != nil {
+		c.Error(apperrors.Internal("Failed to update password", err))
+		return
+	}
+	if result.MatchedCount == 0 {
+		c.Error(apperrors.BadRequest(errInvalidUserToken.Error()))
+		return
+	}
+
+	if err := h.sessions.RevokeAll(ctx, token.UserID, ""); err != nil {
+		c.Error(apperrors.Internal("Failed to revoke sessions", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password reset successfully"})
+}
+
+// sendPasswordReset issues a reset token for an active account and emails the link.
+// Unknown and deactivated accounts are silently ignored.
+func (h *AuthHandler) sendPasswordReset(email string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	var user models.User
+	err := h.db.GetCollection("users").FindOne(ctx, bson.M{"email": email, "is_active": true}).Decode(&user)
+	if err != nil {
+		if err != mongo.ErrNoDocuments {
+			slog.Error("Failed to look up user for password reset", "error", err)
+		}
+		return
+	}
+
+	if err := sendPasswordResetEmail(ctx, h.db, h.mailer, h.mail.AppURL, user); err != nil {
+		slog.Error("Failed to send password reset email", "user_id", user.ID.Hex(), "error", err)
+	}
+}
+
 // GetProfile handles getting user profile
 func (h *AuthHandler) GetProfile(c *gin.Context) {
 	userID, exists := c.Get("user_id")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found"})
+		c.Error(apperrors.Unauthorized("User ID not found"))
 		return
 	}
 
 	objectID, err := primitive.ObjectIDFromHex(userID.(string))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		c.Error(apperrors.BadRequest("Invalid user ID"))
 		return
 	}
 
@@ -161,13 +581,31 @@ func (h *AuthHandler) GetProfile(c *gin.Context) {
 	var user models.User
 	err = collection.FindOne(c, bson.M{"_id": objectID}).Decode(&user)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		c.Error(apperrors.NotFound("User not found"))
 		return
 	}
 
 	c.JSON(http.StatusOK, user.ToResponse())
 }
 
+// startSession issues a token for the user and records its session so that it can be revoked
+func (h *AuthHandler) startSession(c *gin.Context, user models.User, mfa bool) (string, error) {
+	permissions, err := rolePermissions(c, h.db, user.Role)
+	if err != nil {
+		return "", err
+	}
+
+	token, claims, err := h.jwtManager.IssueToken(user.ID.Hex(), user.Email, user.Role.String(), permissions, mfa)
+	if err != nil {
+		return "", err
+	}
+
+	if err := h.sessions.Create(c, user.ID, claims, c.Request.UserAgent(), c.ClientIP()); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
 // AdminDashboard handles admin dashboard
 func (h *AuthHandler) AdminDashboard(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
@@ -179,3 +617,34 @@ func (h *AuthHandler) AdminDashboard(c *gin.Context) {
 		},
 	})
 }
+
+// Helper functions
+
+var (
+	dummyHashOnce sync.Once
+	dummyHash     string
+)
+
+// dummyPasswordHash returns a bcrypt hash to check passwords against when the account
+// does not exist, so that failed logins take the same time either way
+func dummyPasswordHash() string {
+	dummyHashOnce.Do(func() {
+		dummyHash, _ = utils.HashPassword("dummy-password-for-timing")
+	})
+	return dummyHash
+}
+
+// sendPasswordResetEmail issues a password reset token and emails the link to the user
+func sendPasswordResetEmail(ctx context.Context, db *database.Client, mailer mail.Sender, appURL string, user models.User) error {
+	token, err := issueUserToken(ctx, db, user.ID, models.TokenPurposePasswordReset, "", passwordResetTTL)
+	if err != nil {
+		return err
+	}
+
+	return mailer.Send(ctx, mail.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Hi %s,\n\nReset your password by opening this link within one hour:\n\n%s\n\nIf you did not ask for a password reset, you can ignore this email.\n",
+			user.FirstName, appLink(appURL, "/reset-password", token)),
+	})
+}
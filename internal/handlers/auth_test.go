@@ -3,21 +3,26 @@ package handlers
 import (
 	"bytes"
 	"encoding/json"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"testing"
 
 	"ecommerce-backend/internal/config"
+	"ecommerce-backend/internal/middleware"
 	"ecommerce-backend/internal/models"
 	"ecommerce-backend/internal/utils"
+	"ecommerce-backend/internal/validation"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 	"github.com/stretchr/testify/assert"
 )
 
 func TestAuthHandler_Register(t *testing.T) {
 	gin.SetMode(gin.TestMode)
+	binding.Validator = validation.NewStructValidator()
 
 	// If no MongoDB is configured, skip Register tests to avoid nil DB panics
 	if os.Getenv("MONGODB_URI") == "" {
@@ -70,20 +75,24 @@ func TestAuthHandler_Register(t *testing.T) {
 
 			// Setup
 			w := httptest.NewRecorder()
-			c, _ := gin.CreateTestContext(w)
 
 			// Create request body
 			jsonBody, _ := json.Marshal(tt.requestBody)
-			c.Request = httptest.NewRequest("POST", "/api/auth/register", bytes.NewBuffer(jsonBody))
-			c.Request.Header.Set("Content-Type", "application/json")
+			req := httptest.NewRequest("POST", "/api/auth/register", bytes.NewBuffer(jsonBody))
+			req.Header.Set("Content-Type", "application/json")
 
 			// Create handler (in real test, you'd mock the database)
 			cfg := &config.JWTConfig{Secret: "test-secret", Expiration: 24 * 60 * 60 * 1000000000} // 24 hours in nanoseconds
 			jwtManager := utils.NewJWTManager(cfg)
 			handler := &AuthHandler{jwtManager: jwtManager}
 
+			// Errors are rendered by the error middleware
+			router := gin.New()
+			router.Use(middleware.ErrorHandler(config.EnvTest, slog.Default()))
+			router.POST("/api/auth/register", handler.Register)
+
 			// Execute
-			handler.Register(c)
+			router.ServeHTTP(w, req)
 
 			// Assert
 			assert.Equal(t, tt.expectedStatus, w.Code)
@@ -93,6 +102,7 @@ func TestAuthHandler_Register(t *testing.T) {
 
 func TestAuthHandler_Login(t *testing.T) {
 	gin.SetMode(gin.TestMode)
+	binding.Validator = validation.NewStructValidator()
 
 	tests := []struct {
 		name           string
@@ -126,20 +136,24 @@ func TestAuthHandler_Login(t *testing.T) {
 
 			// Setup
 			w := httptest.NewRecorder()
-			c, _ := gin.CreateTestContext(w)
 
 			// Create request body
 			jsonBody, _ := json.Marshal(tt.requestBody)
-			c.Request = httptest.NewRequest("POST", "/api/auth/login", bytes.NewBuffer(jsonBody))
-			c.Request.Header.Set("Content-Type", "application/json")
+			req := httptest.NewRequest("POST", "/api/auth/login", bytes.NewBuffer(jsonBody))
+			req.Header.Set("Content-Type", "application/json")
 
 			// Create handler
 			cfg := &config.JWTConfig{Secret: "test-secret", Expiration: 24 * 60 * 60 * 1000000000}
 			jwtManager := utils.NewJWTManager(cfg)
 			handler := &AuthHandler{jwtManager: jwtManager}
 
+			// Errors are rendered by the error middleware
+			router := gin.New()
+			router.Use(middleware.ErrorHandler(config.EnvTest, slog.Default()))
+			router.POST("/api/auth/login", handler.Login)
+
 			// Execute
-			handler.Login(c)
+			router.ServeHTTP(w, req)
 
 			// Assert
 			assert.Equal(t, tt.expectedStatus, w.Code)
@@ -0,0 +1,206 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"ecommerce-backend/internal/config"
+	"ecommerce-backend/internal/database"
+	apperrors "ecommerce-backend/internal/errors"
+	"ecommerce-backend/internal/models"
+	"ecommerce-backend/internal/pricing"
+	"ecommerce-backend/internal/promotions"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// errProductUnavailable is returned when a cart references a missing or out of stock product
+var errProductUnavailable = errors.New("One or more products are unavailable")
+
+// CartHandler handles cart pricing requests
+type CartHandler struct {
+	db       *database.Client
+	currency string
+	tax      config.TaxConfig
+}
+
+// NewCartHandler creates a new CartHandler
+func NewCartHandler(db *database.Client, currency string, taxConfig config.TaxConfig) *CartHandler {
+	return &CartHandler{
+		db:       db,
+		currency: currency,
+		tax:      taxConfig,
+	}
+}
+
+// PriceCart prices the submitted cart and applies eligible promotions and codes
+func (h *CartHandler) PriceCart(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperrors.Unauthorized("Unauthorized"))
+		return
+	}
+
+	userObjID, err := primitive.ObjectIDFromHex(userID.(string))
+	if err != nil {
+		c.Error(apperrors.BadRequest("Invalid user ID"))
+		return
+	}
+
+	var req models.CartRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperrors.Validation(err))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	priceList, err := loadPriceList(ctx, c, h.db, h.currency, req.Currency)
+	if err != nil {
+		respondPriceListError(c, err)
+		return
+	}
+
+	cart, err := loadCart(ctx, h.db, userObjID, priceList, req.Items)
+	if err != nil {
+		if err == errProductUnavailable {
+			c.Error(apperrors.BadRequest(err.Error()))
+			return
+		}
+		c.Error(apperrors.Internal("Failed to load cart products", err))
+		return
+	}
+
+	result, err := evaluatePromotions(ctx, h.db, cart, priceList, req.Codes)
+	if err != nil {
+		c.Error(apperrors.Internal("Failed to evaluate promotions", err))
+		return
+	}
+
+	// Tax is estimated for the store's location until a shipping address is known
+	breakdown, err := calculateTax(ctx, h.db, h.tax, cart, result.Discount, models.NewMoney(0, priceList.Currency), nil)
+	if err != nil {
+		c.Error(apperrors.Internal("Failed to calculate tax", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.CartTotalsResponse{
+		Lines:         cart.Lines,
+		Subtotal:      result.Subtotal,
+		Discount:      result.Discount,
+		Tax:           breakdown.Tax,
+		Total:         orderTotal(result.Total, models.NewMoney(0, priceList.Currency), breakdown),
+		TaxBreakdown:  breakdown,
+		FreeShipping:  result.FreeShipping,
+		Applied:       result.Applied,
+		RejectedCodes: result.RejectedCodes,
+	})
+}
+
+// Helper functions
+
+// loadCart resolves cart items against the products collection using current prices
+// from the price list. Products without a price in the list currency, or without enough
+// stock for the requested quantity, cannot be sold.
+func loadCart(ctx context.Context, db *database.Client, userID primitive.ObjectID, priceList pricing.PriceList, items []models.CartItemRequest) (models.Cart, error) {
+	cart := models.Cart{UserID: userID}
+
+	quantities := make(map[primitive.ObjectID]int)
+	var order []primitive.ObjectID
+	for _, item := range items {
+		objID, err := primitive.ObjectIDFromHex(item.ProductID)
+		if err != nil {
+			return cart, errProductUnavailable
+		}
+		if _, seen := quantities[objID]; !seen {
+			order = append(order, objID)
+		}
+		quantities[objID] += item.Quantity
+	}
+
+	cursor, err := db.GetCollection("products").Find(ctx, bson.M{"_id": bson.M{"$in": order}})
+	if err != nil {
+		return cart, err
+	}
+	defer cursor.Close(ctx)
+
+	var products []models.Product
+	if err := cursor.All(ctx, &products); err != nil {
+		return cart, err
+	}
+
+	byID := make(map[primitive.ObjectID]models.Product, len(products))
+	for _, product := range products {
+		byID[product.ID] = product
+	}
+
+	for _, id := range order {
+		product, ok := byID[id]
+		if !ok || !product.InStock || product.Stock < quantities[id] {
+			return cart, errProductUnavailable
+		}
+		price, ok := priceList.Price(product)
+		if !ok {
+			return cart, errProductUnavailable
+		}
+		cart.Lines = append(cart.Lines, models.CartLine{
+			ProductID: product.ID,
+			Name:      product.Name,
+			Category:  product.Category,
+			Quantity:  quantities[id],
+			UnitPrice: price,
+			Weight:    product.Weight,
+			TaxClass:  product.TaxClass.OrDefault(),
+		})
+	}
+
+	return cart, nil
+}
+
+// evaluatePromotions loads the applicable promotions and the user's usage, then runs the
+// engine with fixed amounts converted into the cart's currency
+func evaluatePromotions(ctx context.Context, db *database.Client, cart models.Cart, priceList pricing.PriceList, codes []string) (promotions.Result, error) {
+	active, err := loadActivePromotions(ctx, db, codes)
+	if err != nil {
+		return promotions.Result{}, err
+	}
+	for i := range active {
+		active[i] = priceList.Promotion(active[i])
+	}
+
+	usage, err := loadPromotionUsage(ctx, db, cart.UserID, active)
+	if err != nil {
+		return promotions.Result{}, err
+	}
+
+	return promotions.Evaluate(cart, active, promotions.Context{
+		Now:       time.Now(),
+		Codes:     codes,
+		UserUsage: usage,
+	}), nil
+}
+
+// orderTotal adds shipping to the discounted total, plus tax when prices exclude it
+func orderTotal(discounted, shippingCost models.Money, breakdown models.TaxBreakdown) models.Money {
+	total := discounted.Add(shippingCost)
+	if !breakdown.PricesIncludeTax {
+		total = total.Add(breakdown.Tax)
+	}
+	return total
+}
+
+// normalizeMoney defaults a submitted amount to the store currency and rejects negative
+// amounts and amounts in any other currency
+func normalizeMoney(m models.Money, currency string) (models.Money, bool) {
+	if m.Currency == "" {
+		m.Currency = currency
+	}
+	m.Currency = strings.ToUpper(m.Currency)
+	return m, m.Currency == currency && m.Amount >= 0
+}
@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"ecommerce-backend/internal/database"
+	apperrors "ecommerce-backend/internal/errors"
+	"ecommerce-backend/internal/models"
+	"ecommerce-backend/internal/pricing"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// CurrencyHandler handles admin management of exchange rates
+type CurrencyHandler struct {
+	db       *database.Client
+	currency string
+}
+
+// NewCurrencyHandler creates a new CurrencyHandler
+func NewCurrencyHandler(db *database.Client, currency string) *CurrencyHandler {
+	return &CurrencyHandler{
+		db:       db,
+		currency: currency,
+	}
+}
+
+// GetExchangeRates lists the exchange rates from the store currency (requires currency:write)
+func (h *CurrencyHandler) GetExchangeRates(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	rates, err := findExchangeRates(ctx, h.db, h.currency)
+	if err != nil {
+		c.Error(apperrors.Internal("Failed to fetch exchange rates", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"base":  h.currency,
+		"rates": rates,
+	})
+}
+
+// SetExchangeRate creates or replaces the exchange rate for a currency (requires currency:write)
+func (h *CurrencyHandler) SetExchangeRate(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperrors.Unauthorized("Unauthorized"))
+		return
+	}
+
+	currency := strings.ToUpper(c.Param("currency"))
+	if !models.IsValidCurrency(currency) || currency == h.currency {
+		c.Error(apperrors.BadRequest("Invalid currency"))
+		return
+	}
+
+	var req models.ExchangeRateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperrors.Validation(err))
+		return
+	}
+
+	adminID, err := primitive.ObjectIDFromHex(userID.(string))
+	if err != nil {
+		c.Error(apperrors.BadRequest("Invalid user ID"))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	filter := bson.M{"base": h.currency, "currency": currency}
+	update := bson.M{
+		"$set": bson.M{
+			"rate":       req.Rate,
+			"updated_by": adminID,
+			"updated_at": now,
+		},
+		"$setOnInsert": bson.M{"created_at": now},
+	}
+
+	var rate models.ExchangeRate
+	upsert := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	err = h.db.GetCollection("exchange_rates").FindOneAndUpdate(ctx, filter, update, upsert).Decode(&rate)
+	if err != nil {
+		c.Error(apperrors.Internal("Failed to save exchange rate", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Exchange rate saved successfully",
+		"rate":    rate,
+	})
+}
+
+// DeleteExchangeRate removes the exchange rate for a currency (requires currency:write)
+func (h *CurrencyHandler) DeleteExchangeRate(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{"base": h.currency, "currency": strings.ToUpper(c.Param("currency"))}
+	result, err := h.db.GetCollection("exchange_rates").DeleteOne(ctx, filter)
+	if err != nil {
+		c.Error(apperrors.Internal("Failed to delete exchange rate", err))
+		return
+	}
+
+	if result.DeletedCount == 0 {
+		c.Error(apperrors.NotFound("Exchange rate not found"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Exchange rate deleted successfully"})
+}
+
+// Helper functions
+
+// findExchangeRates lists the exchange rates from the base currency
+func findExchangeRates(ctx context.Context, db *database.Client, base string) ([]models.ExchangeRate, error) {
+	findOptions := options.Find().SetSort(bson.D{{Key: "currency", Value: 1}})
+	cursor, err := db.GetCollection("exchange_rates").Find(ctx, bson.M{"base": base}, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	rates := []models.ExchangeRate{}
+	if err := cursor.All(ctx, &rates); err != nil {
+		return nil, err
+	}
+	return rates, nil
+}
+
+// loadPriceList picks the currency to price in: the explicit currency when given, then
+// the currency query parameter, then the Accept-Currency header, then the store currency
+func loadPriceList(ctx context.Context, c *gin.Context, db *database.Client, base, currency string) (pricing.PriceList, error) {
+	var preferred []string
+	switch {
+	case currency != "":
+		preferred = []string{currency}
+	case c.Query("currency") != "":
+		preferred = []string{c.Query("currency")}
+	default:
+		preferred = pricing.ParseAcceptCurrency(c.GetHeader("Accept-Currency"))
+	}
+
+	// The store currency needs no exchange rate
+	if len(preferred) == 0 || strings.EqualFold(preferred[0], base) {
+		return pricing.NewPriceList(base, base, nil)
+	}
+
+	rates, err := findExchangeRates(ctx, db, base)
+	if err != nil {
+		return pricing.PriceList{}, err
+	}
+	return pricing.Negotiate(base, preferred, rates)
+}
+
+// respondPriceListError writes the response for a failed loadPriceList
+func respondPriceListError(c *gin.Context, err error) {
+	if err == pricing.ErrUnsupportedCurrency {
+		c.Error(apperrors.BadRequest("Currency not supported"))
+		return
+	}
+	c.Error(apperrors.Internal("Failed to load exchange rates", err))
+}
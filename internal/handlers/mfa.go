@@ -0,0 +1,273 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"ecommerce-backend/internal/database"
+	apperrors "ecommerce-backend/internal/errors"
+	"ecommerce-backend/internal/mfa"
+	"ecommerce-backend/internal/models"
+	"ecommerce-backend/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// SetupMFA starts two-factor enrolment by generating a secret for the user's
+// authenticator app. It takes effect once EnableMFA confirms a code from the app.
+func (h *ProfileHandler) SetupMFA(c *gin.Context) {
+	userObjID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.MFASetupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperrors.Validation(err))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	user, ok := h.checkPassword(ctx, c, userObjID, req.Password)
+	if !ok {
+		return
+	}
+
+	if user.MFAEnabled {
+		c.Error(apperrors.Conflict("Two-factor authentication is already enabled"))
+		return
+	}
+
+	secret, err := mfa.GenerateSecret()
+	if err != nil {
+		c.Error(apperrors.Internal("Failed to generate secret", err))
+		return
+	}
+
+	update := bson.M{"$set": bson.M{"mfa_pending_secret": secret, "updated_at": time.Now()}}
+	if _, err := h.db.GetCollection("users").UpdateOne(ctx, bson.M{"_id": user.ID}, update); err != nil {
+		c.Error(apperrors.Internal("Failed to start two-factor setup", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.MFASetupResponse{
+		Secret:          secret,
+		ProvisioningURI: mfa.ProvisioningURI(h.auth.MFAIssuer, user.Email, secret),
+	})
+}
+
+// EnableMFA turns on two-factor authentication once the user proves their app generates
+// codes for the pending secret, and returns the recovery codes
+func (h *ProfileHandler) EnableMFA(c *gin.Context) {
+	userObjID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperrors.Validation(err))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	user, ok := h.findUser(ctx, c, userObjID)
+	if !ok {
+		return
+	}
+
+	if user.MFAEnabled {
+		c.Error(apperrors.Conflict("Two-factor authentication is already enabled"))
+		return
+	}
+	if user.MFAPending == "" {
+		c.Error(apperrors.BadRequest("Two-factor setup has not been started"))
+		return
+	}
+
+	step, valid := mfa.Validate(user.MFAPending, req.Code, time.Now())
+	if !valid {
+		c.Error(apperrors.BadRequest("Invalid authentication code"))
+		return
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		c.Error(apperrors.Internal("Failed to generate recovery codes", err))
+		return
+	}
+
+	// The filter fails if setup was restarted with a new secret in the meantime
+	result, err := h.db.GetCollection("users").UpdateOne(ctx,
+		bson.M{"_id": user.ID, "mfa_pending_secret": user.MFAPending},
+		bson.M{
+			"$set": bson.M{
+				"mfa_enabled":    true,
+				"mfa_secret":     user.MFAPending,
+				"mfa_last_step":  step,
+				"recovery_codes": hashes,
+				"updated_at":     time.Now(),
+			},
+			"$unset": bson.M{"mfa_pending_secret": ""},
+		},
+	)
+	if err != nil {
+		c.Error(apperrors.Internal("Failed to enable two-factor authentication", err))
+		return
+	}
+	if result.MatchedCount == 0 {
+		c.Error(apperrors.Conflict("Two-factor setup was restarted, scan the new code"))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// DisableMFA turns off two-factor authentication after checking the password and a code
+func (h *ProfileHandler) DisableMFA(c *gin.Context) {
+	userObjID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.MFADisableRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperrors.Validation(err))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	user, ok := h.checkPassword(ctx, c, userObjID, req.Password)
+	if !ok {
+		return
+	}
+
+	if !user.MFAEnabled {
+		c.Error(apperrors.BadRequest("Two-factor authentication is not enabled"))
+		return
+	}
+
+	valid, err := verifySecondFactor(ctx, h.db, user, req.Code)
+	if err != nil {
+		c.Error(apperrors.Internal("Database error", err))
+		return
+	}
+	if !valid {
+		c.Error(apperrors.Unauthorized("Invalid authentication code"))
+		return
+	}
+
+	update := bson.M{
+		"$set":   bson.M{"mfa_enabled": false, "updated_at": time.Now()},
+		"$unset": bson.M{"mfa_secret": "", "mfa_pending_secret": "", "mfa_last_step": "", "recovery_codes": ""},
+	}
+	if _, err := h.db.GetCollection("users").UpdateOne(ctx, bson.M{"_id": user.ID}, update); err != nil {
+		c.Error(apperrors.Internal("Failed to disable two-factor authentication", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Two-factor authentication disabled"})
+}
+
+// RegenerateRecoveryCodes replaces the user's recovery codes after checking an
+// authenticator code
+func (h *ProfileHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	userObjID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperrors.Validation(err))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	user, ok := h.findUser(ctx, c, userObjID)
+	if !ok {
+		return
+	}
+
+	if !user.MFAEnabled {
+		c.Error(apperrors.BadRequest("Two-factor authentication is not enabled"))
+		return
+	}
+
+	valid, err := verifySecondFactor(ctx, h.db, user, req.Code)
+	if err != nil {
+		c.Error(apperrors.Internal("Database error", err))
+		return
+	}
+	if !valid {
+		c.Error(apperrors.Unauthorized("Invalid authentication code"))
+		return
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		c.Error(apperrors.Internal("Failed to generate recovery codes", err))
+		return
+	}
+
+	update := bson.M{"$set": bson.M{"recovery_codes": hashes, "updated_at": time.Now()}}
+	if _, err := h.db.GetCollection("users").UpdateOne(ctx, bson.M{"_id": user.ID}, update); err != nil {
+		c.Error(apperrors.Internal("Failed to save recovery codes", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// Helper functions
+
+// verifySecondFactor checks an authenticator code or an unused recovery code. Each is
+// accepted only once: the code's time step is recorded and recovery codes are removed.
+func verifySecondFactor(ctx context.Context, db *database.Client, user models.User, code string) (bool, error) {
+	collection := db.GetCollection("users")
+
+	if step, ok := mfa.Validate(user.MFASecret, code, time.Now()); ok {
+		result, err := collection.UpdateOne(ctx,
+			bson.M{"_id": user.ID, "mfa_last_step": bson.M{"$not": bson.M{"$gte": step}}},
+			bson.M{"$set": bson.M{"mfa_last_step": step}},
+		)
+		if err != nil {
+			return false, err
+		}
+		return result.MatchedCount == 1, nil
+	}
+
+	hash := utils.HashToken(mfa.NormalizeRecoveryCode(code))
+	result, err := collection.UpdateOne(ctx,
+		bson.M{"_id": user.ID, "recovery_codes": hash},
+		bson.M{"$pull": bson.M{"recovery_codes": hash}},
+	)
+	if err != nil {
+		return false, err
+	}
+	return result.MatchedCount == 1, nil
+}
+
+// newRecoveryCodes generates recovery codes and the hashes to store for them
+func newRecoveryCodes() ([]string, []string, error) {
+	codes, err := mfa.GenerateRecoveryCodes()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	hashes := make([]string, len(codes))
+	for i, code := range codes {
+		hashes[i] = utils.HashToken(mfa.NormalizeRecoveryCode(code))
+	}
+	return codes, hashes, nil
+}
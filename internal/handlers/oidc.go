@@ -0,0 +1,263 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"ecommerce-backend/internal/database"
+	apperrors "ecommerce-backend/internal/errors"
+	"ecommerce-backend/internal/models"
+	"ecommerce-backend/internal/oidc"
+	"ecommerce-backend/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var (
+	// errOIDCEmailRequired is returned when the provider does not share the user's email
+	errOIDCEmailRequired = errors.New("The provider did not share an email address")
+	// errOIDCAccountExists is returned when an account with the email exists but cannot be
+	// linked safely
+	errOIDCAccountExists = errors.New("An account with this email already exists, sign in with your password to continue")
+)
+
+// GetOIDCProviders lists the OpenID Connect providers users can sign in with
+func (h *AuthHandler) GetOIDCProviders(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"providers": h.providers.Names()})
+}
+
+// OIDCLogin starts a login at an OpenID Connect provider and returns the URL to send the
+// user to
+func (h *AuthHandler) OIDCLogin(c *gin.Context) {
+	provider, err := h.providers.Get(c.Param("provider"))
+	if err != nil {
+		c.Error(apperrors.NotFound("Provider not found"))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	state, err := utils.GenerateRandomToken()
+	if err != nil {
+		c.Error(apperrors.Internal("Failed to start login", err))
+		return
+	}
+	nonce, err := utils.GenerateRandomToken()
+	if err != nil {
+		c.Error(apperrors.Internal("Failed to start login", err))
+		return
+	}
+	verifier, err := oidc.NewCodeVerifier()
+	if err != nil {
+		c.Error(apperrors.Internal("Failed to start login", err))
+		return
+	}
+
+	authURL, err := provider.AuthCodeURL(ctx, state, nonce, oidc.CodeChallenge(verifier))
+	if err != nil {
+		slog.Error("Failed to reach OIDC provider", "provider", provider.Name(), "error", err)
+		c.Error(apperrors.New(http.StatusBadGateway, "Provider is unavailable", err))
+		return
+	}
+
+	now := time.Now()
+	_, err = h.db.GetCollection("oidc_states").InsertOne(ctx, models.OIDCState{
+		ID:           primitive.NewObjectID(),
+		StateHash:    utils.HashToken(state),
+		Provider:     provider.Name(),
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		ExpiresAt:    now.Add(oidcStateTTL),
+		CreatedAt:    now,
+	})
+	if err != nil {
+		c.Error(apperrors.Internal("Failed to start login", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.OIDCLoginResponse{AuthorizationURL: authURL})
+}
+
+// OIDCCallback finishes a login at an OpenID Connect provider. The user is found by their
+// linked identity, linked by verified email, or created, and then signed in as usual.
+func (h *AuthHandler) OIDCCallback(c *gin.Context) {
+	provider, err := h.providers.Get(c.Param("provider"))
+	if err != nil {
+		c.Error(apperrors.NotFound("Provider not found"))
+		return
+	}
+
+	var req models.OIDCCallbackRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperrors.Validation(err))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Each state is used once, which also stops a code from being replayed
+	var state models.OIDCState
+	err = h.db.GetCollection("oidc_states").FindOneAndDelete(ctx, bson.M{
+		"state_hash": utils.HashToken(req.State),
+		"provider":   provider.Name(),
+		"expires_at": bson.M{"$gt": time.Now()},
+	}).Decode(&state)
+	if err == mongo.ErrNoDocuments {
+		c.Error(apperrors.BadRequest("Invalid or expired login state"))
+		return
+	}
+	if err != nil {
+		c.Error(apperrors.Internal("Database error", err))
+		return
+	}
+
+	token, err := provider.Exchange(ctx, req.Code, state.CodeVerifier)
+	if err != nil {
+		slog.Warn("OIDC code exchange failed", "provider", provider.Name(), "error", err)
+		c.Error(apperrors.Unauthorized("Failed to sign in with provider"))
+		return
+	}
+
+	claims, err := provider.VerifyIDToken(ctx, token.IDToken, state.Nonce)
+	if err != nil {
+		slog.Warn("OIDC ID token rejected", "provider", provider.Name(), "error", err)
+		c.Error(apperrors.Unauthorized("Failed to sign in with provider"))
+		return
+	}
+
+	user, created, err := findOrCreateOIDCUser(ctx, h.db, provider.Name(), claims)
+	if err != nil {
+		switch err {
+		case errOIDCEmailRequired:
+			c.Error(apperrors.BadRequest(err.Error()))
+		case errOIDCAccountExists:
+			c.Error(apperrors.Conflict(err.Error()))
+		default:
+			c.Error(apperrors.Internal("Failed to sign in with provider", err))
+		}
+		return
+	}
+
+	if !user.IsActive {
+		c.Error(apperrors.Unauthorized("Account is deactivated"))
+		return
+	}
+
+	if created && !user.EmailVerified {
+		if err := h.sendEmailVerification(user); err != nil {
+			slog.Error("Failed to send verification email", "user_id", user.ID.Hex(), "error", err)
+		}
+	}
+
+	if user.MFAEnabled {
+		h.startMFAChallenge(c, user)
+		return
+	}
+
+	sessionToken, err := h.startSession(c, user, false)
+	if err != nil {
+		c.Error(apperrors.Internal("Failed to generate token", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.LoginResponse{
+		Token: sessionToken,
+		Role:  user.Role,
+	})
+}
+
+// Helper functions
+
+// findOrCreateOIDCUser returns the user linked to the provider account. An existing user
+// with the same email is only linked when both the provider and our records consider the
+// address verified, so that neither side can be used to take over the other's account.
+func findOrCreateOIDCUser(ctx context.Context, db *database.Client, provider string, claims *oidc.Claims) (models.User, bool, error) {
+	collection := db.GetCollection("users")
+
+	var user models.User
+	err := collection.FindOne(ctx, bson.M{
+		"identities": bson.M{"$elemMatch": bson.M{"provider": provider, "subject": claims.Subject}},
+	}).Decode(&user)
+	if err == nil {
+		return user, false, nil
+	}
+	if err != mongo.ErrNoDocuments {
+		return user, false, err
+	}
+
+	if claims.Email == "" {
+		return user, false, errOIDCEmailRequired
+	}
+
+	identity := models.Identity{Provider: provider, Subject: claims.Subject, LinkedAt: time.Now()}
+
+	err = collection.FindOne(ctx, bson.M{"email": claims.Email}).Decode(&user)
+	if err == nil {
+		if !bool(claims.EmailVerified) || !user.EmailVerified {
+			return user, false, errOIDCAccountExists
+		}
+
+		// A user has at most one identity per provider
+		result, err := collection.UpdateOne(ctx,
+			bson.M{"_id": user.ID, "identities.provider": bson.M{"$ne": provider}},
+			bson.M{"$push": bson.M{"identities": identity}, "$set": bson.M{"updated_at": time.Now()}},
+		)
+		if err != nil {
+			return user, false, err
+		}
+		if result.MatchedCount == 0 {
+			return user, false, errOIDCAccountExists
+		}
+		user.Identities = append(user.Identities, identity)
+		return user, false, nil
+	}
+	if err != mongo.ErrNoDocuments {
+		return user, false, err
+	}
+
+	firstName, lastName := oidcNames(claims)
+	now := time.Now()
+	user = models.User{
+		ID:            primitive.NewObjectID(),
+		Email:         claims.Email,
+		FirstName:     firstName,
+		LastName:      lastName,
+		Role:          models.RoleUser,
+		IsActive:      true,
+		EmailVerified: bool(claims.EmailVerified),
+		Identities:    []models.Identity{identity},
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	if user.EmailVerified {
+		user.EmailVerifiedAt = &now
+	}
+
+	if _, err := collection.InsertOne(ctx, user); err != nil {
+		return user, false, err
+	}
+	return user, true, nil
+}
+
+// oidcNames returns the user's first and last name from the ID token, falling back to
+// the full name and then the email address
+func oidcNames(claims *oidc.Claims) (string, string) {
+	if claims.GivenName != "" || claims.FamilyName != "" {
+		return claims.GivenName, claims.FamilyName
+	}
+	if name := strings.TrimSpace(claims.Name); name != "" {
+		first, last, _ := strings.Cut(name, " ")
+		return first, strings.TrimSpace(last)
+	}
+	local, _, _ := strings.Cut(claims.Email, "@")
+	return local, ""
+}
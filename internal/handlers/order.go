@@ -0,0 +1,505 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"ecommerce-backend/internal/config"
+	"ecommerce-backend/internal/database"
+	apperrors "ecommerce-backend/internal/errors"
+	"ecommerce-backend/internal/models"
+	"ecommerce-backend/internal/payments"
+	"ecommerce-backend/internal/shipping"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// OrderHandler handles checkout and order-related HTTP requests
+type OrderHandler struct {
+	db       *database.Client
+	payments *payments.Registry
+	currency string
+	tax      config.TaxConfig
+	// requireVerifiedEmail blocks checkout for users who have not verified their email
+	requireVerifiedEmail bool
+}
+
+// NewOrderHandler creates a new OrderHandler
+func NewOrderHandler(db *database.Client, paymentRegistry *payments.Registry, currency string, taxConfig config.TaxConfig, requireVerifiedEmail bool) *OrderHandler {
+	return &OrderHandler{
+		db:                   db,
+		payments:             paymentRegistry,
+		currency:             currency,
+		tax:                  taxConfig,
+		requireVerifiedEmail: requireVerifiedEmail,
+	}
+}
+
+// Checkout places an order for the submitted cart and creates a payment intent
+func (h *OrderHandler) Checkout(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperrors.Unauthorized("Unauthorized"))
+		return
+	}
+
+	userObjID, err := primitive.ObjectIDFromHex(userID.(string))
+	if err != nil {
+		c.Error(apperrors.BadRequest("Invalid user ID"))
+		return
+	}
+
+	var req models.CheckoutRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperrors.Validation(err))
+		return
+	}
+
+	if (req.ShippingAddress != nil && req.ShippingAddressID != "") || (req.BillingAddress != nil && req.BillingAddressID != "") {
+		c.Error(apperrors.BadRequest("Provide either an address or an address ID"))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if h.requireVerifiedEmail {
+		var user models.User
+		if err := h.db.GetCollection("users").FindOne(ctx, bson.M{"_id": userObjID}).Decode(&user); err != nil {
+			c.Error(apperrors.NotFound("User not found"))
+			return
+		}
+		if !user.EmailVerified {
+			c.Error(apperrors.Forbidden("Email address must be verified before checkout"))
+			return
+		}
+	}
+
+	// Addresses are copied into the order so later address book edits don't rewrite it
+	shippingAddress, ok := h.checkoutAddress(ctx, c, userObjID, req.ShippingAddress, req.ShippingAddressID)
+	if !ok {
+		return
+	}
+	billingAddress, ok := h.checkoutAddress(ctx, c, userObjID, req.BillingAddress, req.BillingAddressID)
+	if !ok {
+		return
+	}
+
+	// The order is locked to the currency and exchange rate it is placed in
+	priceList, err := loadPriceList(ctx, c, h.db, h.currency, req.Currency)
+	if err != nil {
+		respondPriceListError(c, err)
+		return
+	}
+
+	cart, err := loadCart(ctx, h.db, userObjID, priceList, req.Items)
+	if err != nil {
+		if err == errProductUnavailable {
+			c.Error(apperrors.BadRequest(err.Error()))
+			return
+		}
+		c.Error(apperrors.Internal("Failed to load cart products", err))
+		return
+	}
+
+	totals, err := evaluatePromotions(ctx, h.db, cart, priceList, req.Codes)
+	if err != nil {
+		c.Error(apperrors.Internal("Failed to evaluate promotions", err))
+		return
+	}
+
+	// Shipping is optional so that orders without physical goods can be placed
+	var shippingMethod *models.ShippingQuote
+	if shippingAddress != nil {
+		parcel := shipping.Parcel{
+			Weight:       cart.Weight(),
+			Subtotal:     totals.Total,
+			FreeShipping: totals.FreeShipping,
+		}
+		_, quotes, err := quoteShipping(ctx, h.db, *shippingAddress, parcel, priceList)
+		if err != nil {
+			if err == errNoShippingZone {
+				c.Error(apperrors.Unprocessable(err.Error()))
+				return
+			}
+			c.Error(apperrors.Internal("Failed to quote shipping", err))
+			return
+		}
+
+		for i := range quotes {
+			if quotes[i].MethodID.Hex() == req.ShippingMethodID {
+				shippingMethod = &quotes[i]
+				break
+			}
+		}
+		if shippingMethod == nil {
+			c.Error(apperrors.BadRequest("Shipping method not available for this address"))
+			return
+		}
+	}
+
+	shippingCost := models.NewMoney(0, priceList.Currency)
+	if shippingMethod != nil {
+		shippingCost = shippingMethod.Price
+	}
+
+	breakdown, err := calculateTax(ctx, h.db, h.tax, cart, totals.Discount, shippingCost, shippingAddress)
+	if err != nil {
+		c.Error(apperrors.Internal("Failed to calculate tax", err))
+		return
+	}
+
+	provider, err := h.payments.Default()
+	if err != nil {
+		c.Error(apperrors.Internal("Payment provider not configured", err))
+		return
+	}
+
+	now := time.Now()
+	order := models.Order{
+		ID:              primitive.NewObjectID(),
+		UserID:          userObjID,
+		Currency:        priceList.Currency,
+		ExchangeRate:    priceList.Rate,
+		Subtotal:        totals.Subtotal,
+		Discount:        totals.Discount,
+		ShippingCost:    shippingCost,
+		Tax:             breakdown.Tax,
+		Total:           orderTotal(totals.Total, shippingCost, breakdown),
+		Promotions:      totals.Applied,
+		ShippingAddress: shippingAddress,
+		BillingAddress:  billingAddress,
+		ShippingMethod:  shippingMethod,
+		TaxBreakdown:    &breakdown,
+		AmountPaid:      models.NewMoney(0, priceList.Currency),
+		AmountRefunded:  models.NewMoney(0, priceList.Currency),
+		Status:          models.OrderStatusPending,
+		PaymentStatus:   models.PaymentStatusPending,
+		PaymentProvider: provider.Name(),
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+	for _, line := range cart.Lines {
+		order.Items = append(order.Items, models.OrderItem{
+			ProductID: line.ProductID,
+			Name:      line.Name,
+			Category:  line.Category,
+			Quantity:  line.Quantity,
+			UnitPrice: line.UnitPrice,
+			Total:     line.Total(),
+		})
+	}
+
+	// Stock is taken before payment so that concurrent checkouts cannot oversell
+	if err := reserveStock(ctx, h.db, order.Items); err != nil {
+		if err == errProductUnavailable {
+			c.Error(apperrors.Conflict(err.Error()))
+			return
+		}
+		c.Error(apperrors.Internal("Failed to reserve stock", err))
+		return
+	}
+
+	// Promotion uses are claimed before the order is placed, so usage limits hold under
+	// concurrent checkouts
+	if err := claimPromotions(ctx, h.db, order); err != nil {
+		releaseStock(ctx, h.db, order.Items)
+		if err == errPromotionUnavailable {
+			c.Error(apperrors.Conflict(err.Error()))
+			return
+		}
+		c.Error(apperrors.Internal("Failed to claim promotions", err))
+		return
+	}
+
+	// Fully discounted orders have nothing to collect
+	var clientSecret string
+	if order.Total.IsPositive() {
+		intent, err := provider.CreateIntent(ctx, payments.IntentRequest{
+			OrderID: order.ID.Hex(),
+			Amount:  order.Total,
+		})
+		if err != nil {
+			releaseStock(ctx, h.db, order.Items)
+			releasePromotions(ctx, h.db, order.UserID, order.Promotions)
+			c.Error(apperrors.New(http.StatusBadGateway, "Failed to create payment", err))
+			return
+		}
+		order.PaymentIntentID = intent.ID
+		clientSecret = intent.ClientSecret
+	} else {
+		order.Status = models.OrderStatusPaid
+		order.PaymentStatus = models.PaymentStatusPaid
+		order.PaidAt = &now
+	}
+
+	if _, err := h.db.GetCollection("orders").InsertOne(ctx, order); err != nil {
+		releaseStock(ctx, h.db, order.Items)
+		releasePromotions(ctx, h.db, order.UserID, order.Promotions)
+		c.Error(apperrors.Internal("Failed to create order", err))
+		return
+	}
+
+	// The order is placed and its promotion uses are already counted, so a failure here
+	// only loses the redemption history
+	if err := recordPromotionRedemptions(ctx, h.db, order); err != nil {
+		slog.Error("Failed to record promotion redemptions", "order_id", order.ID.Hex(), "error", err)
+	}
+
+	c.JSON(http.StatusCreated, models.CheckoutResponse{
+		Order:        order,
+		ClientSecret: clientSecret,
+	})
+}
+
+// checkoutAddress resolves an inline or saved checkout address and checks that it can be
+// delivered to. It writes the error response and returns false on failure.
+func (h *OrderHandler) checkoutAddress(ctx context.Context, c *gin.Context, userID primitive.ObjectID, inline *models.Address, addressID string) (*models.Address, bool) {
+	address, err := resolveAddress(ctx, h.db, userID, inline, addressID)
+	if err != nil {
+		if err == errAddressNotFound {
+			c.Error(apperrors.NotFound(err.Error()))
+			return nil, false
+		}
+		c.Error(apperrors.Internal("Failed to load address", err))
+		return nil, false
+	}
+	if address == nil {
+		return nil, true
+	}
+
+	if err := address.Validate(); err != nil {
+		c.Error(apperrors.BadRequest(err.Error()))
+		return nil, false
+	}
+	return address, true
+}
+
+// reserveStock takes each item's quantity out of product stock. An item is only taken
+// when enough stock is left, so two checkouts can never both claim the last unit; if any
+// item falls short, the items already taken are put back and errProductUnavailable is
+// returned.
+func reserveStock(ctx context.Context, db *database.Client, items []models.OrderItem) error {
+	products := db.GetCollection("products")
+	for i, item := range items {
+		result, err := products.UpdateOne(ctx,
+			bson.M{"_id": item.ProductID, "in_stock": true, "stock": bson.M{"$gte": item.Quantity}},
+			bson.M{
+				"$inc": bson.M{"stock": -item.Quantity},
+				"$set": bson.M{"updated_at": time.Now()},
+			},
+		)
+		if err == nil && result.MatchedCount == 0 {
+			err = errProductUnavailable
+		}
+		if err != nil {
+			releaseStock(ctx, db, items[:i])
+			return err
+		}
+
+		// The product that sold its last unit is taken off sale. It is marked as sold out
+		// so that restocking puts it back on sale, unlike a product an admin took off.
+		if _, err := products.UpdateOne(ctx,
+			bson.M{"_id": item.ProductID, "stock": bson.M{"$lte": 0}, "in_stock": true},
+			bson.M{"$set": bson.M{"in_stock": false, "sold_out": true}},
+		); err != nil {
+			slog.Error("Failed to mark product out of stock", "product_id", item.ProductID.Hex(), "error", err)
+		}
+	}
+	return nil
+}
+
+// releaseStock puts reserved quantities back after a checkout fails or an order is cancelled
+func releaseStock(ctx context.Context, db *database.Client, items []models.OrderItem) {
+	for _, item := range items {
+		if err := restock(ctx, db, item.ProductID, item.Quantity); err != nil {
+			slog.Error("Failed to release reserved stock", "product_id", item.ProductID.Hex(), "quantity", item.Quantity, "error", err)
+		}
+	}
+}
+
+// restock adds units to a product's stock. A product that went out of stock by selling
+// its last unit goes back on sale; one an admin took off sale stays off.
+func restock(ctx context.Context, db *database.Client, productID primitive.ObjectID, quantity int) error {
+	restocked := bson.M{"$gt": bson.A{"$stock", 0}}
+	_, err := db.GetCollection("products").UpdateOne(ctx, bson.M{"_id": productID}, mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{
+			"stock":      bson.M{"$add": bson.A{bson.M{"$ifNull": bson.A{"$stock", 0}}, quantity}},
+			"updated_at": time.Now(),
+		}}},
+		{{Key: "$set", Value: bson.M{
+			"in_stock": bson.M{"$cond": bson.A{bson.M{"$and": bson.A{"$sold_out", restocked}}, true, "$in_stock"}},
+			"sold_out": bson.M{"$cond": bson.A{restocked, false, "$sold_out"}},
+		}}},
+	})
+	return err
+}
+
+// cancelOrder cancels an order that was never paid and gives back its stock and promotion
+// uses. The cancellation is conditional on the order still being unpaid, so the stock is
+// only given back once however many callers race to cancel it. It reports whether this
+// call cancelled the order.
+func cancelOrder(ctx context.Context, db *database.Client, order models.Order) (bool, error) {
+	now := time.Now()
+	result, err := db.GetCollection("orders").UpdateOne(ctx,
+		bson.M{
+			"_id":            order.ID,
+			"status":         models.OrderStatusPending,
+			"payment_status": bson.M{"$in": bson.A{models.PaymentStatusPending, models.PaymentStatusFailed}},
+		},
+		bson.M{"$set": bson.M{"status": models.OrderStatusCancelled, "cancelled_at": now, "updated_at": now}},
+	)
+	if err != nil || result.ModifiedCount == 0 {
+		return false, err
+	}
+
+	releaseStock(ctx, db, order.Items)
+	releasePromotions(ctx, db, order.UserID, order.Promotions)
+	return true, nil
+}
+
+// ExpireOrders cancels orders left unpaid since before the cutoff, giving back their stock
+// and promotion uses. Orders with an authorized payment are kept. It returns how many
+// orders were cancelled.
+func (h *OrderHandler) ExpireOrders(ctx context.Context, cutoff time.Time) (int, error) {
+	filter := bson.M{
+		"status":         models.OrderStatusPending,
+		"payment_status": bson.M{"$in": bson.A{models.PaymentStatusPending, models.PaymentStatusFailed}},
+		"created_at":     bson.M{"$lt": cutoff},
+	}
+	cursor, err := h.db.GetCollection("orders").Find(ctx, filter, options.Find().SetLimit(500))
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var orders []models.Order
+	if err := cursor.All(ctx, &orders); err != nil {
+		return 0, err
+	}
+
+	expired := 0
+	for _, order := range orders {
+		cancelled, err := cancelOrder(ctx, h.db, order)
+		if err != nil {
+			return expired, err
+		}
+		if cancelled {
+			expired++
+		}
+	}
+	return expired, nil
+}
+
+// GetOrders lists the current user's orders with pagination
+func (h *OrderHandler) GetOrders(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperrors.Unauthorized("Unauthorized"))
+		return
+	}
+
+	userObjID, err := primitive.ObjectIDFromHex(userID.(string))
+	if err != nil {
+		c.Error(apperrors.BadRequest("Invalid user ID"))
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	orders, total, err := findOrders(ctx, h.db, bson.M{"user_id": userObjID}, page, limit)
+	if err != nil {
+		c.Error(apperrors.Internal("Failed to fetch orders", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.OrderListResponse{
+		Orders: orders,
+		Total:  total,
+		Page:   page,
+		Limit:  limit,
+	})
+}
+
+// GetOrder retrieves one of the current user's orders by ID
+func (h *OrderHandler) GetOrder(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperrors.Unauthorized("Unauthorized"))
+		return
+	}
+
+	userObjID, err := primitive.ObjectIDFromHex(userID.(string))
+	if err != nil {
+		c.Error(apperrors.BadRequest("Invalid user ID"))
+		return
+	}
+
+	orderID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.Error(apperrors.BadRequest("Invalid order ID"))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var order models.Order
+	err = h.db.GetCollection("orders").FindOne(ctx, bson.M{"_id": orderID, "user_id": userObjID}).Decode(&order)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.Error(apperrors.NotFound("Order not found"))
+			return
+		}
+		c.Error(apperrors.Internal("Failed to fetch order", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"order": order})
+}
+
+// Helper functions
+
+// findOrders returns a page of orders matching filter, newest first, with the total count
+func findOrders(ctx context.Context, db *database.Client, filter bson.M, page, limit int) ([]models.Order, int64, error) {
+	collection := db.GetCollection("orders")
+
+	total, err := collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	findOptions := options.Find().
+		SetSkip(int64((page - 1) * limit)).
+		SetLimit(int64(limit)).
+		SetSort(bson.D{{Key: "created_at", Value: -1}})
+
+	cursor, err := collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	orders := []models.Order{}
+	if err := cursor.All(ctx, &orders); err != nil {
+		return nil, 0, err
+	}
+	return orders, total, nil
+}
@@ -0,0 +1,198 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"ecommerce-backend/internal/database"
+	apperrors "ecommerce-backend/internal/errors"
+	"ecommerce-backend/internal/models"
+	"ecommerce-backend/internal/payments"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// maxWebhookBodySize bounds the webhook payload read into memory
+const maxWebhookBodySize = 1 << 20
+
+// PaymentHandler handles payment provider callbacks
+type PaymentHandler struct {
+	db       *database.Client
+	payments *payments.Registry
+}
+
+// NewPaymentHandler creates a new PaymentHandler
+func NewPaymentHandler(db *database.Client, paymentRegistry *payments.Registry) *PaymentHandler {
+	return &PaymentHandler{
+		db:       db,
+		payments: paymentRegistry,
+	}
+}
+
+// Webhook verifies a provider webhook and advances the order's payment state.
+// Events are stored by provider and event ID, so redeliveries are acknowledged
+// without being applied twice.
+func (h *PaymentHandler) Webhook(c *gin.Context) {
+	provider, err := h.payments.Get(c.Param("provider"))
+	if err != nil {
+		c.Error(apperrors.NotFound("Unknown payment provider"))
+		return
+	}
+
+	payload, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxWebhookBodySize))
+	if err != nil {
+		c.Error(apperrors.BadRequest("Invalid request payload"))
+		return
+	}
+
+	event, err := provider.VerifyWebhook(payload, c.Request.Header)
+	if err != nil {
+		if errors.Is(err, payments.ErrInvalidSignature) {
+			c.Error(apperrors.Unauthorized("Invalid signature"))
+			return
+		}
+		c.Error(apperrors.BadRequest("Invalid webhook payload"))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	events := h.db.GetCollection("payment_events")
+	record := models.PaymentEvent{
+		ID:         provider.Name() + ":" + event.ID,
+		Provider:   provider.Name(),
+		EventID:    event.ID,
+		Type:       event.Type,
+		IntentID:   event.IntentID,
+		Amount:     event.Amount,
+		ReceivedAt: time.Now(),
+	}
+
+	if _, err := events.InsertOne(ctx, record); err != nil {
+		if !mongo.IsDuplicateKeyError(err) {
+			c.Error(apperrors.Internal("Failed to record event", err))
+			return
+		}
+		// Redelivery: only reprocess if the earlier attempt did not finish
+		if err := events.FindOne(ctx, bson.M{"_id": record.ID}).Decode(&record); err != nil {
+			c.Error(apperrors.Internal("Failed to load event", err))
+			return
+		}
+		if record.Processed {
+			c.JSON(http.StatusOK, gin.H{"message": "Event already processed"})
+			return
+		}
+	}
+
+	order, err := applyPaymentEvent(ctx, h.db, provider.Name(), *event)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			// Not one of our orders; acknowledge so the provider stops retrying
+			c.JSON(http.StatusOK, gin.H{"message": "No matching order"})
+			return
+		}
+		c.Error(apperrors.Internal("Failed to apply event", err))
+		return
+	}
+
+	processedAt := time.Now()
+	update := bson.M{"$set": bson.M{
+		"processed":    true,
+		"processed_at": processedAt,
+		"order_id":     order.ID,
+	}}
+	if _, err := events.UpdateOne(ctx, bson.M{"_id": record.ID}, update); err != nil {
+		c.Error(apperrors.Internal("Failed to record event", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":        "Event processed",
+		"order_id":       order.ID.Hex(),
+		"payment_status": order.PaymentStatus,
+	})
+}
+
+// Helper functions
+
+// applyPaymentEvent moves the order paid through the intent to its next payment status.
+// The update is conditional on the status it was computed from; if another delivery
+// changed the order in between, the transition is recomputed from the new state.
+func applyPaymentEvent(ctx context.Context, db *database.Client, providerName string, event payments.Event) (models.Order, error) {
+	collection := db.GetCollection("orders")
+	filter := bson.M{"payment_provider": providerName, "payment_intent_id": event.IntentID}
+
+	var order models.Order
+	for attempt := 0; attempt < 3; attempt++ {
+		if err := collection.FindOne(ctx, filter).Decode(&order); err != nil {
+			return order, err
+		}
+
+		next, ok := payments.NextPaymentStatus(order.PaymentStatus, order.Total, event)
+		if !ok {
+			return order, nil
+		}
+
+		now := time.Now()
+		set := bson.M{
+			"payment_status": next,
+			"updated_at":     now,
+		}
+		switch event.Type {
+		case models.PaymentEventSucceeded:
+			// The amount the provider reports is recorded as paid; the order only counts as
+			// paid when it covers the total
+			set["amount_paid"] = event.Amount
+			// A cancelled order has given its stock back, so it stays cancelled and the
+			// payment is left for an admin to refund
+			if next == models.PaymentStatusPaid && order.Status != models.OrderStatusCancelled {
+				set["status"] = models.OrderStatusPaid
+				set["paid_at"] = now
+			}
+		case models.PaymentEventRefunded:
+			if event.Amount.Amount < order.AmountRefunded.Amount {
+				// An older refund total arriving late must not lower the refunded amount
+				return order, nil
+			}
+			set["amount_refunded"] = event.Amount
+		}
+
+		result, err := collection.UpdateOne(ctx,
+			bson.M{"_id": order.ID, "payment_status": order.PaymentStatus, "updated_at": order.UpdatedAt},
+			bson.M{"$set": set},
+		)
+		if err != nil {
+			return order, err
+		}
+		if result.MatchedCount == 1 {
+			if next == models.PaymentStatusAmountMismatch {
+				slog.Warn("Payment does not match the order total", "order_id", order.ID.Hex(), "total", order.Total.String(), "paid", event.Amount.String())
+			}
+			if event.Type == models.PaymentEventSucceeded && order.Status == models.OrderStatusCancelled {
+				slog.Error("Payment received for a cancelled order", "order_id", order.ID.Hex(), "paid", event.Amount.String())
+			}
+			order.PaymentStatus = next
+
+			// A failed payment ends the order, so its stock and promotion uses go back
+			if next == models.PaymentStatusFailed {
+				cancelled, err := cancelOrder(ctx, db, order)
+				if err != nil {
+					return order, err
+				}
+				if cancelled {
+					order.Status = models.OrderStatusCancelled
+				}
+			}
+			return order, nil
+		}
+	}
+
+	return order, errors.New("order changed concurrently")
+}
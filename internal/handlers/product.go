@@ -12,11 +12,13 @@ import (
 	"strings"
 	"time"
 
+	"ecommerce-backend/internal/audit"
 	"ecommerce-backend/internal/database"
+	apperrors "ecommerce-backend/internal/errors"
 	"ecommerce-backend/internal/models"
+	"ecommerce-backend/internal/pricing"
 
 	"github.com/gin-gonic/gin"
-	"github.com/go-playground/validator/v10"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -25,55 +27,57 @@ import (
 
 // ProductHandler handles product-related HTTP requests
 type ProductHandler struct {
-	db        *database.Client
-	validator *validator.Validate
+	db       *database.Client
+	currency string
 }
 
 // NewProductHandler creates a new ProductHandler
-func NewProductHandler(db *database.Client) *ProductHandler {
+func NewProductHandler(db *database.Client, currency string) *ProductHandler {
 	return &ProductHandler{
-		db:        db,
-		validator: validator.New(),
+		db:       db,
+		currency: currency,
 	}
 }
 
-// CreateProduct creates a new product (Admin only)
+// CreateProduct creates a new product (requires products:write)
 func (h *ProductHandler) CreateProduct(c *gin.Context) {
 	// Get user from context (set by auth middleware)
 	userID, exists := c.Get("user_id")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		c.Error(apperrors.Unauthorized("Unauthorized"))
 		return
 	}
 
-	userRole, exists := c.Get("user_role")
-	if !exists || userRole != "admin" {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Admin access required"})
+	var req models.CreateProductRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperrors.Validation(err))
 		return
 	}
 
-	var req models.CreateProductRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload"})
+	// Validate price, defaulting to the store currency
+	price, ok := normalizeMoney(req.Price, h.currency)
+	if !ok || !price.IsPositive() {
+		c.Error(apperrors.BadRequest("Price must be greater than 0 and in " + h.currency))
 		return
 	}
 
-	// Validate request
-	if err := h.validator.Struct(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	prices, ok := normalizePrices(req.Prices, h.currency)
+	if !ok {
+		c.Error(apperrors.BadRequest("Prices must be greater than 0 and in distinct currencies other than " + h.currency))
 		return
 	}
 
-	// Validate category
-	if !models.IsValidCategory(req.Category) {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid category"})
+	// Validate tax class, defaulting to the standard rate
+	taxClass := models.TaxClass(req.TaxClass).OrDefault()
+	if !taxClass.IsValid() {
+		c.Error(apperrors.BadRequest("Invalid tax class"))
 		return
 	}
 
 	// Convert user ID to ObjectID
 	adminID, err := primitive.ObjectIDFromHex(userID.(string))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		c.Error(apperrors.BadRequest("Invalid user ID"))
 		return
 	}
 
@@ -81,12 +85,17 @@ func (h *ProductHandler) CreateProduct(c *gin.Context) {
 	product := models.Product{
 		ID:            primitive.NewObjectID(),
 		Name:          req.Name,
-		Price:         req.Price,
+		Price:         price,
+		Prices:        prices,
 		Category:      models.ProductCategory(req.Category),
 		Description:   req.Description,
 		Specification: req.Specification,
 		Material:      req.Material,
 		InStock:       req.InStock,
+		Stock:         req.Stock,
+		Weight:        req.Weight,
+		Dimensions:    req.Dimensions,
+		TaxClass:      taxClass,
 		CreatedBy:     adminID,
 		CreatedAt:     time.Now(),
 		UpdatedAt:     time.Now(),
@@ -99,9 +108,10 @@ func (h *ProductHandler) CreateProduct(c *gin.Context) {
 
 	_, err = collection.InsertOne(ctx, product)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create product"})
+		c.Error(apperrors.Internal("Failed to create product", err))
 		return
 	}
+	audit.SetResourceID(c, product.ID.Hex())
 
 	c.JSON(http.StatusCreated, gin.H{
 		"message": "Product created successfully",
@@ -141,10 +151,16 @@ func (h *ProductHandler) GetProducts(c *gin.Context) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
+	priceList, err := loadPriceList(ctx, c, h.db, h.currency, "")
+	if err != nil {
+		respondPriceListError(c, err)
+		return
+	}
+
 	// Count total documents
 	total, err := collection.CountDocuments(ctx, filter)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count products"})
+		c.Error(apperrors.Internal("Failed to count products", err))
 		return
 	}
 
@@ -157,14 +173,14 @@ func (h *ProductHandler) GetProducts(c *gin.Context) {
 
 	cursor, err := collection.Find(ctx, filter, findOptions)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch products"})
+		c.Error(apperrors.Internal("Failed to fetch products", err))
 		return
 	}
 	defer cursor.Close(ctx)
 
 	var products []models.Product
 	if err = cursor.All(ctx, &products); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to decode products"})
+		c.Error(apperrors.Internal("Failed to decode products", err))
 		return
 	}
 
@@ -172,7 +188,7 @@ func (h *ProductHandler) GetProducts(c *gin.Context) {
 	baseURL := getBaseURL(c)
 	var productResponses []models.ProductResponse
 	for _, product := range products {
-		productResponses = append(productResponses, product.ToResponseWithBaseURL(baseURL))
+		productResponses = append(productResponses, productResponse(product, baseURL, priceList))
 	}
 
 	response := models.ProductListResponse{
@@ -191,7 +207,7 @@ func (h *ProductHandler) GetProduct(c *gin.Context) {
 
 	objID, err := primitive.ObjectIDFromHex(productID)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID"})
+		c.Error(apperrors.BadRequest("Invalid product ID"))
 		return
 	}
 
@@ -199,45 +215,44 @@ func (h *ProductHandler) GetProduct(c *gin.Context) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
+	priceList, err := loadPriceList(ctx, c, h.db, h.currency, "")
+	if err != nil {
+		respondPriceListError(c, err)
+		return
+	}
+
 	var product models.Product
 	err = collection.FindOne(ctx, bson.M{"_id": objID}).Decode(&product)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+			c.Error(apperrors.NotFound("Product not found"))
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch product"})
+		c.Error(apperrors.Internal("Failed to fetch product", err))
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"product": product.ToResponseWithBaseURL(getBaseURL(c))})
+	c.JSON(http.StatusOK, gin.H{"product": productResponse(product, getBaseURL(c), priceList)})
 }
 
-// UpdateProduct updates an existing product (Admin only)
+// UpdateProduct updates an existing product (requires products:write)
 func (h *ProductHandler) UpdateProduct(c *gin.Context) {
-	// Check admin access
-	userRole, exists := c.Get("user_role")
-	if !exists || userRole != "admin" {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Admin access required"})
-		return
-	}
-
 	productID := c.Param("id")
 	objID, err := primitive.ObjectIDFromHex(productID)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID"})
+		c.Error(apperrors.BadRequest("Invalid product ID"))
 		return
 	}
 
 	var req models.UpdateProductRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload"})
+		c.Error(apperrors.Validation(err))
 		return
 	}
 
-	// Validate request
-	if err := h.validator.Struct(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	// Products on sale are sold from their stock, so putting one on sale needs a stock count
+	if req.InStock != nil && *req.InStock && (req.Stock == nil || *req.Stock == 0) {
+		c.Error(apperrors.BadRequest("Stock is required when in_stock is true"))
 		return
 	}
 
@@ -248,13 +263,22 @@ func (h *ProductHandler) UpdateProduct(c *gin.Context) {
 		update["$set"].(bson.M)["name"] = *req.Name
 	}
 	if req.Price != nil {
-		update["$set"].(bson.M)["price"] = *req.Price
+		price, ok := normalizeMoney(*req.Price, h.currency)
+		if !ok || !price.IsPositive() {
+			c.Error(apperrors.BadRequest("Price must be greater than 0 and in " + h.currency))
+			return
+		}
+		update["$set"].(bson.M)["price"] = price
 	}
-	if req.Category != nil {
-		if !models.IsValidCategory(*req.Category) {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid category"})
+	if req.Prices != nil {
+		prices, ok := normalizePrices(req.Prices, h.currency)
+		if !ok {
+			c.Error(apperrors.BadRequest("Prices must be greater than 0 and in distinct currencies other than " + h.currency))
 			return
 		}
+		update["$set"].(bson.M)["prices"] = prices
+	}
+	if req.Category != nil {
 		update["$set"].(bson.M)["category"] = *req.Category
 	}
 	if req.Description != nil {
@@ -267,7 +291,25 @@ func (h *ProductHandler) UpdateProduct(c *gin.Context) {
 		update["$set"].(bson.M)["material"] = *req.Material
 	}
 	if req.InStock != nil {
+		// An admin's choice is kept even when stock later runs out and comes back
 		update["$set"].(bson.M)["in_stock"] = *req.InStock
+		update["$set"].(bson.M)["sold_out"] = false
+	}
+	if req.Stock != nil {
+		update["$set"].(bson.M)["stock"] = *req.Stock
+	}
+	if req.Weight != nil {
+		update["$set"].(bson.M)["weight"] = *req.Weight
+	}
+	if req.Dimensions != nil {
+		update["$set"].(bson.M)["dimensions"] = req.Dimensions
+	}
+	if req.TaxClass != nil {
+		if !models.TaxClass(*req.TaxClass).IsValid() {
+			c.Error(apperrors.BadRequest("Invalid tax class"))
+			return
+		}
+		update["$set"].(bson.M)["tax_class"] = *req.TaxClass
 	}
 
 	collection := h.db.GetCollection("products")
@@ -276,12 +318,12 @@ func (h *ProductHandler) UpdateProduct(c *gin.Context) {
 
 	result, err := collection.UpdateOne(ctx, bson.M{"_id": objID}, update)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update product"})
+		c.Error(apperrors.Internal("Failed to update product", err))
 		return
 	}
 
 	if result.MatchedCount == 0 {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+		c.Error(apperrors.NotFound("Product not found"))
 		return
 	}
 
@@ -289,7 +331,7 @@ func (h *ProductHandler) UpdateProduct(c *gin.Context) {
 	var updatedProduct models.Product
 	err = collection.FindOne(ctx, bson.M{"_id": objID}).Decode(&updatedProduct)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch updated product"})
+		c.Error(apperrors.Internal("Failed to fetch updated product", err))
 		return
 	}
 
@@ -299,19 +341,12 @@ func (h *ProductHandler) UpdateProduct(c *gin.Context) {
 	})
 }
 
-// DeleteProduct deletes a product (Admin only)
+// DeleteProduct deletes a product (requires products:write)
 func (h *ProductHandler) DeleteProduct(c *gin.Context) {
-	// Check admin access
-	userRole, exists := c.Get("user_role")
-	if !exists || userRole != "admin" {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Admin access required"})
-		return
-	}
-
 	productID := c.Param("id")
 	objID, err := primitive.ObjectIDFromHex(productID)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID"})
+		c.Error(apperrors.BadRequest("Invalid product ID"))
 		return
 	}
 
@@ -324,22 +359,22 @@ func (h *ProductHandler) DeleteProduct(c *gin.Context) {
 	err = collection.FindOne(ctx, bson.M{"_id": objID}).Decode(&product)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+			c.Error(apperrors.NotFound("Product not found"))
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch product"})
+		c.Error(apperrors.Internal("Failed to fetch product", err))
 		return
 	}
 
 	// Delete product from database
 	result, err := collection.DeleteOne(ctx, bson.M{"_id": objID})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete product"})
+		c.Error(apperrors.Internal("Failed to delete product", err))
 		return
 	}
 
 	if result.DeletedCount == 0 {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+		c.Error(apperrors.NotFound("Product not found"))
 		return
 	}
 
@@ -354,56 +389,52 @@ func (h *ProductHandler) DeleteProduct(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Product deleted successfully"})
 }
 
-// UploadProductImage handles product image upload (Admin only)
+// UploadProductImage handles product image upload (requires products:write)
 func (h *ProductHandler) UploadProductImage(c *gin.Context) {
-	// Check admin access
-	userRole, exists := c.Get("user_role")
-	if !exists || userRole != "admin" {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Admin access required"})
-		return
-	}
-
 	productID := c.Param("id")
 	objID, err := primitive.ObjectIDFromHex(productID)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID"})
+		c.Error(apperrors.BadRequest("Invalid product ID"))
 		return
 	}
 
 	// Parse multipart form
 	file, header, err := c.Request.FormFile("image")
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "No image file provided"})
+		c.Error(apperrors.BadRequest("No image file provided"))
 		return
 	}
 	defer file.Close()
 
 	// Validate file type
 	if !isValidImageType(header.Filename) {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid image format. Only JPG, JPEG, PNG, and GIF are allowed"})
+		c.Error(apperrors.BadRequest("Invalid image format. Only JPG, JPEG, PNG, and GIF are allowed"))
 		return
 	}
 
 	// Validate file size (max 5MB)
 	if header.Size > 5*1024*1024 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Image size too large. Maximum 5MB allowed"})
+		c.Error(apperrors.BadRequest("Image size too large. Maximum 5MB allowed"))
 		return
 	}
 
 	// Create uploads directory if it doesn't exist
 	uploadDir := "uploads/products"
 	if err := os.MkdirAll(uploadDir, 0755); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create upload directory"})
+		c.Error(apperrors.Internal("Failed to create upload directory", err))
 		return
 	}
 
-	// Generate unique filename
-	filename := fmt.Sprintf("%s_%d%s", productID, time.Now().Unix(), filepath.Ext(header.Filename))
+	filename, err := uploadFilename(productID, header.Filename)
+	if err != nil {
+		c.Error(apperrors.Internal("Failed to name image", err))
+		return
+	}
 	filePath := filepath.Join(uploadDir, filename)
 
 	// Save file
 	if err := saveUploadedFile(file, filePath); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save image"})
+		c.Error(apperrors.Internal("Failed to save image", err))
 		return
 	}
 
@@ -424,14 +455,14 @@ func (h *ProductHandler) UploadProductImage(c *gin.Context) {
 	if err != nil {
 		// Clean up uploaded file if database update fails
 		os.Remove(filePath)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update product with image", "details": err.Error()})
+		c.Error(apperrors.Internal("Failed to update product with image", err))
 		return
 	}
 
 	if result.MatchedCount == 0 {
 		// Clean up uploaded file if product not found
 		os.Remove(filePath)
-		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+		c.Error(apperrors.NotFound("Product not found"))
 		return
 	}
 
@@ -470,9 +501,7 @@ func (h *ProductHandler) UploadProductImage(c *gin.Context) {
 
 	// Verify image_url was actually set in the database
 	if updatedProduct.ImageURL == "" {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Image uploaded but database update verification failed",
-		})
+		c.Error(apperrors.Internal("Image uploaded but database update verification failed", nil))
 		return
 	}
 
@@ -510,6 +539,31 @@ func (h *ProductHandler) GetCategories(c *gin.Context) {
 
 // Helper functions
 
+// productResponse converts a product for the client, priced in the price list currency
+func productResponse(product models.Product, baseURL string, priceList pricing.PriceList) models.ProductResponse {
+	response := product.ToResponseWithBaseURL(baseURL)
+	if price, ok := priceList.Price(product); ok {
+		response.Price = price
+	}
+	return response
+}
+
+// normalizePrices validates explicit per-currency prices. Each must be positive, in a
+// supported currency other than the store currency and appear only once.
+func normalizePrices(prices []models.Money, currency string) ([]models.Money, bool) {
+	normalized := make([]models.Money, 0, len(prices))
+	seen := make(map[string]bool, len(prices))
+	for _, price := range prices {
+		price.Currency = strings.ToUpper(price.Currency)
+		if !models.IsValidCurrency(price.Currency) || price.Currency == currency || seen[price.Currency] || !price.IsPositive() {
+			return nil, false
+		}
+		seen[price.Currency] = true
+		normalized = append(normalized, price)
+	}
+	return normalized, true
+}
+
 // getBaseURL extracts the base URL from the request context
 func getBaseURL(c *gin.Context) string {
 	scheme := "http"
@@ -0,0 +1,358 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"ecommerce-backend/internal/config"
+	"ecommerce-backend/internal/database"
+	apperrors "ecommerce-backend/internal/errors"
+	"ecommerce-backend/internal/mail"
+	"ecommerce-backend/internal/models"
+	"ecommerce-backend/internal/sessions"
+	"ecommerce-backend/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// emailChangeTTL is how long an email change confirmation link stays valid
+const emailChangeTTL = 24 * time.Hour
+
+// errInvalidUserToken is returned when an emailed token is unknown, used or expired
+var errInvalidUserToken = errors.New("Invalid or expired token")
+
+// ProfileHandler handles changes to the current user's profile and credentials
+type ProfileHandler struct {
+	db       *database.Client
+	sessions *sessions.Store
+	mailer   mail.Sender
+	mail     config.MailConfig
+	auth     config.AuthConfig
+}
+
+// NewProfileHandler creates a new ProfileHandler
+func NewProfileHandler(db *database.Client, sessionStore *sessions.Store, mailer mail.Sender, mailConfig config.MailConfig, authConfig config.AuthConfig) *ProfileHandler {
+	return &ProfileHandler{
+		db:       db,
+		sessions: sessionStore,
+		mailer:   mailer,
+		mail:     mailConfig,
+		auth:     authConfig,
+	}
+}
+
+// UpdateProfile changes the current user's first and last name
+func (h *ProfileHandler) UpdateProfile(c *gin.Context) {
+	userObjID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperrors.Validation(err))
+		return
+	}
+
+	update := bson.M{"updated_at": time.Now()}
+	if req.FirstName != nil {
+		update["first_name"] = strings.TrimSpace(*req.FirstName)
+	}
+	if req.LastName != nil {
+		update["last_name"] = strings.TrimSpace(*req.LastName)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var user models.User
+	after := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	err := h.db.GetCollection("users").FindOneAndUpdate(ctx, bson.M{"_id": userObjID}, bson.M{"$set": update}, after).Decode(&user)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.Error(apperrors.NotFound("User not found"))
+			return
+		}
+		c.Error(apperrors.Internal("Failed to update profile", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Profile updated successfully",
+		"user":    user.ToResponse(),
+	})
+}
+
+// ChangePassword sets a new password after checking the current one, and signs out
+// every other session
+func (h *ProfileHandler) ChangePassword(c *gin.Context) {
+	userObjID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperrors.Validation(err))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	user, ok := h.checkPassword(ctx, c, userObjID, req.CurrentPassword)
+	if !ok {
+		return
+	}
+
+	hashedPassword, err := utils.HashPassword(req.NewPassword)
+	if err != nil {
+		c.Error(apperrors.Internal("Failed to hash password", err))
+		return
+	}
+
+	update := bson.M{"$set": bson.M{"password": hashedPassword, "updated_at": time.Now()}}
+	if _, err := h.db.GetCollection("users").UpdateOne(ctx, bson.M{"_id": user.ID}, update); err != nil {
+		c.Error(apperrors.Internal("Failed to update password", err))
+		return
+	}
+
+	if err := h.sessions.RevokeAll(ctx, user.ID, c.GetString("session_id")); err != nil {
+		c.Error(apperrors.Internal("Failed to revoke sessions", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password changed successfully"})
+}
+
+// RequestEmailChange emails a confirmation link to the new address. The account email
+// only changes once the link is confirmed.
+func (h *ProfileHandler) RequestEmailChange(c *gin.Context) {
+	userObjID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.ChangeEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperrors.Validation(err))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	user, ok := h.checkPassword(ctx, c, userObjID, req.Password)
+	if !ok {
+		return
+	}
+
+	if req.NewEmail == user.Email {
+		c.Error(apperrors.BadRequest("New email must be different from the current email"))
+		return
+	}
+
+	taken, err := h.db.GetCollection("users").CountDocuments(ctx, bson.M{"email": req.NewEmail})
+	if err != nil {
+		c.Error(apperrors.Internal("Database error", err))
+		return
+	}
+	if taken > 0 {
+		c.Error(apperrors.Conflict("User already exists"))
+		return
+	}
+
+	token, err := issueUserToken(ctx, h.db, user.ID, models.TokenPurposeEmailChange, req.NewEmail, emailChangeTTL)
+	if err != nil {
+		c.Error(apperrors.Internal("Failed to create confirmation token", err))
+		return
+	}
+
+	err = h.mailer.Send(ctx, mail.Message{
+		To:      req.NewEmail,
+		Subject: "Confirm your new email address",
+		Body: fmt.Sprintf("Hi %s,\n\nConfirm your new email address by opening this link within 24 hours:\n\n%s\n\nIf you did not ask for this change, you can ignore this email.\n",
+			user.FirstName, appLink(h.mail.AppURL, "/confirm-email", token)),
+	})
+	if err != nil {
+		c.Error(apperrors.New(http.StatusBadGateway, "Failed to send confirmation email", err))
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "Confirmation email sent to the new address"})
+}
+
+// ConfirmEmailChange consumes an email change token and updates the account email
+func (h *ProfileHandler) ConfirmEmailChange(c *gin.Context) {
+	var req models.ConfirmEmailChangeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperrors.Validation(err))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	token, err := consumeUserToken(ctx, h.db, models.TokenPurposeEmailChange, req.Token)
+	if err != nil {
+		if err == errInvalidUserToken {
+			c.Error(apperrors.BadRequest(err.Error()))
+			return
+		}
+		c.Error(apperrors.Internal("Failed to check token", err))
+		return
+	}
+
+	collection := h.db.GetCollection("users")
+
+	// The address may have been registered since the change was requested
+	taken, err := collection.CountDocuments(ctx, bson.M{"email": token.Email, "_id": bson.M{"$ne": token.UserID}})
+	if err != nil {
+		c.Error(apperrors.Internal("Database error", err))
+		return
+	}
+	if taken > 0 {
+		c.Error(apperrors.Conflict("User already exists"))
+		return
+	}
+
+	var previous models.User
+	// Opening the link proved the new address belongs to the user
+	now := time.Now()
+	update := bson.M{"$set": bson.M{"email": token.Email, "email_verified": true, "email_verified_at": now, "updated_at": now}}
+	err = collection.FindOneAndUpdate(ctx, bson.M{"_id": token.UserID}, update).Decode(&previous)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.Error(apperrors.NotFound("User not found"))
+			return
+		}
+		c.Error(apperrors.Internal("Failed to update email", err))
+		return
+	}
+
+	// Let the old address know, in case the change was not made by its owner
+	_ = h.mailer.Send(ctx, mail.Message{
+		To:      previous.Email,
+		Subject: "Your email address was changed",
+		Body:    fmt.Sprintf("Hi %s,\n\nThe email address of your account was changed to %s.\n\nIf you did not make this change, contact support immediately.\n", previous.FirstName, token.Email),
+	})
+
+	c.JSON(http.StatusOK, gin.H{"message": "Email changed successfully"})
+}
+
+// checkPassword loads the user and verifies their current password. It writes the error
+// response and returns false on failure.
+func (h *ProfileHandler) checkPassword(ctx context.Context, c *gin.Context, userID primitive.ObjectID, password string) (models.User, bool) {
+	user, ok := h.findUser(ctx, c, userID)
+	if !ok {
+		return user, false
+	}
+
+	if !utils.CheckPasswordHash(password, user.Password) {
+		c.Error(apperrors.Unauthorized("Current password is incorrect"))
+		return user, false
+	}
+	return user, true
+}
+
+// findUser loads the user. It writes the error response and returns false on failure.
+func (h *ProfileHandler) findUser(ctx context.Context, c *gin.Context, userID primitive.ObjectID) (models.User, bool) {
+	var user models.User
+	if err := h.db.GetCollection("users").FindOne(ctx, bson.M{"_id": userID}).Decode(&user); err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.Error(apperrors.NotFound("User not found"))
+			return user, false
+		}
+		c.Error(apperrors.Internal("Database error", err))
+		return user, false
+	}
+	return user, true
+}
+
+// Helper functions
+
+// issueUserToken creates a single-use token for the purpose, replacing any unused token
+// the user already has for it. Only the token's hash is stored.
+func issueUserToken(ctx context.Context, db *database.Client, userID primitive.ObjectID, purpose, email string, ttl time.Duration) (string, error) {
+	token, err := utils.GenerateRandomToken()
+	if err != nil {
+		return "", err
+	}
+
+	collection := db.GetCollection("user_tokens")
+	now := time.Now()
+
+	// Older links stop working once a new one is sent
+	_, err = collection.UpdateMany(ctx,
+		bson.M{"user_id": userID, "purpose": purpose, "used_at": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"used_at": now}},
+	)
+	if err != nil {
+		return "", err
+	}
+
+	_, err = collection.InsertOne(ctx, models.UserToken{
+		ID:        primitive.NewObjectID(),
+		UserID:    userID,
+		Purpose:   purpose,
+		TokenHash: utils.HashToken(token),
+		Email:     email,
+		ExpiresAt: now.Add(ttl),
+		CreatedAt: now,
+	})
+	if err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// consumeUserToken atomically marks an unused, unexpired token as used and returns it
+func consumeUserToken(ctx context.Context, db *database.Client, purpose, token string) (models.UserToken, error) {
+	now := time.Now()
+	filter := bson.M{
+		"token_hash": utils.HashToken(token),
+		"purpose":    purpose,
+		"used_at":    bson.M{"$exists": false},
+		"expires_at": bson.M{"$gt": now},
+	}
+
+	var consumed models.UserToken
+	err := db.GetCollection("user_tokens").FindOneAndUpdate(ctx, filter, bson.M{"$set": bson.M{"used_at": now}}).Decode(&consumed)
+	if err == mongo.ErrNoDocuments {
+		return consumed, errInvalidUserToken
+	}
+	return consumed, err
+}
+
+// userTokenCooldown returns how long the user must wait before another token for the
+// purpose may be sent, based on when the last one was issued
+func userTokenCooldown(ctx context.Context, db *database.Client, userID primitive.ObjectID, purpose string, interval time.Duration) (time.Duration, error) {
+	var last models.UserToken
+	latest := options.FindOne().SetSort(bson.D{{Key: "created_at", Value: -1}})
+	err := db.GetCollection("user_tokens").FindOne(ctx, bson.M{"user_id": userID, "purpose": purpose}, latest).Decode(&last)
+	if err == mongo.ErrNoDocuments {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	if wait := time.Until(last.CreatedAt.Add(interval)); wait > 0 {
+		return wait, nil
+	}
+	return 0, nil
+}
+
+// appLink builds a storefront link carrying a token
+func appLink(appURL, path, token string) string {
+	return strings.TrimRight(appURL, "/") + path + "?token=" + url.QueryEscape(token)
+}
@@ -0,0 +1,588 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"ecommerce-backend/internal/audit"
+	"ecommerce-backend/internal/database"
+	apperrors "ecommerce-backend/internal/errors"
+	"ecommerce-backend/internal/models"
+	"ecommerce-backend/internal/promotions"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// errPromotionUnavailable is returned when a promotion applied to a checkout has been used up
+// or switched off in the meantime
+var errPromotionUnavailable = errors.New("A promotion applied to the order is no longer available")
+
+// PromotionHandler handles promotion-related HTTP requests
+type PromotionHandler struct {
+	db       *database.Client
+	currency string
+}
+
+// NewPromotionHandler creates a new PromotionHandler
+func NewPromotionHandler(db *database.Client, currency string) *PromotionHandler {
+	return &PromotionHandler{
+		db:       db,
+		currency: currency,
+	}
+}
+
+// CreatePromotion creates a new promotion or discount code (requires promotions:write)
+func (h *PromotionHandler) CreatePromotion(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperrors.Unauthorized("Unauthorized"))
+		return
+	}
+
+	var req models.CreatePromotionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperrors.Validation(err))
+		return
+	}
+
+	adminID, err := primitive.ObjectIDFromHex(userID.(string))
+	if err != nil {
+		c.Error(apperrors.BadRequest("Invalid user ID"))
+		return
+	}
+
+	categories, productIDs, err := parsePromotionScope(req.Categories, req.ProductIDs)
+	if err != nil {
+		c.Error(apperrors.BadRequest(err.Error()))
+		return
+	}
+
+	promotion := models.Promotion{
+		ID:           primitive.NewObjectID(),
+		Name:         req.Name,
+		Description:  req.Description,
+		Code:         promotions.NormalizeCode(req.Code),
+		Type:         models.PromotionType(req.Type),
+		Value:        req.Value,
+		Amount:       req.Amount,
+		BuyQuantity:  req.BuyQuantity,
+		GetQuantity:  req.GetQuantity,
+		MinCartValue: req.MinCartValue,
+		Categories:   categories,
+		ProductIDs:   productIDs,
+		UsageLimit:   req.UsageLimit,
+		PerUserLimit: req.PerUserLimit,
+		StartsAt:     req.StartsAt,
+		EndsAt:       req.EndsAt,
+		Stackable:    req.Stackable,
+		Priority:     req.Priority,
+		IsActive:     req.IsActive,
+		CreatedBy:    adminID,
+		CreatedAt:    time.Now(),
+		UpdatedAt:    time.Now(),
+	}
+
+	if err := validatePromotion(&promotion, h.currency); err != nil {
+		c.Error(apperrors.BadRequest(err.Error()))
+		return
+	}
+
+	collection := h.db.GetCollection("promotions")
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if promotion.Code != "" {
+		count, err := collection.CountDocuments(ctx, bson.M{"code": promotion.Code})
+		if err != nil {
+			c.Error(apperrors.Internal("Failed to check promotion code", err))
+			return
+		}
+		if count > 0 {
+			c.Error(apperrors.Conflict("Promotion code already exists"))
+			return
+		}
+	}
+
+	if _, err := collection.InsertOne(ctx, promotion); err != nil {
+		c.Error(apperrors.Internal("Failed to create promotion", err))
+		return
+	}
+	audit.SetResourceID(c, promotion.ID.Hex())
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":   "Promotion created successfully",
+		"promotion": promotion,
+	})
+}
+
+// GetPromotions lists promotions with pagination (requires promotions:write)
+func (h *PromotionHandler) GetPromotions(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+
+	filter := bson.M{}
+	if active := c.Query("active"); active == "true" {
+		filter["is_active"] = true
+	} else if active == "false" {
+		filter["is_active"] = false
+	}
+	if code := c.Query("code"); code != "" {
+		filter["code"] = promotions.NormalizeCode(code)
+	}
+
+	collection := h.db.GetCollection("promotions")
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	total, err := collection.CountDocuments(ctx, filter)
+	if err != nil {
+		c.Error(apperrors.Internal("Failed to count promotions", err))
+		return
+	}
+
+	findOptions := options.Find().
+		SetSkip(int64((page - 1) * limit)).
+		SetLimit(int64(limit)).
+		SetSort(bson.D{{Key: "created_at", Value: -1}})
+
+	cursor, err := collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		c.Error(apperrors.Internal("Failed to fetch promotions", err))
+		return
+	}
+	defer cursor.Close(ctx)
+
+	promotionList := []models.Promotion{}
+	if err = cursor.All(ctx, &promotionList); err != nil {
+		c.Error(apperrors.Internal("Failed to decode promotions", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.PromotionListResponse{
+		Promotions: promotionList,
+		Total:      total,
+		Page:       page,
+		Limit:      limit,
+	})
+}
+
+// GetPromotion retrieves a single promotion by ID (requires promotions:write)
+func (h *PromotionHandler) GetPromotion(c *gin.Context) {
+	objID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.Error(apperrors.BadRequest("Invalid promotion ID"))
+		return
+	}
+
+	collection := h.db.GetCollection("promotions")
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var promotion models.Promotion
+	err = collection.FindOne(ctx, bson.M{"_id": objID}).Decode(&promotion)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.Error(apperrors.NotFound("Promotion not found"))
+			return
+		}
+		c.Error(apperrors.Internal("Failed to fetch promotion", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"promotion": promotion})
+}
+
+// UpdatePromotion updates an existing promotion (requires promotions:write).
+// The code and type are fixed once created; create a new promotion to change them.
+func (h *PromotionHandler) UpdatePromotion(c *gin.Context) {
+	objID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.Error(apperrors.BadRequest("Invalid promotion ID"))
+		return
+	}
+
+	var req models.UpdatePromotionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperrors.Validation(err))
+		return
+	}
+
+	collection := h.db.GetCollection("promotions")
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var promotion models.Promotion
+	err = collection.FindOne(ctx, bson.M{"_id": objID}).Decode(&promotion)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.Error(apperrors.NotFound("Promotion not found"))
+			return
+		}
+		c.Error(apperrors.Internal("Failed to fetch promotion", err))
+		return
+	}
+
+	if req.Name != nil {
+		promotion.Name = *req.Name
+	}
+	if req.Description != nil {
+		promotion.Description = *req.Description
+	}
+	if req.Value != nil {
+		promotion.Value = *req.Value
+	}
+	if req.BuyQuantity != nil {
+		promotion.BuyQuantity = *req.BuyQuantity
+	}
+	if req.GetQuantity != nil {
+		promotion.GetQuantity = *req.GetQuantity
+	}
+	if req.Amount != nil {
+		promotion.Amount = *req.Amount
+	}
+	if req.MinCartValue != nil {
+		promotion.MinCartValue = *req.MinCartValue
+	}
+	if req.Categories != nil || req.ProductIDs != nil {
+		categories, productIDs, err := parsePromotionScope(req.Categories, req.ProductIDs)
+		if err != nil {
+			c.Error(apperrors.BadRequest(err.Error()))
+			return
+		}
+		promotion.Categories = categories
+		promotion.ProductIDs = productIDs
+	}
+	if req.UsageLimit != nil {
+		promotion.UsageLimit = *req.UsageLimit
+	}
+	if req.PerUserLimit != nil {
+		promotion.PerUserLimit = *req.PerUserLimit
+	}
+	if req.StartsAt != nil {
+		promotion.StartsAt = req.StartsAt
+	}
+	if req.EndsAt != nil {
+		promotion.EndsAt = req.EndsAt
+	}
+	if req.Stackable != nil {
+		promotion.Stackable = *req.Stackable
+	}
+	if req.Priority != nil {
+		promotion.Priority = *req.Priority
+	}
+	if req.IsActive != nil {
+		promotion.IsActive = *req.IsActive
+	}
+	promotion.UpdatedAt = time.Now()
+
+	if err := validatePromotion(&promotion, h.currency); err != nil {
+		c.Error(apperrors.BadRequest(err.Error()))
+		return
+	}
+
+	// usage_count is excluded so concurrent redemptions are not overwritten
+	update := bson.M{"$set": bson.M{
+		"name":           promotion.Name,
+		"description":    promotion.Description,
+		"value":          promotion.Value,
+		"amount":         promotion.Amount,
+		"buy_quantity":   promotion.BuyQuantity,
+		"get_quantity":   promotion.GetQuantity,
+		"min_cart_value": promotion.MinCartValue,
+		"categories":     promotion.Categories,
+		"product_ids":    promotion.ProductIDs,
+		"usage_limit":    promotion.UsageLimit,
+		"per_user_limit": promotion.PerUserLimit,
+		"starts_at":      promotion.StartsAt,
+		"ends_at":        promotion.EndsAt,
+		"stackable":      promotion.Stackable,
+		"priority":       promotion.Priority,
+		"is_active":      promotion.IsActive,
+		"updated_at":     promotion.UpdatedAt,
+	}}
+
+	result, err := collection.UpdateOne(ctx, bson.M{"_id": objID}, update)
+	if err != nil {
+		c.Error(apperrors.Internal("Failed to update promotion", err))
+		return
+	}
+
+	if result.MatchedCount == 0 {
+		c.Error(apperrors.NotFound("Promotion not found"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":   "Promotion updated successfully",
+		"promotion": promotion,
+	})
+}
+
+// DeletePromotion deletes a promotion (requires promotions:write)
+func (h *PromotionHandler) DeletePromotion(c *gin.Context) {
+	objID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.Error(apperrors.BadRequest("Invalid promotion ID"))
+		return
+	}
+
+	collection := h.db.GetCollection("promotions")
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	result, err := collection.DeleteOne(ctx, bson.M{"_id": objID})
+	if err != nil {
+		c.Error(apperrors.Internal("Failed to delete promotion", err))
+		return
+	}
+
+	if result.DeletedCount == 0 {
+		c.Error(apperrors.NotFound("Promotion not found"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Promotion deleted successfully"})
+}
+
+// Helper functions
+
+// parsePromotionScope converts category names and product IDs from a request
+func parsePromotionScope(categoryNames, productIDs []string) ([]models.ProductCategory, []primitive.ObjectID, error) {
+	var categories []models.ProductCategory
+	for _, name := range categoryNames {
+		if !models.IsValidCategory(name) {
+			return nil, nil, errors.New("Invalid category: " + name)
+		}
+		categories = append(categories, models.ProductCategory(name))
+	}
+
+	var ids []primitive.ObjectID
+	for _, id := range productIDs {
+		objID, err := primitive.ObjectIDFromHex(id)
+		if err != nil {
+			return nil, nil, errors.New("Invalid product ID: " + id)
+		}
+		ids = append(ids, objID)
+	}
+
+	return categories, ids, nil
+}
+
+// validatePromotion checks the rules that depend on the promotion type and puts
+// its amounts in the store currency
+func validatePromotion(p *models.Promotion, currency string) error {
+	var amountOK, minOK bool
+	p.Amount, amountOK = normalizeMoney(p.Amount, currency)
+	p.MinCartValue, minOK = normalizeMoney(p.MinCartValue, currency)
+	if !amountOK || !minOK {
+		return errors.New("Amounts must be non-negative and in " + currency)
+	}
+
+	switch p.Type {
+	case models.PromotionPercentage:
+		if p.Value <= 0 || p.Value > 100 {
+			return errors.New("Percentage value must be between 0 and 100")
+		}
+	case models.PromotionFixedAmount:
+		if !p.Amount.IsPositive() {
+			return errors.New("Fixed amount must be greater than 0")
+		}
+	case models.PromotionBuyXGetY:
+		if p.BuyQuantity < 1 || p.GetQuantity < 1 {
+			return errors.New("Buy and get quantities must be at least 1")
+		}
+		if p.Value < 0 || p.Value > 100 {
+			return errors.New("Percentage value must be between 0 and 100")
+		}
+	case models.PromotionFreeShipping:
+	default:
+		return errors.New("Invalid promotion type")
+	}
+
+	if p.StartsAt != nil && p.EndsAt != nil && !p.EndsAt.After(*p.StartsAt) {
+		return errors.New("Promotion must end after it starts")
+	}
+
+	return nil
+}
+
+// loadActivePromotions fetches active promotions that are automatic or match one of the codes
+func loadActivePromotions(ctx context.Context, db *database.Client, codes []string) ([]models.Promotion, error) {
+	normalized := []string{}
+	for _, code := range codes {
+		if code = promotions.NormalizeCode(code); code != "" {
+			normalized = append(normalized, code)
+		}
+	}
+
+	filter := bson.M{
+		"is_active": true,
+		"$or": bson.A{
+			bson.M{"code": bson.M{"$exists": false}},
+			bson.M{"code": ""},
+			bson.M{"code": bson.M{"$in": normalized}},
+		},
+	}
+
+	cursor, err := db.GetCollection("promotions").Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var active []models.Promotion
+	if err := cursor.All(ctx, &active); err != nil {
+		return nil, err
+	}
+	return active, nil
+}
+
+// loadPromotionUsage counts how many times the user redeemed each of the given promotions
+func loadPromotionUsage(ctx context.Context, db *database.Client, userID primitive.ObjectID, candidates []models.Promotion) (map[primitive.ObjectID]int, error) {
+	usage := make(map[primitive.ObjectID]int)
+
+	var ids []primitive.ObjectID
+	for _, p := range candidates {
+		if p.PerUserLimit > 0 {
+			ids = append(ids, p.ID)
+		}
+	}
+	if len(ids) == 0 || userID.IsZero() {
+		return usage, nil
+	}
+
+	filter := bson.M{"_id.user_id": userID, "_id.promotion_id": bson.M{"$in": ids}}
+	cursor, err := db.GetCollection("promotion_usage").Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var counts []models.PromotionUsage
+	if err := cursor.All(ctx, &counts); err != nil {
+		return nil, err
+	}
+	for _, row := range counts {
+		usage[row.ID.PromotionID] = row.Count
+	}
+	return usage, nil
+}
+
+// claimPromotions takes one use of each promotion applied to the order, both against the
+// promotion's usage limit and against the user's own limit. Each use is only taken while
+// the limit has room, so concurrent checkouts cannot exceed it; if any promotion has run
+// out, the uses already taken are given back and errPromotionUnavailable is returned.
+func claimPromotions(ctx context.Context, db *database.Client, order models.Order) error {
+	for i, applied := range order.Promotions {
+		if err := claimPromotion(ctx, db, order.UserID, applied.PromotionID); err != nil {
+			releasePromotions(ctx, db, order.UserID, order.Promotions[:i])
+			return err
+		}
+	}
+	return nil
+}
+
+// claimPromotion takes one use of a promotion for the user
+func claimPromotion(ctx context.Context, db *database.Client, userID, promotionID primitive.ObjectID) error {
+	promotionCollection := db.GetCollection("promotions")
+	filter := bson.M{
+		"_id":       promotionID,
+		"is_active": true,
+		"$or": bson.A{
+			bson.M{"usage_limit": bson.M{"$lte": 0}},
+			bson.M{"$expr": bson.M{"$lt": bson.A{"$usage_count", "$usage_limit"}}},
+		},
+	}
+
+	var promotion models.Promotion
+	err := promotionCollection.FindOneAndUpdate(ctx, filter, bson.M{"$inc": bson.M{"usage_count": 1}}).Decode(&promotion)
+	if err == mongo.ErrNoDocuments {
+		return errPromotionUnavailable
+	}
+	if err != nil {
+		return err
+	}
+
+	usage := db.GetCollection("promotion_usage")
+	key := models.PromotionUsageKey{PromotionID: promotionID, UserID: userID}
+	if promotion.PerUserLimit <= 0 {
+		_, err = usage.UpdateOne(ctx, bson.M{"_id": key}, bson.M{"$inc": bson.M{"count": 1}}, options.Update().SetUpsert(true))
+	} else {
+		// The counter is created first so that the limited increment never has to upsert
+		_, err = usage.UpdateOne(ctx, bson.M{"_id": key}, bson.M{"$setOnInsert": bson.M{"count": 0}}, options.Update().SetUpsert(true))
+		if err == nil {
+			var result *mongo.UpdateResult
+			result, err = usage.UpdateOne(ctx,
+				bson.M{"_id": key, "count": bson.M{"$lt": promotion.PerUserLimit}},
+				bson.M{"$inc": bson.M{"count": 1}},
+			)
+			if err == nil && result.MatchedCount == 0 {
+				err = errPromotionUnavailable
+			}
+		}
+	}
+	if err != nil {
+		if _, undoErr := promotionCollection.UpdateOne(ctx, bson.M{"_id": promotionID}, bson.M{"$inc": bson.M{"usage_count": -1}}); undoErr != nil {
+			slog.Error("Failed to release promotion use", "promotion_id", promotionID.Hex(), "error", undoErr)
+		}
+		return err
+	}
+	return nil
+}
+
+// releasePromotions gives back the uses taken by claimPromotions when a checkout does not
+// go through
+func releasePromotions(ctx context.Context, db *database.Client, userID primitive.ObjectID, applied []models.AppliedPromotion) {
+	for _, promotion := range applied {
+		_, err := db.GetCollection("promotions").UpdateOne(ctx,
+			bson.M{"_id": promotion.PromotionID, "usage_count": bson.M{"$gt": 0}},
+			bson.M{"$inc": bson.M{"usage_count": -1}},
+		)
+		if err == nil {
+			key := models.PromotionUsageKey{PromotionID: promotion.PromotionID, UserID: userID}
+			_, err = db.GetCollection("promotion_usage").UpdateOne(ctx,
+				bson.M{"_id": key, "count": bson.M{"$gt": 0}},
+				bson.M{"$inc": bson.M{"count": -1}},
+			)
+		}
+		if err != nil {
+			slog.Error("Failed to release promotion use", "promotion_id", promotion.PromotionID.Hex(), "error", err)
+		}
+	}
+}
+
+// recordPromotionRedemptions stores a redemption per promotion applied to a placed order.
+// The uses themselves are counted by claimPromotions before the order is placed.
+func recordPromotionRedemptions(ctx context.Context, db *database.Client, order models.Order) error {
+	if len(order.Promotions) == 0 {
+		return nil
+	}
+
+	redemptions := make([]interface{}, len(order.Promotions))
+	for i, applied := range order.Promotions {
+		redemptions[i] = models.PromotionRedemption{
+			ID:          primitive.NewObjectID(),
+			PromotionID: applied.PromotionID,
+			UserID:      order.UserID,
+			OrderID:     order.ID,
+			Discount:    applied.Discount,
+			CreatedAt:   order.CreatedAt,
+		}
+	}
+	_, err := db.GetCollection("promotion_redemptions").InsertMany(ctx, redemptions)
+	return err
+}
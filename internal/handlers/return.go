@@ -0,0 +1,685 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"ecommerce-backend/internal/database"
+	apperrors "ecommerce-backend/internal/errors"
+	"ecommerce-backend/internal/models"
+	"ecommerce-backend/internal/payments"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ReturnHandler handles return (RMA) requests from customers and admins
+type ReturnHandler struct {
+	db       *database.Client
+	payments *payments.Registry
+}
+
+// NewReturnHandler creates a new ReturnHandler
+func NewReturnHandler(db *database.Client, paymentRegistry *payments.Registry) *ReturnHandler {
+	return &ReturnHandler{
+		db:       db,
+		payments: paymentRegistry,
+	}
+}
+
+// CreateReturn requests a return for items of one of the current user's orders
+func (h *ReturnHandler) CreateReturn(c *gin.Context) {
+	userObjID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	orderID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.Error(apperrors.BadRequest("Invalid order ID"))
+		return
+	}
+
+	var req models.CreateReturnRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperrors.Validation(err))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var order models.Order
+	err = h.db.GetCollection("orders").FindOne(ctx, bson.M{"_id": orderID, "user_id": userObjID}).Decode(&order)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.Error(apperrors.NotFound("Order not found"))
+			return
+		}
+		c.Error(apperrors.Internal("Failed to fetch order", err))
+		return
+	}
+
+	if order.PaymentStatus != models.PaymentStatusPaid && order.PaymentStatus != models.PaymentStatusPartiallyRefunded {
+		c.Error(apperrors.Conflict("Only paid orders can be returned"))
+		return
+	}
+
+	returnable := returnableQuantities(order)
+	prices := make(map[primitive.ObjectID]models.OrderItem, len(order.Items))
+	for _, item := range order.Items {
+		prices[item.ProductID] = item
+	}
+
+	var items []models.ReturnItem
+	for _, itemReq := range req.Items {
+		productID, err := primitive.ObjectIDFromHex(itemReq.ProductID)
+		if err != nil {
+			c.Error(apperrors.BadRequest("Invalid product ID"))
+			return
+		}
+		if itemReq.Quantity > returnable[productID] {
+			c.Error(apperrors.BadRequest("Return quantity exceeds returnable quantity for product " + itemReq.ProductID))
+			return
+		}
+		returnable[productID] -= itemReq.Quantity
+
+		orderItem := prices[productID]
+		items = append(items, models.ReturnItem{
+			ProductID: productID,
+			Name:      orderItem.Name,
+			Quantity:  itemReq.Quantity,
+			UnitPrice: orderItem.UnitPrice,
+			Reason:    itemReq.Reason,
+		})
+	}
+
+	claimed, err := h.claimReturnQuantities(ctx, order, items)
+	if err != nil {
+		c.Error(apperrors.Internal("Failed to reserve return quantities", err))
+		return
+	}
+	if !claimed {
+		c.Error(apperrors.Conflict("Items were returned concurrently, please retry"))
+		return
+	}
+
+	now := time.Now()
+	ret := models.Return{
+		ID:             primitive.NewObjectID(),
+		OrderID:        order.ID,
+		UserID:         userObjID,
+		Items:          items,
+		Reason:         req.Reason,
+		Status:         models.ReturnStatusRequested,
+		RefundedAmount: models.NewMoney(0, order.Total.Currency),
+		History: []models.ReturnEvent{
+			newReturnEvent(c, models.ReturnStatusRequested, "requested", req.Reason, nil),
+		},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if _, err := h.db.GetCollection("returns").InsertOne(ctx, ret); err != nil {
+		h.releaseReturnQuantities(ctx, order.ID, items)
+		c.Error(apperrors.Internal("Failed to create return", err))
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Return requested successfully",
+		"return":  ret,
+	})
+}
+
+// GetOrderReturns lists the returns for one of the current user's orders
+func (h *ReturnHandler) GetOrderReturns(c *gin.Context) {
+	userObjID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	orderID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.Error(apperrors.BadRequest("Invalid order ID"))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	returns, err := h.findReturns(ctx, bson.M{"order_id": orderID, "user_id": userObjID})
+	if err != nil {
+		c.Error(apperrors.Internal("Failed to fetch returns", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"returns": returns})
+}
+
+// GetReturns lists all returns with pagination and an optional status filter (requires returns:manage)
+func (h *ReturnHandler) GetReturns(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+
+	filter := bson.M{}
+	if status := c.Query("status"); status != "" {
+		filter["status"] = status
+	}
+
+	collection := h.db.GetCollection("returns")
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	total, err := collection.CountDocuments(ctx, filter)
+	if err != nil {
+		c.Error(apperrors.Internal("Failed to count returns", err))
+		return
+	}
+
+	findOptions := options.Find().
+		SetSkip(int64((page - 1) * limit)).
+		SetLimit(int64(limit)).
+		SetSort(bson.D{{Key: "created_at", Value: -1}})
+
+	cursor, err := collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		c.Error(apperrors.Internal("Failed to fetch returns", err))
+		return
+	}
+	defer cursor.Close(ctx)
+
+	returns := []models.Return{}
+	if err := cursor.All(ctx, &returns); err != nil {
+		c.Error(apperrors.Internal("Failed to decode returns", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ReturnListResponse{
+		Returns: returns,
+		Total:   total,
+		Page:    page,
+		Limit:   limit,
+	})
+}
+
+// GetReturn retrieves a single return by ID (requires returns:manage)
+func (h *ReturnHandler) GetReturn(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	ret, ok := h.loadReturn(ctx, c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"return": ret})
+}
+
+// ApproveReturn approves a requested return (requires returns:manage)
+func (h *ReturnHandler) ApproveReturn(c *gin.Context) {
+	h.decideReturn(c, models.ReturnStatusApproved, "approved")
+}
+
+// RejectReturn rejects a requested return (requires returns:manage)
+func (h *ReturnHandler) RejectReturn(c *gin.Context) {
+	h.decideReturn(c, models.ReturnStatusRejected, "rejected")
+}
+
+// ReceiveReturn marks the returned items as received and restocks them by default. Receiving
+// a received return again retries restocking the items that were not restocked. (requires returns:manage)
+func (h *ReturnHandler) ReceiveReturn(c *gin.Context) {
+	var req models.ReceiveReturnRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperrors.Validation(err))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	ret, ok := h.loadReturn(ctx, c)
+	if !ok {
+		return
+	}
+
+	// A received return is received again only to retry a restock that failed
+	retry := ret.Status == models.ReturnStatusReceived && (req.Restock == nil || *req.Restock)
+	if !retry {
+		if !ret.Status.CanTransitionTo(models.ReturnStatusReceived) {
+			c.Error(apperrors.Conflict("Return cannot be received in status " + string(ret.Status)))
+			return
+		}
+
+		event := newReturnEvent(c, models.ReturnStatusReceived, "received", req.Note, nil)
+		if !h.transitionReturn(ctx, c, &ret, event, nil) {
+			return
+		}
+	}
+
+	if req.Restock == nil || *req.Restock {
+		if err := h.restockItems(ctx, &ret); err != nil {
+			c.Error(apperrors.Internal("Failed to restock items", err))
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Return received successfully",
+		"return":  ret,
+	})
+}
+
+// RefundReturn refunds a return through the order's payment provider (requires returns:manage and orders:refund)
+func (h *ReturnHandler) RefundReturn(c *gin.Context) {
+	var req models.RefundReturnRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperrors.Validation(err))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	ret, ok := h.loadReturn(ctx, c)
+	if !ok {
+		return
+	}
+
+	if !ret.Status.CanTransitionTo(models.ReturnStatusRefunding) {
+		c.Error(apperrors.Conflict("Return cannot be refunded in status " + string(ret.Status)))
+		return
+	}
+
+	// Claim the return before any money moves, so a concurrent refund of the same
+	// return is turned away here instead of paying out a second time
+	previous := ret.Status
+	if !h.transitionReturn(ctx, c, &ret, newReturnEvent(c, models.ReturnStatusRefunding, "refund_started", req.Note, nil), nil) {
+		return
+	}
+
+	refund, amount, appErr := h.issueRefund(ctx, &ret, req)
+	if appErr != nil {
+		h.releaseRefund(ctx, &ret, newReturnEvent(c, previous, "refund_failed", appErr.Message, nil))
+		c.Error(appErr)
+		return
+	}
+
+	event := newReturnEvent(c, models.ReturnStatusRefunded, "refunded", req.Note, &amount)
+	set := bson.M{"refunded_amount": ret.RefundedAmount.Add(amount)}
+	push := bson.M{"refund_ids": refund.ID}
+	if !h.transitionReturn(ctx, c, &ret, event, bson.M{"$set": set, "$push": push}) {
+		return
+	}
+	ret.RefundedAmount = ret.RefundedAmount.Add(amount)
+	ret.RefundIDs = append(ret.RefundIDs, refund.ID)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":   "Refund issued successfully",
+		"refund_id": refund.ID,
+		"amount":    amount,
+		"return":    ret,
+	})
+}
+
+// decideReturn approves or rejects a requested return
+func (h *ReturnHandler) decideReturn(c *gin.Context, next models.ReturnStatus, action string) {
+	var req models.ReturnDecisionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperrors.Validation(err))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	ret, ok := h.loadReturn(ctx, c)
+	if !ok {
+		return
+	}
+
+	if !ret.Status.CanTransitionTo(next) {
+		c.Error(apperrors.Conflict("Return cannot be " + action + " in status " + string(ret.Status)))
+		return
+	}
+
+	if !h.transitionReturn(ctx, c, &ret, newReturnEvent(c, next, action, req.Note, nil), nil) {
+		return
+	}
+	if next == models.ReturnStatusRejected {
+		h.releaseReturnQuantities(ctx, ret.OrderID, ret.Items)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Return " + action + " successfully",
+		"return":  ret,
+	})
+}
+
+// loadReturn fetches the return named by the :id parameter, writing an error response on failure
+func (h *ReturnHandler) loadReturn(ctx context.Context, c *gin.Context) (models.Return, bool) {
+	var ret models.Return
+
+	returnID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.Error(apperrors.BadRequest("Invalid return ID"))
+		return ret, false
+	}
+
+	err = h.db.GetCollection("returns").FindOne(ctx, bson.M{"_id": returnID}).Decode(&ret)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.Error(apperrors.NotFound("Return not found"))
+			return ret, false
+		}
+		c.Error(apperrors.Internal("Failed to fetch return", err))
+		return ret, false
+	}
+
+	return ret, true
+}
+
+// transitionReturn moves a return to the event's status and appends the event to its history.
+// The update only applies if the return is still in the status it was loaded with.
+func (h *ReturnHandler) transitionReturn(ctx context.Context, c *gin.Context, ret *models.Return, event models.ReturnEvent, extra bson.M) bool {
+	set := bson.M{"status": event.Status, "updated_at": event.CreatedAt}
+	push := bson.M{"history": event}
+
+	if extra != nil {
+		if extraSet, ok := extra["$set"].(bson.M); ok {
+			for key, value := range extraSet {
+				set[key] = value
+			}
+		}
+		if extraPush, ok := extra["$push"].(bson.M); ok {
+			for key, value := range extraPush {
+				push[key] = value
+			}
+		}
+	}
+
+	result, err := h.db.GetCollection("returns").UpdateOne(ctx,
+		bson.M{"_id": ret.ID, "status": ret.Status},
+		bson.M{"$set": set, "$push": push},
+	)
+	if err != nil {
+		c.Error(apperrors.Internal("Failed to update return", err))
+		return false
+	}
+	if result.MatchedCount == 0 {
+		c.Error(apperrors.Conflict("Return was modified concurrently"))
+		return false
+	}
+
+	ret.Status = event.Status
+	ret.UpdatedAt = event.CreatedAt
+	ret.History = append(ret.History, event)
+	return true
+}
+
+// issueRefund refunds a claimed return. The amount is reserved on the order before the
+// provider is called, so refunds of different returns of one order cannot together
+// exceed its total, and the reservation is given back if the provider refuses.
+func (h *ReturnHandler) issueRefund(ctx context.Context, ret *models.Return, req models.RefundReturnRequest) (*payments.Refund, models.Money, *apperrors.AppError) {
+	orders := h.db.GetCollection("orders")
+	var order models.Order
+	if err := orders.FindOne(ctx, bson.M{"_id": ret.OrderID}).Decode(&order); err != nil {
+		return nil, models.Money{}, apperrors.Internal("Failed to fetch order", err)
+	}
+
+	refundable := order.Total.Sub(order.AmountRefunded)
+	amount := returnRefundAmount(order, *ret)
+	if req.Amount != nil {
+		amount = *req.Amount
+		if amount.Currency == "" {
+			amount.Currency = order.Total.Currency
+		}
+	}
+	if amount.Currency != order.Total.Currency {
+		return nil, amount, apperrors.BadRequest("Refund currency must match the order currency")
+	}
+	if !amount.IsPositive() || amount.Amount > refundable.Amount {
+		return nil, amount, apperrors.BadRequest("Refund amount must be between 0 and " + refundable.String())
+	}
+
+	provider, err := h.payments.Get(order.PaymentProvider)
+	if err != nil || order.PaymentIntentID == "" {
+		return nil, amount, apperrors.Conflict("Order has no refundable payment")
+	}
+
+	after := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	err = orders.FindOneAndUpdate(ctx,
+		bson.M{"_id": order.ID, "amount_refunded.amount": bson.M{"$lte": order.Total.Amount - amount.Amount}},
+		bson.M{
+			"$inc": bson.M{"amount_refunded.amount": amount.Amount},
+			"$set": bson.M{"amount_refunded.currency": amount.Currency, "updated_at": time.Now()},
+		}, after).Decode(&order)
+	if err == mongo.ErrNoDocuments {
+		return nil, amount, apperrors.Conflict("Refund exceeds what is left to refund on the order")
+	}
+	if err != nil {
+		return nil, amount, apperrors.Internal("Failed to reserve refund on order", err)
+	}
+
+	refund, err := provider.Refund(ctx, order.PaymentIntentID, amount, ret.Reason)
+	if err != nil {
+		_, releaseErr := orders.UpdateOne(ctx, bson.M{"_id": order.ID}, bson.M{
+			"$inc": bson.M{"amount_refunded.amount": -amount.Amount},
+			"$set": bson.M{"updated_at": time.Now()},
+		})
+		if releaseErr != nil {
+			slog.Error("Failed to release refund reservation", "order_id", order.ID.Hex(), "amount", amount.String(), "error", releaseErr)
+		}
+		return nil, amount, apperrors.New(http.StatusBadGateway, "Payment provider refused the refund", err)
+	}
+
+	// The money has moved, so a failure from here on must not release the return
+	_, err = orders.UpdateOne(ctx, bson.M{"_id": order.ID}, bson.M{"$set": bson.M{
+		"payment_status": payments.RefundStatus(order.Total, order.AmountRefunded),
+	}})
+	if err != nil {
+		slog.Error("Failed to update order payment status after refund", "order_id", order.ID.Hex(), "error", err)
+	}
+
+	return refund, amount, nil
+}
+
+// releaseRefund puts a claimed return back in the status it had before a refund that
+// failed, recording why in its history
+func (h *ReturnHandler) releaseRefund(ctx context.Context, ret *models.Return, event models.ReturnEvent) {
+	_, err := h.db.GetCollection("returns").UpdateOne(ctx,
+		bson.M{"_id": ret.ID, "status": models.ReturnStatusRefunding},
+		bson.M{
+			"$set":  bson.M{"status": event.Status, "updated_at": event.CreatedAt},
+			"$push": bson.M{"history": event},
+		},
+	)
+	if err != nil {
+		slog.Error("Failed to release return after refund failure", "return_id", ret.ID.Hex(), "error", err)
+	}
+}
+
+// restockItems puts the returned quantities back into product inventory. Each item is
+// marked restocked before its product is, so concurrent calls restock it once, and the mark
+// is taken back if the product update fails so that receiving the return again retries it.
+func (h *ReturnHandler) restockItems(ctx context.Context, ret *models.Return) error {
+	returns := h.db.GetCollection("returns")
+	for i, item := range ret.Items {
+		if item.Restocked {
+			continue
+		}
+
+		field := fmt.Sprintf("items.%d.restocked", i)
+		result, err := returns.UpdateOne(ctx,
+			bson.M{"_id": ret.ID, field: bson.M{"$ne": true}},
+			bson.M{"$set": bson.M{field: true}},
+		)
+		if err != nil {
+			return err
+		}
+		if result.ModifiedCount == 0 {
+			ret.Items[i].Restocked = true
+			continue
+		}
+
+		if err := restock(ctx, h.db, item.ProductID, item.Quantity); err != nil {
+			if _, releaseErr := returns.UpdateOne(ctx, bson.M{"_id": ret.ID}, bson.M{"$set": bson.M{field: false}}); releaseErr != nil {
+				slog.Error("Failed to release restock mark", "return_id", ret.ID.Hex(), "product_id", item.ProductID.Hex(), "error", releaseErr)
+			}
+			return err
+		}
+		ret.Items[i].Restocked = true
+	}
+	return nil
+}
+
+// claimReturnQuantities adds the items to the returned quantities of the order's lines. Each
+// line is only updated while it has room for the item, so concurrent returns cannot claim
+// more than was ordered. If a line has no room, the lines already claimed are given back and
+// false is returned.
+func (h *ReturnHandler) claimReturnQuantities(ctx context.Context, order models.Order, items []models.ReturnItem) (bool, error) {
+	ordered := make(map[primitive.ObjectID]int, len(order.Items))
+	for _, item := range order.Items {
+		ordered[item.ProductID] = item.Quantity
+	}
+
+	orders := h.db.GetCollection("orders")
+	for i, item := range items {
+		result, err := orders.UpdateOne(ctx,
+			bson.M{"_id": order.ID, "items": bson.M{"$elemMatch": bson.M{
+				"product_id":        item.ProductID,
+				"returned_quantity": bson.M{"$not": bson.M{"$gt": ordered[item.ProductID] - item.Quantity}},
+			}}},
+			bson.M{"$inc": bson.M{"items.$.returned_quantity": item.Quantity}},
+		)
+		if err != nil || result.ModifiedCount == 0 {
+			h.releaseReturnQuantities(ctx, order.ID, items[:i])
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// releaseReturnQuantities gives back the returned quantities claimed for items, when their
+// return was not created or has been rejected
+func (h *ReturnHandler) releaseReturnQuantities(ctx context.Context, orderID primitive.ObjectID, items []models.ReturnItem) {
+	orders := h.db.GetCollection("orders")
+	for _, item := range items {
+		_, err := orders.UpdateOne(ctx,
+			bson.M{"_id": orderID, "items": bson.M{"$elemMatch": bson.M{
+				"product_id":        item.ProductID,
+				"returned_quantity": bson.M{"$gte": item.Quantity},
+			}}},
+			bson.M{"$inc": bson.M{"items.$.returned_quantity": -item.Quantity}},
+		)
+		if err != nil {
+			slog.Error("Failed to release returned quantity", "order_id", orderID.Hex(), "product_id", item.ProductID.Hex(), "error", err)
+		}
+	}
+}
+
+// findReturns lists returns matching filter, newest first
+func (h *ReturnHandler) findReturns(ctx context.Context, filter bson.M) ([]models.Return, error) {
+	findOptions := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+	cursor, err := h.db.GetCollection("returns").Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	returns := []models.Return{}
+	if err := cursor.All(ctx, &returns); err != nil {
+		return nil, err
+	}
+	return returns, nil
+}
+
+// Helper functions
+
+// currentUserID reads the authenticated user's ID, writing an error response if it is missing
+func currentUserID(c *gin.Context) (primitive.ObjectID, bool) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperrors.Unauthorized("Unauthorized"))
+		return primitive.NilObjectID, false
+	}
+
+	objID, err := primitive.ObjectIDFromHex(userID.(string))
+	if err != nil {
+		c.Error(apperrors.BadRequest("Invalid user ID"))
+		return primitive.NilObjectID, false
+	}
+	return objID, true
+}
+
+// newReturnEvent builds a history entry attributed to the current user
+func newReturnEvent(c *gin.Context, status models.ReturnStatus, action, note string, amount *models.Money) models.ReturnEvent {
+	event := models.ReturnEvent{
+		Status:    status,
+		Action:    action,
+		Note:      note,
+		Amount:    amount,
+		CreatedAt: time.Now(),
+	}
+	if userID, exists := c.Get("user_id"); exists {
+		event.ActorID, _ = primitive.ObjectIDFromHex(userID.(string))
+	}
+	if role, exists := c.Get("user_role"); exists {
+		event.ActorRole, _ = role.(string)
+	}
+	return event
+}
+
+// returnableQuantities returns, per product, the ordered quantity not already claimed by
+// returns that were not rejected
+func returnableQuantities(order models.Order) map[primitive.ObjectID]int {
+	returnable := make(map[primitive.ObjectID]int, len(order.Items))
+	for _, item := range order.Items {
+		returnable[item.ProductID] += item.Quantity - item.Returned
+	}
+	return returnable
+}
+
+// returnRefundAmount is the value of the returned items after the order's discounts and
+// including their tax, capped at what is left to refund on the order. Shipping is not refunded.
+func returnRefundAmount(order models.Order, ret models.Return) models.Money {
+	taxLines := make(map[primitive.ObjectID]models.TaxLine)
+	if order.TaxBreakdown != nil {
+		for _, line := range order.TaxBreakdown.Lines {
+			if !line.ProductID.IsZero() && line.Quantity > 0 {
+				taxLines[line.ProductID] = line
+			}
+		}
+	}
+
+	zero := models.NewMoney(0, order.Total.Currency)
+	value := zero
+	for _, item := range ret.Items {
+		if line, ok := taxLines[item.ProductID]; ok {
+			value = value.Add(line.Gross.MulRate(float64(item.Quantity) / float64(line.Quantity)))
+			continue
+		}
+
+		itemValue := item.UnitPrice.Times(item.Quantity)
+		if order.Subtotal.IsPositive() {
+			itemsTotal := order.Total.Sub(order.ShippingCost)
+			itemValue = itemValue.MulRate(float64(itemsTotal.Amount) / float64(order.Subtotal.Amount))
+		}
+		value = value.Add(itemValue)
+	}
+	value = value.Sub(ret.RefundedAmount)
+	return value.Min(order.Total.Sub(order.AmountRefunded)).Max(zero)
+}
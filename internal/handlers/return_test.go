@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"testing"
+
+	"ecommerce-backend/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func usd(amount int64) models.Money {
+	return models.NewMoney(amount, "USD")
+}
+
+func TestReturnableQuantities(t *testing.T) {
+	phone := primitive.NewObjectID()
+	shirt := primitive.NewObjectID()
+	order := models.Order{
+		Items: []models.OrderItem{
+			{ProductID: phone, Quantity: 1, UnitPrice: usd(20000)},
+			{ProductID: shirt, Quantity: 3, UnitPrice: usd(2000), Returned: 2},
+		},
+	}
+
+	returnable := returnableQuantities(order)
+
+	assert.Equal(t, 1, returnable[phone])
+	assert.Equal(t, 1, returnable[shirt])
+	assert.Equal(t, 0, returnable[primitive.NewObjectID()])
+}
+
+func TestReturnRefundAmount(t *testing.T) {
+	shirt := primitive.NewObjectID()
+
+	tests := []struct {
+		name     string
+		order    models.Order
+		ret      models.Return
+		expected models.Money
+	}{
+		{
+			name:     "full price items",
+			order:    models.Order{Subtotal: usd(10000), Total: usd(10000)},
+			ret:      models.Return{Items: []models.ReturnItem{{ProductID: shirt, Quantity: 2, UnitPrice: usd(2000)}}},
+			expected: usd(4000),
+		},
+		{
+			name:     "order discount is shared proportionally",
+			order:    models.Order{Subtotal: usd(10000), Total: usd(9000)},
+			ret:      models.Return{Items: []models.ReturnItem{{ProductID: shirt, Quantity: 2, UnitPrice: usd(2000)}}},
+			expected: usd(3600),
+		},
+		{
+			name:     "capped at remaining refundable amount",
+			order:    models.Order{Subtotal: usd(10000), Total: usd(10000), AmountRefunded: usd(9000)},
+			ret:      models.Return{Items: []models.ReturnItem{{ProductID: shirt, Quantity: 2, UnitPrice: usd(2000)}}},
+			expected: usd(1000),
+		},
+		{
+			name:     "already refunded portion is excluded",
+			order:    models.Order{Subtotal: usd(10000), Total: usd(10000), AmountRefunded: usd(1500)},
+			ret:      models.Return{RefundedAmount: usd(1500), Items: []models.ReturnItem{{ProductID: shirt, Quantity: 2, UnitPrice: usd(2000)}}},
+			expected: usd(2500),
+		},
+		{
+			name:     "shipping is not refunded",
+			order:    models.Order{Subtotal: usd(10000), ShippingCost: usd(1000), Total: usd(10000)},
+			ret:      models.Return{Items: []models.ReturnItem{{ProductID: shirt, Quantity: 2, UnitPrice: usd(2000)}}},
+			expected: usd(3600),
+		},
+		{
+			name: "tax breakdown gives the taxed line value",
+			order: models.Order{
+				Subtotal: usd(6000),
+				Total:    usd(7200),
+				TaxBreakdown: &models.TaxBreakdown{Lines: []models.TaxLine{
+					{ProductID: shirt, Quantity: 3, Net: usd(6000), Tax: usd(1200), Gross: usd(7200)},
+				}},
+			},
+			ret:      models.Return{Items: []models.ReturnItem{{ProductID: shirt, Quantity: 2, UnitPrice: usd(2000)}}},
+			expected: usd(4800),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, returnRefundAmount(tt.order, tt.ret))
+		})
+	}
+}
@@ -0,0 +1,270 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"time"
+
+	"ecommerce-backend/internal/audit"
+	"ecommerce-backend/internal/database"
+	apperrors "ecommerce-backend/internal/errors"
+	"ecommerce-backend/internal/middleware"
+	"ecommerce-backend/internal/models"
+	"ecommerce-backend/internal/sessions"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// roleNamePattern restricts role names to lowercase identifiers
+var roleNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// RoleHandler handles admin management of roles and their permissions
+type RoleHandler struct {
+	db       *database.Client
+	sessions *sessions.Store
+}
+
+// NewRoleHandler creates a new RoleHandler
+func NewRoleHandler(db *database.Client, sessionStore *sessions.Store) *RoleHandler {
+	return &RoleHandler{
+		db:       db,
+		sessions: sessionStore,
+	}
+}
+
+// GetRoles lists the roles and every available permission (requires roles:manage)
+func (h *RoleHandler) GetRoles(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cursor, err := h.db.GetCollection("roles").Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "name", Value: 1}}))
+	if err != nil {
+		c.Error(apperrors.Internal("Failed to fetch roles", err))
+		return
+	}
+	defer cursor.Close(ctx)
+
+	roles := []models.RoleDefinition{}
+	if err := cursor.All(ctx, &roles); err != nil {
+		c.Error(apperrors.Internal("Failed to decode roles", err))
+		return
+	}
+
+	// The admin role always has every permission, whatever is stored
+	for i := range roles {
+		if roles[i].Name == models.RoleAdmin {
+			roles[i].Permissions = models.AllPermissions
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"roles":       roles,
+		"permissions": models.AllPermissions,
+	})
+}
+
+// CreateRole creates a role bundling a set of permissions (requires roles:manage)
+func (h *RoleHandler) CreateRole(c *gin.Context) {
+	req, ok := h.bindRoleRequest(c)
+	if !ok {
+		return
+	}
+
+	if !roleNamePattern.MatchString(req.Name) {
+		c.Error(apperrors.BadRequest("Role name must be lowercase letters, digits and underscores"))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	collection := h.db.GetCollection("roles")
+	count, err := collection.CountDocuments(ctx, bson.M{"name": req.Name})
+	if err != nil {
+		c.Error(apperrors.Internal("Database error", err))
+		return
+	}
+	if count > 0 || models.Role(req.Name).IsValid() {
+		c.Error(apperrors.Conflict("Role already exists"))
+		return
+	}
+
+	now := time.Now()
+	role := models.RoleDefinition{
+		ID:          primitive.NewObjectID(),
+		Name:        models.Role(req.Name),
+		Description: req.Description,
+		Permissions: req.Permissions,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	if _, err := collection.InsertOne(ctx, role); err != nil {
+		c.Error(apperrors.Internal("Failed to create role", err))
+		return
+	}
+	audit.SetResourceID(c, string(role.Name))
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Role created successfully",
+		"role":    role,
+	})
+}
+
+// UpdateRole replaces a role's description and permissions. The sessions of users with
+// the role are revoked, so that they sign in again with the new permissions. (requires roles:manage)
+func (h *RoleHandler) UpdateRole(c *gin.Context) {
+	name := models.Role(c.Param("name"))
+	if name.IsValid() {
+		c.Error(apperrors.BadRequest("Built-in roles cannot be changed"))
+		return
+	}
+
+	req, ok := h.bindRoleRequest(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	update := bson.M{"$set": bson.M{
+		"description": req.Description,
+		"permissions": req.Permissions,
+		"updated_at":  time.Now(),
+	}}
+
+	var role models.RoleDefinition
+	after := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	err := h.db.GetCollection("roles").FindOneAndUpdate(ctx, bson.M{"name": name, "built_in": bson.M{"$ne": true}}, update, after).Decode(&role)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.Error(apperrors.NotFound("Role not found"))
+			return
+		}
+		c.Error(apperrors.Internal("Failed to update role", err))
+		return
+	}
+
+	if err := h.revokeRoleSessions(ctx, role.Name); err != nil {
+		c.Error(apperrors.Internal("Failed to revoke sessions", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Role updated successfully",
+		"role":    role,
+	})
+}
+
+// DeleteRole deletes a role no user has (requires roles:manage)
+func (h *RoleHandler) DeleteRole(c *gin.Context) {
+	name := models.Role(c.Param("name"))
+	if name.IsValid() {
+		c.Error(apperrors.BadRequest("Built-in roles cannot be deleted"))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	count, err := h.db.GetCollection("users").CountDocuments(ctx, bson.M{"role": name})
+	if err != nil {
+		c.Error(apperrors.Internal("Database error", err))
+		return
+	}
+	if count > 0 {
+		c.Error(apperrors.Conflict("Role is assigned to users"))
+		return
+	}
+
+	result, err := h.db.GetCollection("roles").DeleteOne(ctx, bson.M{"name": name, "built_in": bson.M{"$ne": true}})
+	if err != nil {
+		c.Error(apperrors.Internal("Failed to delete role", err))
+		return
+	}
+	if result.DeletedCount == 0 {
+		c.Error(apperrors.NotFound("Role not found"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Role deleted successfully"})
+}
+
+// bindRoleRequest binds and validates a role payload. It writes the error response and
+// returns false on failure.
+func (h *RoleHandler) bindRoleRequest(c *gin.Context) (models.RoleRequest, bool) {
+	var req models.RoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperrors.Validation(err))
+		return req, false
+	}
+
+	// A role cannot grant more than its creator holds
+	for _, permission := range req.Permissions {
+		if !permission.IsValid() {
+			c.Error(apperrors.BadRequest("Unknown permission: " + string(permission)))
+			return req, false
+		}
+		if !middleware.HasPermission(c, permission) {
+			c.Error(apperrors.Forbidden("Cannot grant a permission you do not hold: " + string(permission)))
+			return req, false
+		}
+	}
+	return req, true
+}
+
+// revokeRoleSessions signs out every user with the role
+func (h *RoleHandler) revokeRoleSessions(ctx context.Context, role models.Role) error {
+	cursor, err := h.db.GetCollection("users").Find(ctx, bson.M{"role": role}, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	var users []struct {
+		ID primitive.ObjectID `bson:"_id"`
+	}
+	if err := cursor.All(ctx, &users); err != nil {
+		return err
+	}
+
+	userIDs := make([]primitive.ObjectID, len(users))
+	for i, user := range users {
+		userIDs[i] = user.ID
+	}
+	return h.sessions.RevokeUsers(ctx, userIDs)
+}
+
+// Helper functions
+
+// rolePermissions returns the permissions granted by a role. The admin role has every
+// permission; unknown roles have none.
+func rolePermissions(ctx context.Context, db *database.Client, role models.Role) ([]string, error) {
+	if role == models.RoleAdmin {
+		return permissionNames(models.AllPermissions), nil
+	}
+
+	var definition models.RoleDefinition
+	err := db.GetCollection("roles").FindOne(ctx, bson.M{"name": role}).Decode(&definition)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return permissionNames(definition.Permissions), nil
+}
+
+func permissionNames(permissions []models.Permission) []string {
+	names := make([]string, len(permissions))
+	for i, permission := range permissions {
+		names[i] = string(permission)
+	}
+	return names
+}
@@ -0,0 +1,506 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"ecommerce-backend/internal/audit"
+	"ecommerce-backend/internal/database"
+	apperrors "ecommerce-backend/internal/errors"
+	"ecommerce-backend/internal/models"
+	"ecommerce-backend/internal/pricing"
+	"ecommerce-backend/internal/shipping"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// errNoShippingZone is returned when no shipping zone covers a destination
+var errNoShippingZone = errors.New("We do not ship to this address")
+
+// ShippingHandler handles shipping zones, methods and quotes
+type ShippingHandler struct {
+	db       *database.Client
+	currency string
+}
+
+// NewShippingHandler creates a new ShippingHandler
+func NewShippingHandler(db *database.Client, currency string) *ShippingHandler {
+	return &ShippingHandler{
+		db:       db,
+		currency: currency,
+	}
+}
+
+// Quote returns the shipping methods available for a cart and destination
+func (h *ShippingHandler) Quote(c *gin.Context) {
+	var req models.ShippingQuoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperrors.Validation(err))
+		return
+	}
+
+	// Quotes are public; signed-in users get their per-user promotion limits applied
+	var userObjID primitive.ObjectID
+	if userID, exists := c.Get("user_id"); exists {
+		userObjID, _ = primitive.ObjectIDFromHex(userID.(string))
+	}
+
+	if req.Address != nil && req.AddressID != "" {
+		c.Error(apperrors.BadRequest("Provide either an address or an address ID"))
+		return
+	}
+	if req.AddressID != "" && userObjID.IsZero() {
+		c.Error(apperrors.Unauthorized("Sign in to use a saved address"))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	address, err := resolveAddress(ctx, h.db, userObjID, req.Address, req.AddressID)
+	if err != nil {
+		if err == errAddressNotFound {
+			c.Error(apperrors.NotFound(err.Error()))
+			return
+		}
+		c.Error(apperrors.Internal("Failed to load address", err))
+		return
+	}
+
+	priceList, err := loadPriceList(ctx, c, h.db, h.currency, req.Currency)
+	if err != nil {
+		respondPriceListError(c, err)
+		return
+	}
+
+	cart, err := loadCart(ctx, h.db, userObjID, priceList, req.Items)
+	if err != nil {
+		if err == errProductUnavailable {
+			c.Error(apperrors.BadRequest(err.Error()))
+			return
+		}
+		c.Error(apperrors.Internal("Failed to load cart products", err))
+		return
+	}
+
+	totals, err := evaluatePromotions(ctx, h.db, cart, priceList, req.Codes)
+	if err != nil {
+		c.Error(apperrors.Internal("Failed to evaluate promotions", err))
+		return
+	}
+
+	parcel := shipping.Parcel{
+		Weight:       cart.Weight(),
+		Subtotal:     totals.Total,
+		FreeShipping: totals.FreeShipping,
+	}
+	zone, quotes, err := quoteShipping(ctx, h.db, *address, parcel, priceList)
+	if err != nil {
+		if err == errNoShippingZone {
+			c.Error(apperrors.Unprocessable(err.Error()))
+			return
+		}
+		c.Error(apperrors.Internal("Failed to quote shipping", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ShippingQuoteResponse{
+		Zone:         zone,
+		Weight:       parcel.Weight,
+		Subtotal:     parcel.Subtotal,
+		FreeShipping: parcel.FreeShipping,
+		Methods:      quotes,
+	})
+}
+
+// GetZones lists all shipping zones (requires shipping:write)
+func (h *ShippingHandler) GetZones(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	zones, err := findShippingZones(ctx, h.db, bson.M{})
+	if err != nil {
+		c.Error(apperrors.Internal("Failed to fetch shipping zones", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"zones": zones})
+}
+
+// CreateZone creates a shipping zone (requires shipping:write)
+func (h *ShippingHandler) CreateZone(c *gin.Context) {
+	var req models.ShippingZoneRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperrors.Validation(err))
+		return
+	}
+
+	zone := zoneFromRequest(req)
+	zone.ID = primitive.NewObjectID()
+	zone.CreatedAt = zone.UpdatedAt
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if _, err := h.db.GetCollection("shipping_zones").InsertOne(ctx, zone); err != nil {
+		c.Error(apperrors.Internal("Failed to create shipping zone", err))
+		return
+	}
+	audit.SetResourceID(c, zone.ID.Hex())
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Shipping zone created successfully",
+		"zone":    zone,
+	})
+}
+
+// UpdateZone replaces a shipping zone's rules (requires shipping:write)
+func (h *ShippingHandler) UpdateZone(c *gin.Context) {
+	objID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.Error(apperrors.BadRequest("Invalid zone ID"))
+		return
+	}
+
+	var req models.ShippingZoneRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperrors.Validation(err))
+		return
+	}
+
+	zone := zoneFromRequest(req)
+	update := bson.M{"$set": bson.M{
+		"name":              zone.Name,
+		"countries":         zone.Countries,
+		"regions":           zone.Regions,
+		"postcode_patterns": zone.PostcodePatterns,
+		"priority":          zone.Priority,
+		"is_active":         zone.IsActive,
+		"updated_at":        zone.UpdatedAt,
+	}}
+
+	collection := h.db.GetCollection("shipping_zones")
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	after := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	err = collection.FindOneAndUpdate(ctx, bson.M{"_id": objID}, update, after).Decode(&zone)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.Error(apperrors.NotFound("Shipping zone not found"))
+			return
+		}
+		c.Error(apperrors.Internal("Failed to update shipping zone", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Shipping zone updated successfully",
+		"zone":    zone,
+	})
+}
+
+// DeleteZone deletes a shipping zone and its methods (requires shipping:write)
+func (h *ShippingHandler) DeleteZone(c *gin.Context) {
+	objID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.Error(apperrors.BadRequest("Invalid zone ID"))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	result, err := h.db.GetCollection("shipping_zones").DeleteOne(ctx, bson.M{"_id": objID})
+	if err != nil {
+		c.Error(apperrors.Internal("Failed to delete shipping zone", err))
+		return
+	}
+
+	if result.DeletedCount == 0 {
+		c.Error(apperrors.NotFound("Shipping zone not found"))
+		return
+	}
+
+	if _, err := h.db.GetCollection("shipping_methods").DeleteMany(ctx, bson.M{"zone_id": objID}); err != nil {
+		c.Error(apperrors.Internal("Failed to delete shipping methods", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Shipping zone deleted successfully"})
+}
+
+// GetMethods lists shipping methods, optionally for one zone (requires shipping:write)
+func (h *ShippingHandler) GetMethods(c *gin.Context) {
+	filter := bson.M{}
+	if zoneID := c.Query("zone_id"); zoneID != "" {
+		objID, err := primitive.ObjectIDFromHex(zoneID)
+		if err != nil {
+			c.Error(apperrors.BadRequest("Invalid zone ID"))
+			return
+		}
+		filter["zone_id"] = objID
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	methods, err := findShippingMethods(ctx, h.db, filter)
+	if err != nil {
+		c.Error(apperrors.Internal("Failed to fetch shipping methods", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"methods": methods})
+}
+
+// CreateMethod creates a shipping method in a zone (requires shipping:write)
+func (h *ShippingHandler) CreateMethod(c *gin.Context) {
+	var req models.ShippingMethodRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperrors.Validation(err))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	method, ok := h.methodFromRequest(ctx, c, req)
+	if !ok {
+		return
+	}
+	method.ID = primitive.NewObjectID()
+	method.CreatedAt = method.UpdatedAt
+
+	if _, err := h.db.GetCollection("shipping_methods").InsertOne(ctx, method); err != nil {
+		c.Error(apperrors.Internal("Failed to create shipping method", err))
+		return
+	}
+	audit.SetResourceID(c, method.ID.Hex())
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Shipping method created successfully",
+		"method":  method,
+	})
+}
+
+// UpdateMethod replaces a shipping method (requires shipping:write)
+func (h *ShippingHandler) UpdateMethod(c *gin.Context) {
+	objID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.Error(apperrors.BadRequest("Invalid method ID"))
+		return
+	}
+
+	var req models.ShippingMethodRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperrors.Validation(err))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	method, ok := h.methodFromRequest(ctx, c, req)
+	if !ok {
+		return
+	}
+
+	update := bson.M{"$set": bson.M{
+		"zone_id":        method.ZoneID,
+		"name":           method.Name,
+		"type":           method.Type,
+		"rate":           method.Rate,
+		"weight_tiers":   method.WeightTiers,
+		"free_threshold": method.FreeThreshold,
+		"min_days":       method.MinDays,
+		"max_days":       method.MaxDays,
+		"is_active":      method.IsActive,
+		"updated_at":     method.UpdatedAt,
+	}}
+
+	after := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	err = h.db.GetCollection("shipping_methods").FindOneAndUpdate(ctx, bson.M{"_id": objID}, update, after).Decode(&method)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.Error(apperrors.NotFound("Shipping method not found"))
+			return
+		}
+		c.Error(apperrors.Internal("Failed to update shipping method", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Shipping method updated successfully",
+		"method":  method,
+	})
+}
+
+// DeleteMethod deletes a shipping method (requires shipping:write)
+func (h *ShippingHandler) DeleteMethod(c *gin.Context) {
+	objID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.Error(apperrors.BadRequest("Invalid method ID"))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	result, err := h.db.GetCollection("shipping_methods").DeleteOne(ctx, bson.M{"_id": objID})
+	if err != nil {
+		c.Error(apperrors.Internal("Failed to delete shipping method", err))
+		return
+	}
+
+	if result.DeletedCount == 0 {
+		c.Error(apperrors.NotFound("Shipping method not found"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Shipping method deleted successfully"})
+}
+
+// methodFromRequest validates a method request against its type and zone
+func (h *ShippingHandler) methodFromRequest(ctx context.Context, c *gin.Context, req models.ShippingMethodRequest) (models.ShippingMethod, bool) {
+	var method models.ShippingMethod
+
+	rateType := models.ShippingRateType(req.Type)
+	if !rateType.IsValid() {
+		c.Error(apperrors.BadRequest("Invalid shipping method type"))
+		return method, false
+	}
+	if rateType == models.ShippingWeightBased && len(req.WeightTiers) == 0 {
+		c.Error(apperrors.BadRequest("Weight based methods need at least one weight tier"))
+		return method, false
+	}
+
+	rate, rateOK := normalizeMoney(req.Rate, h.currency)
+	threshold, thresholdOK := normalizeMoney(req.FreeThreshold, h.currency)
+	tiers := make([]models.WeightTier, len(req.WeightTiers))
+	for i, tier := range req.WeightTiers {
+		tiers[i] = tier
+		if tiers[i].Rate, rateOK = normalizeMoney(tier.Rate, h.currency); !rateOK {
+			break
+		}
+	}
+	if !rateOK || !thresholdOK {
+		c.Error(apperrors.BadRequest("Shipping prices must be non-negative amounts in " + h.currency))
+		return method, false
+	}
+	if rateType == models.ShippingFreeOverThreshold && !threshold.IsPositive() {
+		c.Error(apperrors.BadRequest("Free shipping threshold must be greater than 0"))
+		return method, false
+	}
+
+	zoneID, err := primitive.ObjectIDFromHex(req.ZoneID)
+	if err != nil {
+		c.Error(apperrors.BadRequest("Invalid zone ID"))
+		return method, false
+	}
+
+	count, err := h.db.GetCollection("shipping_zones").CountDocuments(ctx, bson.M{"_id": zoneID})
+	if err != nil {
+		c.Error(apperrors.Internal("Failed to fetch shipping zone", err))
+		return method, false
+	}
+	if count == 0 {
+		c.Error(apperrors.BadRequest("Shipping zone not found"))
+		return method, false
+	}
+
+	return models.ShippingMethod{
+		ZoneID:        zoneID,
+		Name:          req.Name,
+		Type:          rateType,
+		Rate:          rate,
+		WeightTiers:   tiers,
+		FreeThreshold: threshold,
+		MinDays:       req.MinDays,
+		MaxDays:       req.MaxDays,
+		IsActive:      req.IsActive,
+		UpdatedAt:     time.Now(),
+	}, true
+}
+
+// Helper functions
+
+// zoneFromRequest builds a zone with country codes upper-cased
+func zoneFromRequest(req models.ShippingZoneRequest) models.ShippingZone {
+	countries := make([]string, 0, len(req.Countries))
+	for _, country := range req.Countries {
+		countries = append(countries, models.Address{Country: country}.Normalized().Country)
+	}
+
+	return models.ShippingZone{
+		Name:             req.Name,
+		Countries:        countries,
+		Regions:          req.Regions,
+		PostcodePatterns: req.PostcodePatterns,
+		Priority:         req.Priority,
+		IsActive:         req.IsActive,
+		UpdatedAt:        time.Now(),
+	}
+}
+
+// findShippingZones lists zones matching filter by descending priority
+func findShippingZones(ctx context.Context, db *database.Client, filter bson.M) ([]models.ShippingZone, error) {
+	findOptions := options.Find().SetSort(bson.D{{Key: "priority", Value: -1}, {Key: "name", Value: 1}})
+	cursor, err := db.GetCollection("shipping_zones").Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	zones := []models.ShippingZone{}
+	if err := cursor.All(ctx, &zones); err != nil {
+		return nil, err
+	}
+	return zones, nil
+}
+
+// findShippingMethods lists methods matching filter by name
+func findShippingMethods(ctx context.Context, db *database.Client, filter bson.M) ([]models.ShippingMethod, error) {
+	findOptions := options.Find().SetSort(bson.D{{Key: "name", Value: 1}})
+	cursor, err := db.GetCollection("shipping_methods").Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	methods := []models.ShippingMethod{}
+	if err := cursor.All(ctx, &methods); err != nil {
+		return nil, err
+	}
+	return methods, nil
+}
+
+// quoteShipping finds the zone covering the address and prices its methods for the parcel
+// in the price list currency
+func quoteShipping(ctx context.Context, db *database.Client, address models.Address, parcel shipping.Parcel, priceList pricing.PriceList) (*models.ShippingZone, []models.ShippingQuote, error) {
+	zones, err := findShippingZones(ctx, db, bson.M{"is_active": true})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	zone := shipping.MatchZone(zones, address)
+	if zone == nil {
+		return nil, nil, errNoShippingZone
+	}
+
+	methods, err := findShippingMethods(ctx, db, bson.M{"zone_id": zone.ID, "is_active": true})
+	if err != nil {
+		return nil, nil, err
+	}
+	for i := range methods {
+		methods[i] = priceList.ShippingMethod(methods[i])
+	}
+
+	return zone, shipping.Quote(*zone, methods, parcel, time.Now()), nil
+}
@@ -9,11 +9,12 @@ import (
 	"strings"
 	"time"
 
+	"ecommerce-backend/internal/audit"
 	"ecommerce-backend/internal/database"
+	apperrors "ecommerce-backend/internal/errors"
 	"ecommerce-backend/internal/models"
 
 	"github.com/gin-gonic/gin"
-	"github.com/go-playground/validator/v10"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -22,60 +23,55 @@ import (
 
 // SliderHandler handles slider-related HTTP requests
 type SliderHandler struct {
-	db        *database.Client
-	validator *validator.Validate
+	db *database.Client
 }
 
 // NewSliderHandler creates a new SliderHandler
 func NewSliderHandler(db *database.Client) *SliderHandler {
 	return &SliderHandler{
-		db:        db,
-		validator: validator.New(),
+		db: db,
 	}
 }
 
-// UploadSliderImage handles slider image upload (Admin only)
+// UploadSliderImage handles slider image upload (requires sliders:write)
 func (h *SliderHandler) UploadSliderImage(c *gin.Context) {
-	userRole, exists := c.Get("user_role")
-	if !exists || userRole != "admin" {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Admin access required"})
-		return
-	}
-
 	// Parse multipart form
 	file, header, err := c.Request.FormFile("image")
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "No image file provided"})
+		c.Error(apperrors.BadRequest("No image file provided"))
 		return
 	}
 	defer file.Close()
 
 	// Validate file type
 	if !isValidImageType(header.Filename) {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid image format. Only JPG, JPEG, PNG, and GIF are allowed"})
+		c.Error(apperrors.BadRequest("Invalid image format. Only JPG, JPEG, PNG, and GIF are allowed"))
 		return
 	}
 
 	// Validate file size (max 5MB)
 	if header.Size > 5*1024*1024 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Image size too large. Maximum 5MB allowed"})
+		c.Error(apperrors.BadRequest("Image size too large. Maximum 5MB allowed"))
 		return
 	}
 
 	// Create uploads directory if it doesn't exist
 	uploadDir := "uploads/slider"
 	if err := os.MkdirAll(uploadDir, 0755); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create upload directory"})
+		c.Error(apperrors.Internal("Failed to create upload directory", err))
 		return
 	}
 
-	// Generate unique filename
-	filename := fmt.Sprintf("slider_%d%s", time.Now().Unix(), filepath.Ext(header.Filename))
+	filename, err := uploadFilename("slider", header.Filename)
+	if err != nil {
+		c.Error(apperrors.Internal("Failed to name image", err))
+		return
+	}
 	filePath := filepath.Join(uploadDir, filename)
 
 	// Save file
 	if err := saveUploadedFile(file, filePath); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save image"})
+		c.Error(apperrors.Internal("Failed to save image", err))
 		return
 	}
 
@@ -94,7 +90,7 @@ func (h *SliderHandler) UploadSliderImage(c *gin.Context) {
 	adminID, err := primitive.ObjectIDFromHex(userID.(string))
 	if err != nil {
 		os.Remove(filePath)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		c.Error(apperrors.BadRequest("Invalid user ID"))
 		return
 	}
 
@@ -111,9 +107,10 @@ func (h *SliderHandler) UploadSliderImage(c *gin.Context) {
 	_, err = collection.InsertOne(ctx, slider)
 	if err != nil {
 		os.Remove(filePath)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save slider to database"})
+		c.Error(apperrors.Internal("Failed to save slider to database", err))
 		return
 	}
+	audit.SetResourceID(c, slider.ID.Hex())
 
 	// Return full URL
 	fullImageURL := slider.ImageURL
@@ -143,14 +140,14 @@ func (h *SliderHandler) GetSliders(c *gin.Context) {
 	findOptions := options.Find().SetSort(bson.D{{Key: "order", Value: 1}})
 	cursor, err := collection.Find(ctx, bson.M{}, findOptions)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch sliders"})
+		c.Error(apperrors.Internal("Failed to fetch sliders", err))
 		return
 	}
 	defer cursor.Close(ctx)
 
 	var sliders []models.Slider
 	if err = cursor.All(ctx, &sliders); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to decode sliders"})
+		c.Error(apperrors.Internal("Failed to decode sliders", err))
 		return
 	}
 
@@ -194,12 +191,6 @@ func (h *SliderHandler) GetSliders(c *gin.Context) {
 
 // GetAllSliders retrieves all sliders for admin (list view)
 func (h *SliderHandler) GetAllSliders(c *gin.Context) {
-	userRole, exists := c.Get("user_role")
-	if !exists || userRole != "admin" {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Admin access required"})
-		return
-	}
-
 	collection := h.db.GetCollection("slider")
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
@@ -207,14 +198,14 @@ func (h *SliderHandler) GetAllSliders(c *gin.Context) {
 	findOptions := options.Find().SetSort(bson.D{{Key: "order", Value: 1}})
 	cursor, err := collection.Find(ctx, bson.M{}, findOptions)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch sliders"})
+		c.Error(apperrors.Internal("Failed to fetch sliders", err))
 		return
 	}
 	defer cursor.Close(ctx)
 
 	var sliders []models.Slider
 	if err = cursor.All(ctx, &sliders); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to decode sliders"})
+		c.Error(apperrors.Internal("Failed to decode sliders", err))
 		return
 	}
 
@@ -230,18 +221,12 @@ func (h *SliderHandler) GetAllSliders(c *gin.Context) {
 	})
 }
 
-// DeleteSlider deletes a slider image (Admin only)
+// DeleteSlider deletes a slider image (requires sliders:write)
 func (h *SliderHandler) DeleteSlider(c *gin.Context) {
-	userRole, exists := c.Get("user_role")
-	if !exists || userRole != "admin" {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Admin access required"})
-		return
-	}
-
 	sliderID := c.Param("id")
 	objID, err := primitive.ObjectIDFromHex(sliderID)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid slider ID"})
+		c.Error(apperrors.BadRequest("Invalid slider ID"))
 		return
 	}
 
@@ -262,26 +247,20 @@ func (h *SliderHandler) DeleteSlider(c *gin.Context) {
 	// Delete from database
 	result, err := collection.DeleteOne(ctx, bson.M{"_id": objID})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete slider"})
+		c.Error(apperrors.Internal("Failed to delete slider", err))
 		return
 	}
 
 	if result.DeletedCount == 0 {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Slider not found"})
+		c.Error(apperrors.NotFound("Slider not found"))
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "Slider deleted successfully"})
 }
 
-// GetSliderSettings retrieves slider settings (Admin only)
+// GetSliderSettings retrieves slider settings (requires sliders:write)
 func (h *SliderHandler) GetSliderSettings(c *gin.Context) {
-	userRole, exists := c.Get("user_role")
-	if !exists || userRole != "admin" {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Admin access required"})
-		return
-	}
-
 	collection := h.db.GetCollection("slider_settings")
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
@@ -301,7 +280,7 @@ func (h *SliderHandler) GetSliderSettings(c *gin.Context) {
 	}
 
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch settings"})
+		c.Error(apperrors.Internal("Failed to fetch settings", err))
 		return
 	}
 
@@ -314,22 +293,11 @@ func (h *SliderHandler) GetSliderSettings(c *gin.Context) {
 	})
 }
 
-// UpdateSliderSettings updates slider settings (Admin only)
+// UpdateSliderSettings updates slider settings (requires sliders:write)
 func (h *SliderHandler) UpdateSliderSettings(c *gin.Context) {
-	userRole, exists := c.Get("user_role")
-	if !exists || userRole != "admin" {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Admin access required"})
-		return
-	}
-
 	var req models.UpdateSliderSettingsRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload"})
-		return
-	}
-
-	if err := h.validator.Struct(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(apperrors.Validation(err))
 		return
 	}
 
@@ -356,7 +324,7 @@ func (h *SliderHandler) UpdateSliderSettings(c *gin.Context) {
 	opts := options.Update().SetUpsert(true)
 	_, err := collection.UpdateOne(ctx, bson.M{}, update, opts)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update settings"})
+		c.Error(apperrors.Internal("Failed to update settings", err))
 		return
 	}
 
@@ -364,7 +332,7 @@ func (h *SliderHandler) UpdateSliderSettings(c *gin.Context) {
 	var settings models.SliderSettings
 	err = collection.FindOne(ctx, bson.M{}).Decode(&settings)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch updated settings"})
+		c.Error(apperrors.Internal("Failed to fetch updated settings", err))
 		return
 	}
 
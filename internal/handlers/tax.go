@@ -0,0 +1,246 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"ecommerce-backend/internal/audit"
+	"ecommerce-backend/internal/config"
+	"ecommerce-backend/internal/database"
+	apperrors "ecommerce-backend/internal/errors"
+	"ecommerce-backend/internal/models"
+	"ecommerce-backend/internal/tax"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// TaxHandler handles admin management of tax rates
+type TaxHandler struct {
+	db *database.Client
+}
+
+// NewTaxHandler creates a new TaxHandler
+func NewTaxHandler(db *database.Client) *TaxHandler {
+	return &TaxHandler{
+		db: db,
+	}
+}
+
+// GetTaxRates lists tax rates, optionally for one country (requires tax:write)
+func (h *TaxHandler) GetTaxRates(c *gin.Context) {
+	filter := bson.M{}
+	if country := c.Query("country"); country != "" {
+		filter["country"] = strings.ToUpper(country)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	rates, err := findTaxRates(ctx, h.db, filter)
+	if err != nil {
+		c.Error(apperrors.Internal("Failed to fetch tax rates", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"rates": rates})
+}
+
+// CreateTaxRate creates a tax rate for a country or region (requires tax:write)
+func (h *TaxHandler) CreateTaxRate(c *gin.Context) {
+	var req models.TaxRateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperrors.Validation(err))
+		return
+	}
+
+	rate, ok := taxRateFromRequest(c, req)
+	if !ok {
+		return
+	}
+	rate.ID = primitive.NewObjectID()
+	rate.CreatedAt = rate.UpdatedAt
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if !h.ensureUniqueRate(ctx, c, rate, primitive.NilObjectID) {
+		return
+	}
+
+	if _, err := h.db.GetCollection("tax_rates").InsertOne(ctx, rate); err != nil {
+		c.Error(apperrors.Internal("Failed to create tax rate", err))
+		return
+	}
+	audit.SetResourceID(c, rate.ID.Hex())
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Tax rate created successfully",
+		"rate":    rate,
+	})
+}
+
+// UpdateTaxRate replaces a tax rate (requires tax:write)
+func (h *TaxHandler) UpdateTaxRate(c *gin.Context) {
+	objID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.Error(apperrors.BadRequest("Invalid tax rate ID"))
+		return
+	}
+
+	var req models.TaxRateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperrors.Validation(err))
+		return
+	}
+
+	rate, ok := taxRateFromRequest(c, req)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if !h.ensureUniqueRate(ctx, c, rate, objID) {
+		return
+	}
+
+	update := bson.M{"$set": bson.M{
+		"name":       rate.Name,
+		"country":    rate.Country,
+		"region":     rate.Region,
+		"class":      rate.Class,
+		"rate":       rate.Rate,
+		"is_active":  rate.IsActive,
+		"updated_at": rate.UpdatedAt,
+	}}
+
+	after := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	err = h.db.GetCollection("tax_rates").FindOneAndUpdate(ctx, bson.M{"_id": objID}, update, after).Decode(&rate)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.Error(apperrors.NotFound("Tax rate not found"))
+			return
+		}
+		c.Error(apperrors.Internal("Failed to update tax rate", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Tax rate updated successfully",
+		"rate":    rate,
+	})
+}
+
+// DeleteTaxRate deletes a tax rate (requires tax:write)
+func (h *TaxHandler) DeleteTaxRate(c *gin.Context) {
+	objID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.Error(apperrors.BadRequest("Invalid tax rate ID"))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	result, err := h.db.GetCollection("tax_rates").DeleteOne(ctx, bson.M{"_id": objID})
+	if err != nil {
+		c.Error(apperrors.Internal("Failed to delete tax rate", err))
+		return
+	}
+
+	if result.DeletedCount == 0 {
+		c.Error(apperrors.NotFound("Tax rate not found"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Tax rate deleted successfully"})
+}
+
+// ensureUniqueRate rejects a second rate for the same country, region and class
+func (h *TaxHandler) ensureUniqueRate(ctx context.Context, c *gin.Context, rate models.TaxRate, excludeID primitive.ObjectID) bool {
+	filter := bson.M{"country": rate.Country, "region": rate.Region, "class": rate.Class}
+	if rate.Region == "" {
+		filter["region"] = bson.M{"$in": []interface{}{"", nil}}
+	}
+	if !excludeID.IsZero() {
+		filter["_id"] = bson.M{"$ne": excludeID}
+	}
+
+	count, err := h.db.GetCollection("tax_rates").CountDocuments(ctx, filter)
+	if err != nil {
+		c.Error(apperrors.Internal("Failed to check existing tax rates", err))
+		return false
+	}
+	if count > 0 {
+		c.Error(apperrors.Conflict("A tax rate for this class and region already exists"))
+		return false
+	}
+	return true
+}
+
+// Helper functions
+
+// taxRateFromRequest validates the class and normalizes the country code
+func taxRateFromRequest(c *gin.Context, req models.TaxRateRequest) (models.TaxRate, bool) {
+	class := models.TaxClass(req.Class)
+	if !class.IsValid() {
+		c.Error(apperrors.BadRequest("Invalid tax class"))
+		return models.TaxRate{}, false
+	}
+
+	return models.TaxRate{
+		Name:      req.Name,
+		Country:   strings.ToUpper(req.Country),
+		Region:    strings.TrimSpace(req.Region),
+		Class:     class,
+		Rate:      req.Rate,
+		IsActive:  req.IsActive,
+		UpdatedAt: time.Now(),
+	}, true
+}
+
+// findTaxRates lists rates matching filter by country and region
+func findTaxRates(ctx context.Context, db *database.Client, filter bson.M) ([]models.TaxRate, error) {
+	findOptions := options.Find().SetSort(bson.D{{Key: "country", Value: 1}, {Key: "region", Value: 1}, {Key: "class", Value: 1}})
+	cursor, err := db.GetCollection("tax_rates").Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	rates := []models.TaxRate{}
+	if err := cursor.All(ctx, &rates); err != nil {
+		return nil, err
+	}
+	return rates, nil
+}
+
+// calculateTax taxes the cart for the address, or the store's default location when
+// no address is known yet
+func calculateTax(ctx context.Context, db *database.Client, cfg config.TaxConfig, cart models.Cart, discount, shippingCost models.Money, address *models.Address) (models.TaxBreakdown, error) {
+	destination := models.Address{Country: cfg.DefaultCountry, Region: cfg.DefaultRegion}
+	if address != nil {
+		destination = *address
+	}
+	destination = destination.Normalized()
+
+	rates, err := findTaxRates(ctx, db, bson.M{"country": destination.Country, "is_active": true})
+	if err != nil {
+		return models.TaxBreakdown{}, err
+	}
+
+	return tax.Calculate(rates, tax.Input{
+		Lines:            cart.Lines,
+		Discount:         discount,
+		Shipping:         shippingCost,
+		Address:          destination,
+		PricesIncludeTax: cfg.PricesIncludeTax,
+	}), nil
+}
@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"ecommerce-backend/internal/config"
+	"ecommerce-backend/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUploadHandler_ServeUpload(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "products"), 0755))
+	for name, content := range map[string]string{
+		"products/phone.png": "png",
+		"products/page.html": "<script>alert(1)</script>",
+		"products/image.svg": "<svg/>",
+		"products/.env":      "SECRET=1",
+		"manual.pdf":         "pdf",
+	} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+	}
+	outside := filepath.Join(filepath.Dir(dir), "outside.png")
+	require.NoError(t, os.WriteFile(outside, []byte("secret"), 0644))
+	t.Cleanup(func() { os.Remove(outside) })
+
+	tests := []struct {
+		name                string
+		path                string
+		expectedStatus      int
+		expectedType        string
+		expectedDisposition string
+	}{
+		{name: "image", path: "/uploads/products/phone.png", expectedStatus: http.StatusOK, expectedType: "image/png"},
+		{name: "known download type", path: "/uploads/manual.pdf", expectedStatus: http.StatusOK, expectedType: "application/pdf", expectedDisposition: `attachment; filename="manual.pdf"`},
+		{name: "html is downloaded", path: "/uploads/products/page.html", expectedStatus: http.StatusOK, expectedType: "application/octet-stream", expectedDisposition: `attachment; filename="page.html"`},
+		{name: "svg is downloaded", path: "/uploads/products/image.svg", expectedStatus: http.StatusOK, expectedType: "application/octet-stream", expectedDisposition: `attachment; filename="image.svg"`},
+		{name: "directory", path: "/uploads/products/", expectedStatus: http.StatusNotFound},
+		{name: "root directory", path: "/uploads/", expectedStatus: http.StatusNotFound},
+		{name: "hidden file", path: "/uploads/products/.env", expectedStatus: http.StatusNotFound},
+		{name: "missing file", path: "/uploads/products/missing.png", expectedStatus: http.StatusNotFound},
+		{name: "path traversal", path: "/uploads/../outside.png", expectedStatus: http.StatusNotFound},
+		{name: "encoded path traversal", path: "/uploads/%2e%2e/outside.png", expectedStatus: http.StatusNotFound},
+	}
+
+	handler := NewUploadHandler(dir)
+	router := gin.New()
+	router.Use(middleware.ErrorHandler(config.EnvTest, slog.Default()))
+	router.GET("/uploads/*filepath", handler.ServeUpload)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus != http.StatusOK {
+				assert.Contains(t, w.Body.String(), `"error"`)
+				return
+			}
+			assert.Equal(t, tt.expectedType, w.Header().Get("Content-Type"))
+			assert.Equal(t, tt.expectedDisposition, w.Header().Get("Content-Disposition"))
+			assert.Equal(t, "nosniff", w.Header().Get("X-Content-Type-Options"))
+			assert.Equal(t, "public, max-age=31536000, immutable", w.Header().Get("Cache-Control"))
+			assert.Contains(t, w.Header().Get("Content-Security-Policy"), "sandbox")
+		})
+	}
+}
+
+func TestUploadFilename(t *testing.T) {
+	first, err := uploadFilename("slider", "banner.png")
+	require.NoError(t, err)
+	second, err := uploadFilename("slider", "banner.png")
+	require.NoError(t, err)
+
+	assert.NotEqual(t, first, second)
+	assert.True(t, strings.HasPrefix(first, "slider_"))
+	assert.Equal(t, ".png", filepath.Ext(first))
+}
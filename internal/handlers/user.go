@@ -0,0 +1,368 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"strconv"
+	"time"
+
+	"ecommerce-backend/internal/config"
+	"ecommerce-backend/internal/database"
+	apperrors "ecommerce-backend/internal/errors"
+	"ecommerce-backend/internal/mail"
+	"ecommerce-backend/internal/middleware"
+	"ecommerce-backend/internal/models"
+	"ecommerce-backend/internal/sessions"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// UserHandler handles admin management of user accounts
+type UserHandler struct {
+	db       *database.Client
+	sessions *sessions.Store
+	mailer   mail.Sender
+	mail     config.MailConfig
+}
+
+// NewUserHandler creates a new UserHandler
+func NewUserHandler(db *database.Client, sessionStore *sessions.Store, mailer mail.Sender, mailConfig config.MailConfig) *UserHandler {
+	return &UserHandler{
+		db:       db,
+		sessions: sessionStore,
+		mailer:   mailer,
+		mail:     mailConfig,
+	}
+}
+
+// GetUsers searches users by email or name with pagination (requires users:manage)
+func (h *UserHandler) GetUsers(c *gin.Context) {
+	// Parse query parameters
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	// Build filter
+	filter := bson.M{}
+	if q := c.Query("q"); q != "" {
+		pattern := primitive.Regex{Pattern: regexp.QuoteMeta(q), Options: "i"}
+		filter["$or"] = bson.A{
+			bson.M{"email": pattern},
+			bson.M{"first_name": pattern},
+			bson.M{"last_name": pattern},
+		}
+	}
+	if role := c.Query("role"); role != "" {
+		filter["role"] = role
+	}
+	if active := c.Query("active"); active == "true" || active == "false" {
+		filter["is_active"] = active == "true"
+	}
+
+	collection := h.db.GetCollection("users")
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	total, err := collection.CountDocuments(ctx, filter)
+	if err != nil {
+		c.Error(apperrors.Internal("Failed to count users", err))
+		return
+	}
+
+	findOptions := options.Find().
+		SetSkip(int64((page - 1) * limit)).
+		SetLimit(int64(limit)).
+		SetSort(bson.D{{Key: "created_at", Value: -1}})
+
+	cursor, err := collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		c.Error(apperrors.Internal("Failed to fetch users", err))
+		return
+	}
+	defer cursor.Close(ctx)
+
+	var users []models.User
+	if err := cursor.All(ctx, &users); err != nil {
+		c.Error(apperrors.Internal("Failed to decode users", err))
+		return
+	}
+
+	responses := []models.UserResponse{}
+	for _, user := range users {
+		responses = append(responses, user.ToResponse())
+	}
+
+	c.JSON(http.StatusOK, models.UserListResponse{
+		Users: responses,
+		Total: total,
+		Page:  page,
+		Limit: limit,
+	})
+}
+
+// GetUser retrieves a user by ID (requires users:manage)
+func (h *UserHandler) GetUser(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	user, ok := h.findUser(ctx, c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, user.ToResponse())
+}
+
+// UpdateUserRole changes a user's role and signs them out, so that the new role's
+// permissions apply immediately (requires users:manage)
+func (h *UserHandler) UpdateUserRole(c *gin.Context) {
+	var req models.UpdateUserRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperrors.Validation(err))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	user, ok := h.findManagedUser(ctx, c)
+	if !ok {
+		return
+	}
+
+	if user.ID.Hex() == c.GetString("user_id") {
+		c.Error(apperrors.BadRequest("You cannot change your own role"))
+		return
+	}
+
+	// Only admins may hand out the admin role
+	if req.Role == models.RoleAdmin && c.GetString("user_role") != string(models.RoleAdmin) {
+		c.Error(apperrors.Forbidden("Only admins can grant the admin role"))
+		return
+	}
+
+	if !req.Role.IsValid() {
+		count, err := h.db.GetCollection("roles").CountDocuments(ctx, bson.M{"name": req.Role})
+		if err != nil {
+			c.Error(apperrors.Internal("Database error", err))
+			return
+		}
+		if count == 0 {
+			c.Error(apperrors.BadRequest("Role not found"))
+			return
+		}
+	}
+
+	// A role can only be handed out by someone who holds all of its permissions
+	permissions, err := rolePermissions(ctx, h.db, req.Role)
+	if err != nil {
+		c.Error(apperrors.Internal("Database error", err))
+		return
+	}
+	for _, permission := range permissions {
+		if !middleware.HasPermission(c, models.Permission(permission)) {
+			c.Error(apperrors.Forbidden("Cannot grant a permission you do not hold: " + permission))
+			return
+		}
+	}
+
+	if !h.updateUser(ctx, c, user.ID, bson.M{"role": req.Role}) || !h.revokeSessions(ctx, c, user.ID) {
+		return
+	}
+	user.Role = req.Role
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "User role updated successfully",
+		"user":    user.ToResponse(),
+	})
+}
+
+// ActivateUser allows a deactivated user to sign in again (requires users:manage)
+func (h *UserHandler) ActivateUser(c *gin.Context) {
+	h.setActive(c, true)
+}
+
+// DeactivateUser blocks a user from signing in and revokes their sessions (requires users:manage)
+func (h *UserHandler) DeactivateUser(c *gin.Context) {
+	h.setActive(c, false)
+}
+
+// ForcePasswordReset invalidates a user's password, signs them out and emails them a
+// password reset link (requires users:manage)
+func (h *UserHandler) ForcePasswordReset(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	user, ok := h.findManagedUser(ctx, c)
+	if !ok {
+		return
+	}
+
+	// An empty hash never matches, so the old password stops working right away
+	if !h.updateUser(ctx, c, user.ID, bson.M{"password": ""}) || !h.revokeSessions(ctx, c, user.ID) {
+		return
+	}
+
+	if err := sendPasswordResetEmail(ctx, h.db, h.mailer, h.mail.AppURL, user); err != nil {
+		c.Error(apperrors.Internal("Failed to send password reset email", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password reset link sent"})
+}
+
+// GetUserOrders lists a user's orders with pagination (requires users:manage)
+func (h *UserHandler) GetUserOrders(c *gin.Context) {
+	objID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.Error(apperrors.BadRequest("Invalid user ID"))
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	orders, total, err := findOrders(ctx, h.db, bson.M{"user_id": objID}, page, limit)
+	if err != nil {
+		c.Error(apperrors.Internal("Failed to fetch orders", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.OrderListResponse{
+		Orders: orders,
+		Total:  total,
+		Page:   page,
+		Limit:  limit,
+	})
+}
+
+// GetUserSessions lists a user's active sessions (requires users:manage)
+func (h *UserHandler) GetUserSessions(c *gin.Context) {
+	objID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.Error(apperrors.BadRequest("Invalid user ID"))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	userSessions, err := h.sessions.List(ctx, objID)
+	if err != nil {
+		c.Error(apperrors.Internal("Failed to fetch sessions", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"sessions": userSessions})
+}
+
+// setActive activates or deactivates the user in the path
+func (h *UserHandler) setActive(c *gin.Context, active bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	user, ok := h.findManagedUser(ctx, c)
+	if !ok {
+		return
+	}
+
+	if !active && user.ID.Hex() == c.GetString("user_id") {
+		c.Error(apperrors.BadRequest("You cannot deactivate your own account"))
+		return
+	}
+
+	if !h.updateUser(ctx, c, user.ID, bson.M{"is_active": active}) {
+		return
+	}
+	// Deactivation takes effect immediately rather than when the user's tokens expire
+	if !active && !h.revokeSessions(ctx, c, user.ID) {
+		return
+	}
+	user.IsActive = active
+
+	message := "User activated successfully"
+	if !active {
+		message = "User deactivated successfully"
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": message,
+		"user":    user.ToResponse(),
+	})
+}
+
+// findUser loads the user in the path. It writes the error response and returns false
+// on failure.
+func (h *UserHandler) findUser(ctx context.Context, c *gin.Context) (models.User, bool) {
+	var user models.User
+
+	objID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.Error(apperrors.BadRequest("Invalid user ID"))
+		return user, false
+	}
+
+	if err := h.db.GetCollection("users").FindOne(ctx, bson.M{"_id": objID}).Decode(&user); err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.Error(apperrors.NotFound("User not found"))
+			return user, false
+		}
+		c.Error(apperrors.Internal("Database error", err))
+		return user, false
+	}
+	return user, true
+}
+
+// findManagedUser loads the user in the path for a change to their account. Only admins
+// may change an admin's account. It writes the error response and returns false on
+// failure.
+func (h *UserHandler) findManagedUser(ctx context.Context, c *gin.Context) (models.User, bool) {
+	user, ok := h.findUser(ctx, c)
+	if !ok {
+		return user, false
+	}
+	if user.Role == models.RoleAdmin && c.GetString("user_role") != string(models.RoleAdmin) {
+		c.Error(apperrors.Forbidden("Only admins can manage admin accounts"))
+		return user, false
+	}
+	return user, true
+}
+
+// updateUser sets fields of the user. It writes the error response and returns false on
+// failure.
+func (h *UserHandler) updateUser(ctx context.Context, c *gin.Context, userID primitive.ObjectID, set bson.M) bool {
+	set["updated_at"] = time.Now()
+	if _, err := h.db.GetCollection("users").UpdateOne(ctx, bson.M{"_id": userID}, bson.M{"$set": set}); err != nil {
+		c.Error(apperrors.Internal("Failed to update user", err))
+		return false
+	}
+	return true
+}
+
+// revokeSessions signs the user out everywhere. It writes the error response and returns
+// false on failure.
+func (h *UserHandler) revokeSessions(ctx context.Context, c *gin.Context, userID primitive.ObjectID) bool {
+	if err := h.sessions.RevokeAll(ctx, userID, ""); err != nil {
+		c.Error(apperrors.Internal("Failed to revoke sessions", err))
+		return false
+	}
+	return true
+}
@@ -0,0 +1,124 @@
+package lockout
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Policy describes how failed attempts against a key are throttled
+type Policy struct {
+	FreeAttempts int           // Failures allowed before backoff starts
+	BaseDelay    time.Duration // Delay after the first failure past FreeAttempts, doubled for each further failure
+	MaxDelay     time.Duration // Upper bound of the backoff delay
+	LockoutAfter int           // Failures that lock the key out; 0 disables lockouts
+	LockoutFor   time.Duration // How long a lockout lasts
+	Window       time.Duration // Failures are forgotten after this long without another failure
+}
+
+// Record tracks the failed attempts against one key, such as an account or an IP address
+type Record struct {
+	Key           string     `bson:"_id"`
+	Failures      int        `bson:"failures"`
+	LastFailureAt time.Time  `bson:"last_failure_at"`
+	LockedUntil   *time.Time `bson:"locked_until,omitempty"`
+}
+
+// RetryAfter returns how long the key must wait before the next attempt, or 0 if an
+// attempt is allowed now
+func (p Policy) RetryAfter(r Record, now time.Time) time.Duration {
+	if r.LockedUntil != nil && now.Before(*r.LockedUntil) {
+		return r.LockedUntil.Sub(now)
+	}
+
+	failures := p.activeFailures(r, now)
+	if failures == 0 {
+		return 0
+	}
+
+	if wait := r.LastFailureAt.Add(p.Backoff(failures)).Sub(now); wait > 0 {
+		return wait
+	}
+	return 0
+}
+
+// Backoff returns the delay required after the given number of consecutive failures
+func (p Policy) Backoff(failures int) time.Duration {
+	excess := failures - p.FreeAttempts
+	if excess <= 0 || p.BaseDelay <= 0 {
+		return 0
+	}
+
+	delay := p.BaseDelay
+	for i := 1; i < excess; i++ {
+		delay *= 2
+		if p.MaxDelay > 0 && delay >= p.MaxDelay {
+			return p.MaxDelay
+		}
+	}
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		return p.MaxDelay
+	}
+	return delay
+}
+
+// Attempt counts an attempt against the key before its outcome is known. It returns the
+// record with the attempt counted and how long the caller must wait before the attempt
+// may go ahead, or 0 if it may go ahead now. Attempts made during a backoff still count,
+// attempts made during a lockout do not, and the attempt that follows LockoutAfter
+// failures locks the key out. A successful attempt is taken back by the caller.
+func (p Policy) Attempt(r Record, now time.Time) (Record, time.Duration) {
+	if r.LockedUntil != nil && now.Before(*r.LockedUntil) {
+		return r, r.LockedUntil.Sub(now)
+	}
+
+	wait := p.RetryAfter(r, now)
+	r.Failures = p.activeFailures(r, now) + 1
+	r.LastFailureAt = now
+
+	if p.LockoutAfter > 0 && r.Failures > p.LockoutAfter {
+		lockedUntil := now.Add(p.LockoutFor)
+		r.LockedUntil = &lockedUntil
+		// The count starts over once the lockout ends
+		r.Failures = 0
+		return r, p.LockoutFor
+	}
+	return r, wait
+}
+
+// AttemptUpdate returns the update pipeline that applies Attempt to a stored record in a
+// single step, so that concurrent attempts are each counted against the record left by
+// the one before. Run it with an upsert and decode the document from before the update.
+func (p Policy) AttemptUpdate(now time.Time) mongo.Pipeline {
+	locked := bson.M{"$gt": bson.A{bson.M{"$ifNull": bson.A{"$locked_until", time.Time{}}}, now}}
+
+	var failures any = bson.M{"$ifNull": bson.A{"$failures", 0}}
+	if p.Window > 0 {
+		stale := bson.M{"$lt": bson.A{bson.M{"$ifNull": bson.A{"$last_failure_at", time.Time{}}}, now.Add(-p.Window)}}
+		failures = bson.M{"$cond": bson.A{stale, 0, failures}}
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{
+			"failures":        bson.M{"$cond": bson.A{locked, "$failures", bson.M{"$add": bson.A{failures, 1}}}},
+			"last_failure_at": bson.M{"$cond": bson.A{locked, "$last_failure_at", now}},
+		}}},
+	}
+	if p.LockoutAfter > 0 {
+		reached := bson.M{"$and": bson.A{bson.M{"$not": bson.A{locked}}, bson.M{"$gt": bson.A{"$failures", p.LockoutAfter}}}}
+		pipeline = append(pipeline, bson.D{{Key: "$set", Value: bson.M{
+			"locked_until": bson.M{"$cond": bson.A{reached, now.Add(p.LockoutFor), "$locked_until"}},
+			"failures":     bson.M{"$cond": bson.A{reached, 0, "$failures"}},
+		}}})
+	}
+	return pipeline
+}
+
+// activeFailures returns the failure count, ignoring failures outside the window
+func (p Policy) activeFailures(r Record, now time.Time) int {
+	if p.Window > 0 && now.Sub(r.LastFailureAt) > p.Window {
+		return 0
+	}
+	return r.Failures
+}
@@ -0,0 +1,100 @@
+package lockout
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var policy = Policy{
+	FreeAttempts: 2,
+	BaseDelay:    time.Second,
+	MaxDelay:     10 * time.Second,
+	LockoutAfter: 8,
+	LockoutFor:   15 * time.Minute,
+	Window:       time.Hour,
+}
+
+func TestPolicy_Backoff(t *testing.T) {
+	tests := []struct {
+		failures int
+		expected time.Duration
+	}{
+		{0, 0},
+		{2, 0},
+		{3, time.Second},
+		{4, 2 * time.Second},
+		{5, 4 * time.Second},
+		{6, 8 * time.Second},
+		{7, 10 * time.Second},
+		{20, 10 * time.Second},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, policy.Backoff(tt.failures), "failures=%d", tt.failures)
+	}
+}
+
+func TestPolicy_RetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	record := Record{Key: "account:ada@example.com", Failures: 4, LastFailureAt: now.Add(-500 * time.Millisecond)}
+	assert.Equal(t, 1500*time.Millisecond, policy.RetryAfter(record, now))
+	assert.Zero(t, policy.RetryAfter(record, now.Add(2*time.Second)))
+
+	// Failures outside the window are forgotten
+	stale := Record{Failures: 7, LastFailureAt: now.Add(-2 * time.Hour)}
+	assert.Zero(t, policy.RetryAfter(stale, now))
+
+	lockedUntil := now.Add(time.Minute)
+	locked := Record{LockedUntil: &lockedUntil}
+	assert.Equal(t, time.Minute, policy.RetryAfter(locked, now))
+}
+
+func TestPolicy_Attempt(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	var record Record
+	var wait time.Duration
+	for i := 0; i < 8; i++ {
+		record, wait = policy.Attempt(record, now.Add(time.Duration(i)*time.Minute))
+		assert.Zero(t, wait, "attempt %d", i+1)
+	}
+	assert.Equal(t, 8, record.Failures)
+	assert.Nil(t, record.LockedUntil)
+
+	// The attempt after the eighth failure locks the key out and is refused
+	next := now.Add(8 * time.Minute)
+	record, wait = policy.Attempt(record, next)
+	require.NotNil(t, record.LockedUntil)
+	assert.Equal(t, next.Add(15*time.Minute), *record.LockedUntil)
+	assert.Equal(t, 15*time.Minute, wait)
+	assert.Equal(t, 0, record.Failures)
+
+	// Attempts during the lockout are refused without being counted
+	locked, wait := policy.Attempt(record, next.Add(time.Minute))
+	assert.Equal(t, record, locked)
+	assert.Equal(t, 14*time.Minute, wait)
+
+	// Attempts during a backoff are refused and counted
+	backingOff := Record{Failures: 3, LastFailureAt: now}
+	record, wait = policy.Attempt(backingOff, now.Add(500*time.Millisecond))
+	assert.Equal(t, 500*time.Millisecond, wait)
+	assert.Equal(t, 4, record.Failures)
+
+	// An attempt after the window starts the count again
+	fresh, wait := policy.Attempt(Record{Failures: 5, LastFailureAt: now.Add(-2 * time.Hour)}, now)
+	assert.Zero(t, wait)
+	assert.Equal(t, 1, fresh.Failures)
+
+	// Lockouts are disabled without a threshold
+	unlimited := Policy{FreeAttempts: 1, BaseDelay: time.Second}
+	record = Record{}
+	for i := 0; i < 50; i++ {
+		record, _ = unlimited.Attempt(record, now)
+	}
+	assert.Nil(t, record.LockedUntil)
+	assert.Equal(t, 50, record.Failures)
+}
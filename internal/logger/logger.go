@@ -3,20 +3,28 @@ package logger
 import (
 	"log/slog"
 	"os"
+
+	"ecommerce-backend/internal/config"
 )
 
-// New creates a new structured logger
-func New() *slog.Logger {
+// New creates a new structured logger for the environment: readable text with debug
+// messages in development, warnings and errors only in tests, and JSON elsewhere
+func New(env config.Environment) *slog.Logger {
 	opts := &slog.HandlerOptions{
 		Level:     slog.LevelInfo,
 		AddSource: true,
 	}
 
-	var handler slog.Handler = slog.NewJSONHandler(os.Stdout, opts)
-
-	// Use text handler for development
-	if os.Getenv("ENV") == "development" {
+	var handler slog.Handler
+	switch env {
+	case config.EnvDevelopment:
+		opts.Level = slog.LevelDebug
+		handler = slog.NewTextHandler(os.Stdout, opts)
+	case config.EnvTest:
+		opts.Level = slog.LevelWarn
 		handler = slog.NewTextHandler(os.Stdout, opts)
+	default:
+		handler = slog.NewJSONHandler(os.Stdout, opts)
 	}
 
 	return slog.New(handler)
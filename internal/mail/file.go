@@ -0,0 +1,41 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// FileSender writes each email to its own file in a directory instead of delivering
+// it. It is meant for development and tests.
+type FileSender struct {
+	from string
+	dir  string
+	seq  atomic.Uint64
+}
+
+// NewFileSender creates a sender that writes emails into dir, creating it if needed
+func NewFileSender(from, dir string) (*FileSender, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create mail directory: %w", err)
+	}
+	return &FileSender{from: from, dir: dir}, nil
+}
+
+// Send writes the message as an .eml file named after the time it was sent
+func (s *FileSender) Send(ctx context.Context, msg Message) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", s.from)
+	fmt.Fprintf(&b, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", msg.Subject)
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	b.WriteString(msg.Body)
+
+	name := fmt.Sprintf("%s-%04d.eml", time.Now().Format("20060102T150405.000000000"), s.seq.Add(1))
+	return os.WriteFile(filepath.Join(s.dir, name), []byte(b.String()), 0o600)
+}
@@ -0,0 +1,42 @@
+package mail
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileSender_Send(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "mail")
+	sender, err := NewFileSender("shop@example.com", dir)
+	require.NoError(t, err)
+
+	require.NoError(t, sender.Send(context.Background(), Message{To: "ada@example.com", Subject: "Hello", Body: "First"}))
+	require.NoError(t, sender.Send(context.Background(), Message{To: "ada@example.com", Subject: "Again", Body: "Second"}))
+
+	files, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+
+	content, err := os.ReadFile(filepath.Join(dir, files[0].Name()))
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "From: shop@example.com\r\n")
+	assert.Contains(t, string(content), "To: ada@example.com\r\n")
+	assert.Contains(t, string(content), "Subject: Hello\r\n")
+	assert.Contains(t, string(content), "\r\n\r\nFirst")
+}
+
+func TestNewSender(t *testing.T) {
+	_, err := NewSender("log", "shop@example.com", "", nil)
+	assert.NoError(t, err)
+
+	_, err = NewSender("file", "shop@example.com", t.TempDir(), nil)
+	assert.NoError(t, err)
+
+	_, err = NewSender("smtp", "shop@example.com", "", nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,54 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+)
+
+// Message is a plain text email
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Sender delivers emails
+type Sender interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// LogSender writes emails to the log instead of delivering them. It is meant for
+// development, where the links in the emails can be copied from the log.
+type LogSender struct {
+	from string
+	log  *slog.Logger
+}
+
+// NewLogSender creates a sender that logs emails
+func NewLogSender(from string, log *slog.Logger) *LogSender {
+	return &LogSender{from: from, log: log}
+}
+
+// Send logs the message
+func (s *LogSender) Send(ctx context.Context, msg Message) error {
+	s.log.InfoContext(ctx, "Email sent",
+		"from", s.from,
+		"to", msg.To,
+		"subject", msg.Subject,
+		"body", msg.Body,
+	)
+	return nil
+}
+
+// NewSender creates the sender for a driver name: "log" or "file"
+func NewSender(driver, from, dir string, log *slog.Logger) (Sender, error) {
+	switch driver {
+	case "log":
+		return NewLogSender(from, log), nil
+	case "file":
+		return NewFileSender(from, dir)
+	default:
+		return nil, fmt.Errorf("unknown mail driver %q", driver)
+	}
+}
@@ -0,0 +1,104 @@
+package mfa
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Base32 of the RFC 6238 SHA1 test key "12345678901234567890"
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestCode(t *testing.T) {
+	tests := []struct {
+		name     string
+		unix     int64
+		expected string
+	}{
+		{name: "59", unix: 59, expected: "287082"},
+		{name: "1111111109", unix: 1111111109, expected: "081804"},
+		{name: "1111111111", unix: 1111111111, expected: "050471"},
+		{name: "1234567890", unix: 1234567890, expected: "005924"},
+		{name: "2000000000", unix: 2000000000, expected: "279037"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, err := Code(rfcSecret, Step(time.Unix(tt.unix, 0)))
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, code)
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+
+	tests := []struct {
+		name  string
+		at    time.Time
+		code  string
+		valid bool
+	}{
+		{name: "current period", at: now, code: "050471", valid: true},
+		{name: "previous period", at: now.Add(Period), code: "050471", valid: true},
+		{name: "next period", at: now.Add(-Period), code: "050471", valid: true},
+		{name: "too old", at: now.Add(2 * Period), code: "050471", valid: false},
+		{name: "wrong code", at: now, code: "123456", valid: false},
+		{name: "wrong length", at: now, code: "05047", valid: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, ok := Validate(rfcSecret, tt.code, tt.at)
+			assert.Equal(t, tt.valid, ok)
+			if tt.valid {
+				assert.Equal(t, Step(now), step)
+			}
+		})
+	}
+}
+
+func TestGenerateSecret(t *testing.T) {
+	secret, err := GenerateSecret()
+	require.NoError(t, err)
+	assert.Len(t, secret, 32)
+
+	code, err := Code(secret, Step(time.Now()))
+	require.NoError(t, err)
+	_, ok := Validate(secret, code, time.Now())
+	assert.True(t, ok)
+}
+
+func TestProvisioningURI(t *testing.T) {
+	uri, err := url.Parse(ProvisioningURI("Shop", "user@example.com", rfcSecret))
+	require.NoError(t, err)
+
+	assert.Equal(t, "otpauth", uri.Scheme)
+	assert.Equal(t, "totp", uri.Host)
+	assert.Equal(t, "/Shop:user@example.com", uri.Path)
+	assert.Equal(t, rfcSecret, uri.Query().Get("secret"))
+	assert.Equal(t, "Shop", uri.Query().Get("issuer"))
+	assert.Equal(t, "6", uri.Query().Get("digits"))
+}
+
+func TestGenerateRecoveryCodes(t *testing.T) {
+	codes, err := GenerateRecoveryCodes()
+	require.NoError(t, err)
+	assert.Len(t, codes, RecoveryCodeCount)
+
+	seen := make(map[string]bool)
+	for _, code := range codes {
+		assert.Len(t, code, 11)
+		assert.Equal(t, "-", code[5:6])
+		assert.Len(t, NormalizeRecoveryCode(code), 10)
+		assert.False(t, seen[code])
+		seen[code] = true
+	}
+
+	assert.Equal(t, NormalizeRecoveryCode(codes[0]), NormalizeRecoveryCode(" "+strings.ToUpper(codes[0])))
+}
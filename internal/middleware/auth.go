@@ -1,61 +1,215 @@
 package middleware
 
 import (
-	"net/http"
+	"context"
 	"strings"
 
+	"ecommerce-backend/internal/apikeys"
+	apperrors "ecommerce-backend/internal/errors"
+	"ecommerce-backend/internal/models"
 	"ecommerce-backend/internal/utils"
 
 	"github.com/gin-gonic/gin"
 )
 
-// AuthMiddleware validates JWT tokens
-func AuthMiddleware(jwtManager *utils.JWTManager) gin.HandlerFunc {
+// SessionChecker reports whether the session a token belongs to is still active
+type SessionChecker interface {
+	IsActive(ctx context.Context, sessionID string) (bool, error)
+}
+
+// APIKeyAuthenticator returns the active API key matching a presented key
+type APIKeyAuthenticator interface {
+	Authenticate(ctx context.Context, key, ip string) (*models.APIKey, error)
+}
+
+// apiKeyRole is the role of requests made with an API key, which hold only the key's permissions
+const apiKeyRole = "api_key"
+
+// AuthMiddleware validates JWT tokens and rejects tokens whose session was revoked.
+// Requests already authenticated by APIKeyMiddleware pass through.
+func AuthMiddleware(jwtManager *utils.JWTManager, sessions SessionChecker) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		if c.GetString("api_key_id") != "" {
+			c.Next()
+			return
+		}
+
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header required"})
+			c.Error(apperrors.Unauthorized("Authorization header required"))
 			c.Abort()
 			return
 		}
 
 		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
 		if tokenString == authHeader {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid authorization header format"})
+			c.Error(apperrors.Unauthorized("Invalid authorization header format"))
 			c.Abort()
 			return
 		}
 
 		claims, err := jwtManager.ValidateToken(tokenString)
 		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+			c.Error(apperrors.Unauthorized("Invalid token"))
+			c.Abort()
+			return
+		}
+
+		active, err := sessions.IsActive(c.Request.Context(), claims.ID)
+		if err != nil {
+			c.Error(apperrors.Internal("Failed to check session", err))
+			c.Abort()
+			return
+		}
+		if !active {
+			c.Error(apperrors.Unauthorized("Session has been revoked"))
 			c.Abort()
 			return
 		}
 
+		c.Set("session_id", claims.ID)
 		c.Set("user_id", claims.UserID)
 		c.Set("user_email", claims.Email)
 		c.Set("user_role", claims.Role)
+		c.Set("permissions", claims.Permissions)
+		c.Set("mfa", claims.MFA)
+		c.Next()
+	}
+}
+
+// APIKeyMiddleware authenticates requests sending an API key, as "Authorization: ApiKey <key>"
+// or in the X-API-Key header, as the admin who issued the key with only the key's
+// permissions. Requests without a key are left to AuthMiddleware.
+func APIKeyMiddleware(keys APIKeyAuthenticator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := apiKeyFromRequest(c)
+		if key == "" {
+			c.Next()
+			return
+		}
+
+		apiKey, err := keys.Authenticate(c.Request.Context(), key, c.ClientIP())
+		if err == apikeys.ErrInvalidKey {
+			c.Error(apperrors.Unauthorized("Invalid API key"))
+			c.Abort()
+			return
+		}
+		if err != nil {
+			c.Error(apperrors.Internal("Failed to check API key", err))
+			c.Abort()
+			return
+		}
+
+		permissions := make([]string, 0, len(apiKey.Permissions))
+		for _, permission := range apiKey.Permissions {
+			permissions = append(permissions, string(permission))
+		}
+
+		c.Set("api_key_id", apiKey.ID.Hex())
+		c.Set("user_id", apiKey.CreatedBy.Hex())
+		c.Set("user_role", apiKeyRole)
+		c.Set("permissions", permissions)
+		// Keys are issued from admin sessions, which already passed any two-factor requirement
+		c.Set("mfa", true)
+		c.Next()
+	}
+}
+
+// OptionalAuthMiddleware identifies the user when a valid token is sent, but lets
+// anonymous requests through
+func OptionalAuthMiddleware(jwtManager *utils.JWTManager, sessions SessionChecker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tokenString := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if tokenString != "" {
+			claims, err := jwtManager.ValidateToken(tokenString)
+			if err == nil {
+				if active, err := sessions.IsActive(c.Request.Context(), claims.ID); err != nil || !active {
+					claims = nil
+				}
+			}
+			if claims != nil {
+				c.Set("session_id", claims.ID)
+				c.Set("user_id", claims.UserID)
+				c.Set("user_email", claims.Email)
+				c.Set("user_role", claims.Role)
+				c.Set("permissions", claims.Permissions)
+				c.Set("mfa", claims.MFA)
+			}
+		}
 		c.Next()
 	}
 }
 
-// AdminMiddleware ensures the user has admin role
-func AdminMiddleware() gin.HandlerFunc {
+// AdminMiddleware ensures the user is an admin or staff member, i.e. holds at least one
+// permission. With requireMFA, the session must also have been started with two-factor
+// authentication.
+func AdminMiddleware(requireMFA bool) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		role, exists := c.Get("user_role")
 		if !exists {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "User role not found"})
+			c.Error(apperrors.Unauthorized("User role not found"))
+			c.Abort()
+			return
+		}
+
+		if role != string(models.RoleAdmin) && len(c.GetStringSlice("permissions")) == 0 {
+			c.Error(apperrors.Forbidden("Admin access required"))
+			c.Abort()
+			return
+		}
+
+		if requireMFA && !c.GetBool("mfa") {
+			c.Error(apperrors.Forbidden("Two-factor authentication is required for admin access"))
 			c.Abort()
 			return
 		}
 
-		if role != "admin" {
-			c.JSON(http.StatusForbidden, gin.H{"error": "Admin access required"})
+		c.Next()
+	}
+}
+
+// RequirePermission ensures the user holds all of the permissions
+func RequirePermission(permissions ...models.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, exists := c.Get("user_role"); !exists {
+			c.Error(apperrors.Unauthorized("User role not found"))
 			c.Abort()
 			return
 		}
 
+		for _, permission := range permissions {
+			if !HasPermission(c, permission) {
+				c.Error(apperrors.Forbidden("Insufficient permissions"))
+				c.Abort()
+				return
+			}
+		}
+
 		c.Next()
 	}
 }
+
+// HasPermission reports whether the authenticated user holds the permission. Admins
+// hold every permission, including ones added after their token was issued.
+func HasPermission(c *gin.Context, permission models.Permission) bool {
+	if c.GetString("user_role") == string(models.RoleAdmin) {
+		return true
+	}
+	for _, granted := range c.GetStringSlice("permissions") {
+		if granted == string(permission) {
+			return true
+		}
+	}
+	return false
+}
+
+// apiKeyFromRequest returns the API key sent with the request, or "" if there is none
+func apiKeyFromRequest(c *gin.Context) string {
+	if key := c.GetHeader("X-API-Key"); key != "" {
+		return strings.TrimSpace(key)
+	}
+	if key, ok := strings.CutPrefix(c.GetHeader("Authorization"), "ApiKey "); ok {
+		return strings.TrimSpace(key)
+	}
+	return ""
+}
@@ -0,0 +1,181 @@
+package middleware
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"ecommerce-backend/internal/apikeys"
+	"ecommerce-backend/internal/config"
+	"ecommerce-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestAdminMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		role           string
+		permissions    []string
+		mfa            bool
+		requireMFA     bool
+		expectedStatus int
+	}{
+		{name: "admin", role: "admin", expectedStatus: http.StatusOK},
+		{name: "user", role: "user", expectedStatus: http.StatusForbidden},
+		{name: "no role", expectedStatus: http.StatusUnauthorized},
+		{name: "staff", role: "support", permissions: []string{"returns:manage"}, expectedStatus: http.StatusOK},
+		{name: "admin without mfa when required", role: "admin", requireMFA: true, expectedStatus: http.StatusForbidden},
+		{name: "admin with mfa when required", role: "admin", mfa: true, requireMFA: true, expectedStatus: http.StatusOK},
+		{name: "user with mfa when required", role: "user", mfa: true, requireMFA: true, expectedStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(ErrorHandler(config.EnvTest, slog.Default()))
+			router.Use(func(c *gin.Context) {
+				if tt.role != "" {
+					c.Set("user_role", tt.role)
+					c.Set("permissions", tt.permissions)
+					c.Set("mfa", tt.mfa)
+				}
+			})
+			router.Use(AdminMiddleware(tt.requireMFA))
+			router.GET("/admin", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin", nil))
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
+
+func TestRequirePermission(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		role           string
+		permissions    []string
+		required       []models.Permission
+		expectedStatus int
+	}{
+		{name: "granted", role: "editor", permissions: []string{"products:write"}, required: []models.Permission{models.PermProductsWrite}, expectedStatus: http.StatusOK},
+		{name: "missing", role: "editor", permissions: []string{"products:write"}, required: []models.Permission{models.PermOrdersRefund}, expectedStatus: http.StatusForbidden},
+		{name: "needs all", role: "editor", permissions: []string{"products:write"}, required: []models.Permission{models.PermProductsWrite, models.PermSlidersWrite}, expectedStatus: http.StatusForbidden},
+		{name: "admin has every permission", role: "admin", required: []models.Permission{models.PermRolesManage}, expectedStatus: http.StatusOK},
+		{name: "user", role: "user", required: []models.Permission{models.PermProductsWrite}, expectedStatus: http.StatusForbidden},
+		{name: "no role", required: []models.Permission{models.PermProductsWrite}, expectedStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(ErrorHandler(config.EnvTest, slog.Default()))
+			router.Use(func(c *gin.Context) {
+				if tt.role != "" {
+					c.Set("user_role", tt.role)
+					c.Set("permissions", tt.permissions)
+				}
+			})
+			router.Use(RequirePermission(tt.required...))
+			router.GET("/admin", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin", nil))
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
+
+// fakeAPIKeys accepts a single key
+type fakeAPIKeys struct {
+	key    string
+	apiKey models.APIKey
+}
+
+func (f fakeAPIKeys) Authenticate(ctx context.Context, key, ip string) (*models.APIKey, error) {
+	if key != f.key {
+		return nil, apikeys.ErrInvalidKey
+	}
+	return &f.apiKey, nil
+}
+
+func TestAPIKeyMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	issuer := primitive.NewObjectID()
+	keys := fakeAPIKeys{
+		key: "ek_valid",
+		apiKey: models.APIKey{
+			ID:          primitive.NewObjectID(),
+			CreatedBy:   issuer,
+			Permissions: []models.Permission{models.PermProductsWrite},
+		},
+	}
+
+	tests := []struct {
+		name           string
+		headers        map[string]string
+		expectedStatus int
+		authenticated  bool
+	}{
+		{name: "X-API-Key header", headers: map[string]string{"X-API-Key": "ek_valid"}, expectedStatus: http.StatusOK, authenticated: true},
+		{name: "ApiKey authorization", headers: map[string]string{"Authorization": "ApiKey ek_valid"}, expectedStatus: http.StatusOK, authenticated: true},
+		{name: "invalid key", headers: map[string]string{"X-API-Key": "ek_other"}, expectedStatus: http.StatusUnauthorized},
+		{name: "bearer token left to AuthMiddleware", headers: map[string]string{"Authorization": "Bearer token"}, expectedStatus: http.StatusOK},
+		{name: "no credentials", expectedStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(ErrorHandler(config.EnvTest, slog.Default()))
+			router.Use(APIKeyMiddleware(keys))
+			var authenticated bool
+			router.GET("/admin/products", func(c *gin.Context) {
+				authenticated = c.GetString("api_key_id") != ""
+				if authenticated {
+					assert.Equal(t, issuer.Hex(), c.GetString("user_id"))
+					assert.True(t, HasPermission(c, models.PermProductsWrite))
+					assert.False(t, HasPermission(c, models.PermUsersManage))
+				}
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/admin/products", nil)
+			for key, value := range tt.headers {
+				req.Header.Set(key, value)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, tt.authenticated, authenticated)
+		})
+	}
+}
+
+func TestAuthMiddleware_AcceptsAPIKeyRequests(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(ErrorHandler(config.EnvTest, slog.Default()))
+	router.Use(func(c *gin.Context) { c.Set("api_key_id", "key") })
+	router.Use(AuthMiddleware(nil, nil))
+	router.GET("/admin", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
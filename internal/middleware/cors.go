@@ -1,22 +1,170 @@
 package middleware
 
 import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"ecommerce-backend/internal/config"
+	apperrors "ecommerce-backend/internal/errors"
+
 	"github.com/gin-gonic/gin"
 )
 
-// CORS returns a CORS middleware
-func CORS() gin.HandlerFunc {
+// CORS returns a CORS middleware that allows the configured origins. Allowed origins
+// are echoed back, except that an allowed origin of "*" is answered with a literal "*"
+// and never with credentials, so that any site cannot make credentialed requests.
+// Preflights from other origins, or asking for methods or headers that are not allowed,
+// are rejected.
+func CORS(cfg config.CORSConfig) gin.HandlerFunc {
+	origins := newOriginMatcher(cfg.AllowedOrigins)
+	credentials := cfg.AllowCredentials && !origins.any
+	methods := strings.Join(cfg.AllowedMethods, ", ")
+	headers := strings.Join(cfg.AllowedHeaders, ", ")
+	exposed := strings.Join(cfg.ExposedHeaders, ", ")
+	maxAge := strconv.Itoa(int(cfg.MaxAge.Seconds()))
+
 	return func(c *gin.Context) {
-		c.Header("Access-Control-Allow-Origin", "*")
-		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization")
-		c.Header("Access-Control-Allow-Credentials", "true")
+		origin := c.GetHeader("Origin")
+		if origin == "" {
+			c.Next()
+			return
+		}
 
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(204)
+		// The response depends on the origin, so caches must not share it between origins
+		c.Writer.Header().Add("Vary", "Origin")
+		allowed := origins.matches(origin)
+		allowOrigin := origin
+		if origins.any {
+			allowOrigin = "*"
+		}
+
+		preflight := c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != ""
+		if preflight {
+			c.Writer.Header().Add("Vary", "Access-Control-Request-Method")
+			c.Writer.Header().Add("Vary", "Access-Control-Request-Headers")
+
+			if !allowed ||
+				!containsFold(cfg.AllowedMethods, c.GetHeader("Access-Control-Request-Method")) ||
+				!allHeadersAllowed(cfg.AllowedHeaders, c.GetHeader("Access-Control-Request-Headers")) {
+				c.Error(apperrors.Forbidden("CORS preflight not allowed"))
+				c.Abort()
+				return
+			}
+
+			c.Header("Access-Control-Allow-Origin", allowOrigin)
+			if credentials {
+				c.Header("Access-Control-Allow-Credentials", "true")
+			}
+			c.Header("Access-Control-Allow-Methods", methods)
+			if headers != "" {
+				c.Header("Access-Control-Allow-Headers", headers)
+			}
+			if cfg.MaxAge > 0 {
+				c.Header("Access-Control-Max-Age", maxAge)
+			}
+			c.AbortWithStatus(http.StatusNoContent)
 			return
 		}
 
+		// Requests from other origins are served without CORS headers, so browsers
+		// do not let the page read the response
+		if allowed {
+			c.Header("Access-Control-Allow-Origin", allowOrigin)
+			if credentials {
+				c.Header("Access-Control-Allow-Credentials", "true")
+			}
+			if exposed != "" {
+				c.Header("Access-Control-Expose-Headers", exposed)
+			}
+		}
+
 		c.Next()
 	}
 }
+
+// originMatcher matches origins against exact origins and wildcard subdomain patterns
+// such as "https://*.example.com"
+type originMatcher struct {
+	any       bool
+	exact     map[string]bool
+	wildcards []wildcardOrigin
+}
+
+// wildcardOrigin is a pattern split around its "*."
+type wildcardOrigin struct {
+	prefix string // Scheme, e.g. "https://"
+	suffix string // Parent domain and port, e.g. ".example.com"
+}
+
+func newOriginMatcher(patterns []string) originMatcher {
+	m := originMatcher{exact: make(map[string]bool)}
+	for _, pattern := range patterns {
+		pattern = strings.ToLower(strings.TrimSuffix(strings.TrimSpace(pattern), "/"))
+		switch {
+		case pattern == "*":
+			m.any = true
+		case strings.Contains(pattern, "://*."):
+			i := strings.Index(pattern, "*.")
+			m.wildcards = append(m.wildcards, wildcardOrigin{prefix: pattern[:i], suffix: pattern[i+1:]})
+		case pattern != "":
+			m.exact[pattern] = true
+		}
+	}
+	return m
+}
+
+func (m originMatcher) matches(origin string) bool {
+	origin = strings.ToLower(origin)
+	if m.any || m.exact[origin] {
+		return true
+	}
+	for _, w := range m.wildcards {
+		if !strings.HasPrefix(origin, w.prefix) || !strings.HasSuffix(origin, w.suffix) {
+			continue
+		}
+		subdomain := origin[len(w.prefix) : len(origin)-len(w.suffix)]
+		if isHostLabels(subdomain) {
+			return true
+		}
+	}
+	return false
+}
+
+// isHostLabels reports whether s is one or more dot separated host name labels
+func isHostLabels(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, label := range strings.Split(s, ".") {
+		if label == "" {
+			return false
+		}
+		for _, r := range label {
+			if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-') {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// allHeadersAllowed checks a comma separated Access-Control-Request-Headers value
+func allHeadersAllowed(allowed []string, requested string) bool {
+	for _, header := range strings.Split(requested, ",") {
+		header = strings.TrimSpace(header)
+		if header != "" && !containsFold(allowed, header) {
+			return false
+		}
+	}
+	return true
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
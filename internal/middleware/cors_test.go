@@ -0,0 +1,123 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"ecommerce-backend/internal/config"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCORS(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cfg := config.CORSConfig{
+		AllowedOrigins:   []string{"https://shop.example.com", "https://*.example.org"},
+		AllowedMethods:   []string{"GET", "POST", "OPTIONS"},
+		AllowedHeaders:   []string{"Content-Type", "Authorization"},
+		ExposedHeaders:   []string{"X-Request-ID"},
+		AllowCredentials: true,
+		MaxAge:           time.Hour,
+	}
+
+	tests := []struct {
+		name           string
+		method         string
+		headers        map[string]string
+		expectedStatus int
+		expectedOrigin string
+	}{
+		{name: "no origin", method: http.MethodGet, expectedStatus: http.StatusOK},
+		{name: "exact origin", method: http.MethodGet, headers: map[string]string{"Origin": "https://shop.example.com"}, expectedStatus: http.StatusOK, expectedOrigin: "https://shop.example.com"},
+		{name: "wildcard subdomain", method: http.MethodGet, headers: map[string]string{"Origin": "https://admin.eu.example.org"}, expectedStatus: http.StatusOK, expectedOrigin: "https://admin.eu.example.org"},
+		{name: "wildcard does not match the parent domain", method: http.MethodGet, headers: map[string]string{"Origin": "https://example.org"}, expectedStatus: http.StatusOK},
+		{name: "wildcard does not match a lookalike domain", method: http.MethodGet, headers: map[string]string{"Origin": "https://evil-example.org"}, expectedStatus: http.StatusOK},
+		{name: "wildcard checks the scheme", method: http.MethodGet, headers: map[string]string{"Origin": "http://admin.example.org"}, expectedStatus: http.StatusOK},
+		{name: "other origin", method: http.MethodGet, headers: map[string]string{"Origin": "https://evil.com"}, expectedStatus: http.StatusOK},
+		{
+			name:           "preflight",
+			method:         http.MethodOptions,
+			headers:        map[string]string{"Origin": "https://shop.example.com", "Access-Control-Request-Method": "POST", "Access-Control-Request-Headers": "content-type, authorization"},
+			expectedStatus: http.StatusNoContent,
+			expectedOrigin: "https://shop.example.com",
+		},
+		{
+			name:           "preflight from other origin",
+			method:         http.MethodOptions,
+			headers:        map[string]string{"Origin": "https://evil.com", "Access-Control-Request-Method": "POST"},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "preflight for disallowed method",
+			method:         http.MethodOptions,
+			headers:        map[string]string{"Origin": "https://shop.example.com", "Access-Control-Request-Method": "DELETE"},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "preflight for disallowed header",
+			method:         http.MethodOptions,
+			headers:        map[string]string{"Origin": "https://shop.example.com", "Access-Control-Request-Method": "POST", "Access-Control-Request-Headers": "X-Custom"},
+			expectedStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(ErrorHandler(config.EnvTest, slog.Default()))
+			router.Use(CORS(cfg))
+			router.GET("/", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+			req := httptest.NewRequest(tt.method, "/", nil)
+			for key, value := range tt.headers {
+				req.Header.Set(key, value)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusForbidden {
+				assert.Contains(t, w.Body.String(), `"error"`)
+			}
+			assert.Equal(t, tt.expectedOrigin, w.Header().Get("Access-Control-Allow-Origin"))
+			if tt.headers["Origin"] != "" {
+				assert.Contains(t, w.Header().Values("Vary"), "Origin")
+			}
+			if tt.expectedOrigin != "" {
+				assert.Equal(t, "true", w.Header().Get("Access-Control-Allow-Credentials"))
+			}
+			if tt.expectedStatus == http.StatusNoContent {
+				assert.Equal(t, "GET, POST, OPTIONS", w.Header().Get("Access-Control-Allow-Methods"))
+				assert.Equal(t, "Content-Type, Authorization", w.Header().Get("Access-Control-Allow-Headers"))
+				assert.Equal(t, "3600", w.Header().Get("Access-Control-Max-Age"))
+			} else if tt.expectedOrigin != "" {
+				assert.Equal(t, "X-Request-ID", w.Header().Get("Access-Control-Expose-Headers"))
+			}
+		})
+	}
+}
+
+func TestCORS_AnyOrigin(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(CORS(config.CORSConfig{
+		AllowedOrigins:   []string{"*"},
+		AllowedMethods:   []string{"GET"},
+		AllowCredentials: true,
+	}))
+	router.GET("/", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Origin", "https://evil.com")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Credentials"))
+}
@@ -0,0 +1,90 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+
+	"ecommerce-backend/internal/config"
+	apperrors "ecommerce-backend/internal/errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ProblemContentType is the RFC 7807 media type clients can ask for in Accept
+const ProblemContentType = "application/problem+json"
+
+// errorBody is the default error envelope, sent as {"error": {...}}
+type errorBody struct {
+	Code      string                 `json:"code"`
+	Message   string                 `json:"message"`
+	Details   []apperrors.FieldError `json:"details,omitempty"`
+	RequestID string                 `json:"request_id,omitempty"`
+	Cause     string                 `json:"cause,omitempty"`
+}
+
+// problemBody is the RFC 7807 rendering of the same error
+type problemBody struct {
+	Type      string                 `json:"type"`
+	Title     string                 `json:"title"`
+	Status    int                    `json:"status"`
+	Detail    string                 `json:"detail"`
+	Instance  string                 `json:"instance"`
+	Code      string                 `json:"code"`
+	Errors    []apperrors.FieldError `json:"errors,omitempty"`
+	RequestID string                 `json:"request_id,omitempty"`
+	Cause     string                 `json:"cause,omitempty"`
+}
+
+// ErrorHandler renders the last error a handler recorded with c.Error. Errors that are
+// not an AppError become a 500, and the underlying cause of an error is only included
+// in environments that expose errors.
+func ErrorHandler(env config.Environment, logger *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+
+		appErr := apperrors.As(c.Errors.Last().Err)
+		requestID := c.GetString("request_id")
+		if appErr.Status >= http.StatusInternalServerError {
+			logger.Error("Request failed",
+				"status", appErr.Status,
+				"method", c.Request.Method,
+				"path", c.Request.URL.Path,
+				"request_id", requestID,
+				"error", appErr,
+			)
+		}
+
+		var cause string
+		if env.ExposeErrors() && appErr.Err != nil {
+			cause = appErr.Err.Error()
+		}
+
+		if c.NegotiateFormat(gin.MIMEJSON, ProblemContentType) == ProblemContentType {
+			c.Header("Content-Type", ProblemContentType)
+			c.JSON(appErr.Status, problemBody{
+				Type:      "about:blank",
+				Title:     http.StatusText(appErr.Status),
+				Status:    appErr.Status,
+				Detail:    appErr.Message,
+				Instance:  c.Request.URL.Path,
+				Code:      appErr.Code,
+				Errors:    appErr.Details,
+				RequestID: requestID,
+				Cause:     cause,
+			})
+			return
+		}
+
+		c.JSON(appErr.Status, gin.H{"error": errorBody{
+			Code:      appErr.Code,
+			Message:   appErr.Message,
+			Details:   appErr.Details,
+			RequestID: requestID,
+			Cause:     cause,
+		}})
+	}
+}
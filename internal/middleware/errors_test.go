@@ -0,0 +1,135 @@
+package middleware
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"ecommerce-backend/internal/config"
+	apperrors "ecommerce-backend/internal/errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrorHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	validationErr := apperrors.ErrValidation.Wrap(errors.New("binding failed"))
+	validationErr.Details = []apperrors.FieldError{{Field: "email", Rule: "required", Message: "is required"}}
+
+	tests := []struct {
+		name           string
+		env            config.Environment
+		accept         string
+		err            error
+		expectedStatus int
+		expectedType   string
+		expectedBody   string
+	}{
+		{
+			name:           "envelope",
+			env:            config.EnvProduction,
+			err:            apperrors.NotFound("Product not found"),
+			expectedStatus: http.StatusNotFound,
+			expectedType:   "application/json; charset=utf-8",
+			expectedBody:   `{"error":{"code":"not_found","message":"Product not found","request_id":"req-1"}}`,
+		},
+		{
+			name:           "validation details",
+			env:            config.EnvProduction,
+			err:            validationErr,
+			expectedStatus: http.StatusBadRequest,
+			expectedType:   "application/json; charset=utf-8",
+			expectedBody:   `{"error":{"code":"validation_failed","message":"Validation error","details":[{"field":"email","rule":"required","message":"is required"}],"request_id":"req-1"}}`,
+		},
+		{
+			name:           "cause hidden in production",
+			env:            config.EnvProduction,
+			err:            apperrors.Internal("Failed to fetch products", errors.New("connection refused")),
+			expectedStatus: http.StatusInternalServerError,
+			expectedType:   "application/json; charset=utf-8",
+			expectedBody:   `{"error":{"code":"internal_server_error","message":"Failed to fetch products","request_id":"req-1"}}`,
+		},
+		{
+			name:           "cause exposed in development",
+			env:            config.EnvDevelopment,
+			err:            apperrors.Internal("Failed to fetch products", errors.New("connection refused")),
+			expectedStatus: http.StatusInternalServerError,
+			expectedType:   "application/json; charset=utf-8",
+			expectedBody:   `{"error":{"code":"internal_server_error","message":"Failed to fetch products","request_id":"req-1","cause":"connection refused"}}`,
+		},
+		{
+			name:           "untyped error",
+			env:            config.EnvProduction,
+			err:            errors.New("boom"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedType:   "application/json; charset=utf-8",
+			expectedBody:   `{"error":{"code":"internal_server_error","message":"Internal server error","request_id":"req-1"}}`,
+		},
+		{
+			name:           "problem details",
+			env:            config.EnvProduction,
+			accept:         "application/problem+json",
+			err:            validationErr,
+			expectedStatus: http.StatusBadRequest,
+			expectedType:   ProblemContentType,
+			expectedBody:   `{"type":"about:blank","title":"Bad Request","status":400,"detail":"Validation error","instance":"/test","code":"validation_failed","errors":[{"field":"email","rule":"required","message":"is required"}],"request_id":"req-1"}`,
+		},
+		{
+			name:           "json preferred over problem details",
+			env:            config.EnvProduction,
+			accept:         "application/json, application/problem+json",
+			err:            apperrors.Forbidden("Insufficient permissions"),
+			expectedStatus: http.StatusForbidden,
+			expectedType:   "application/json; charset=utf-8",
+			expectedBody:   `{"error":{"code":"forbidden","message":"Insufficient permissions","request_id":"req-1"}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(func(c *gin.Context) { c.Set("request_id", "req-1") })
+			router.Use(ErrorHandler(tt.env, slog.New(slog.NewTextHandler(io.Discard, nil))))
+			router.GET("/test", func(c *gin.Context) {
+				c.Error(tt.err)
+			})
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/test", nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, tt.expectedType, w.Header().Get("Content-Type"))
+			assert.JSONEq(t, tt.expectedBody, w.Body.String())
+		})
+	}
+}
+
+func TestErrorHandler_LeavesWrittenResponses(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(ErrorHandler(config.EnvProduction, slog.Default()))
+	router.GET("/test", func(c *gin.Context) {
+		c.Error(errors.New("already handled"))
+		c.JSON(http.StatusAccepted, gin.H{"message": "Queued"})
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/test", nil))
+
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	var body map[string]string
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, "Queued", body["message"])
+}
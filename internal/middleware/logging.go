@@ -35,6 +35,7 @@ func LoggingMiddleware(logger *slog.Logger) gin.HandlerFunc {
 			"method", method,
 			"path", path,
 			"body_size", bodySize,
+			"request_id", c.GetString("request_id"),
 		)
 	}
 }
@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"math"
+	"strconv"
+	"time"
+
+	apperrors "ecommerce-backend/internal/errors"
+	"ecommerce-backend/internal/ratelimit"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimitKey identifies who a request counts against
+type RateLimitKey func(c *gin.Context) string
+
+// ByIP counts requests against the client IP
+func ByIP(c *gin.Context) string {
+	return "ip:" + c.ClientIP()
+}
+
+// ByUser counts requests against the API key or signed-in user, falling back to the
+// client IP for anonymous requests. It must run after the auth middleware.
+func ByUser(c *gin.Context) string {
+	if id := c.GetString("api_key_id"); id != "" {
+		return "key:" + id
+	}
+	if id := c.GetString("user_id"); id != "" {
+		return "user:" + id
+	}
+	return ByIP(c)
+}
+
+// RateLimit limits requests with a token bucket per key and reports the limit in
+// X-RateLimit-* headers. Requests are let through if the store fails, so an outage of a
+// shared store does not take the API down with it.
+func RateLimit(store ratelimit.Store, policy ratelimit.Policy, key RateLimitKey) gin.HandlerFunc {
+	if !policy.Enabled() {
+		return func(c *gin.Context) {
+			c.Next()
+		}
+	}
+
+	return func(c *gin.Context) {
+		result, err := store.Take(c.Request.Context(), policy.Name+":"+key(c), policy)
+		if err != nil {
+			c.Next()
+			return
+		}
+
+		c.Header("X-RateLimit-Limit", strconv.Itoa(result.Limit))
+		c.Header("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		c.Header("X-RateLimit-Reset", strconv.Itoa(ceilSeconds(result.Reset)))
+
+		if !result.Allowed {
+			c.Header("Retry-After", strconv.Itoa(ceilSeconds(result.RetryAfter)))
+			c.Error(apperrors.TooManyRequests("Too many requests, try again later"))
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
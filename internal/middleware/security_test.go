@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"ecommerce-backend/internal/config"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSecurityHeaders(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name     string
+		cfg      config.SecurityConfig
+		expected map[string]string
+	}{
+		{
+			name: "all headers",
+			cfg: config.SecurityConfig{
+				HSTSMaxAge:            365 * 24 * time.Hour,
+				HSTSIncludeSubdomains: true,
+				ReferrerPolicy:        "no-referrer",
+				FrameOptions:          "DENY",
+				ContentSecurityPolicy: "default-src 'none'",
+			},
+			expected: map[string]string{
+				"X-Content-Type-Options":    "nosniff",
+				"Strict-Transport-Security": "max-age=31536000; includeSubDomains",
+				"Referrer-Policy":           "no-referrer",
+				"X-Frame-Options":           "DENY",
+				"Content-Security-Policy":   "default-src 'none'",
+			},
+		},
+		{
+			name: "empty settings are left out",
+			cfg:  config.SecurityConfig{},
+			expected: map[string]string{
+				"X-Content-Type-Options":    "nosniff",
+				"Strict-Transport-Security": "",
+				"Referrer-Policy":           "",
+				"X-Frame-Options":           "",
+				"Content-Security-Policy":   "",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(SecurityHeaders(tt.cfg))
+			router.GET("/", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+			for header, value := range tt.expected {
+				assert.Equal(t, value, w.Header().Get(header), header)
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"ecommerce-backend/internal/database"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Migration is a one-time data change applied at startup
+type Migration struct {
+	ID  string
+	Run func(ctx context.Context, db *database.Client) error
+}
+
+// appliedMigration records a migration in the migrations collection
+type appliedMigration struct {
+	ID        string    `bson:"_id"`
+	AppliedAt time.Time `bson:"applied_at"`
+}
+
+// Run applies, in order, the migrations not yet recorded in the migrations collection.
+// Migrations must be idempotent: two instances starting together may both run one.
+func Run(ctx context.Context, db *database.Client, log *slog.Logger, migrations ...Migration) error {
+	collection := db.GetCollection("migrations")
+
+	for _, migration := range migrations {
+		count, err := collection.CountDocuments(ctx, bson.M{"_id": migration.ID})
+		if err != nil {
+			return fmt.Errorf("failed to check migration %s: %w", migration.ID, err)
+		}
+		if count > 0 {
+			continue
+		}
+
+		if err := migration.Run(ctx, db); err != nil {
+			return fmt.Errorf("migration %s failed: %w", migration.ID, err)
+		}
+
+		_, err = collection.InsertOne(ctx, appliedMigration{ID: migration.ID, AppliedAt: time.Now()})
+		if err != nil && !mongo.IsDuplicateKeyError(err) {
+			return fmt.Errorf("failed to record migration %s: %w", migration.ID, err)
+		}
+		log.Info("Applied migration", "id", migration.ID)
+	}
+
+	return nil
+}
@@ -0,0 +1,58 @@
+package migrations
+
+import (
+	"context"
+
+	"ecommerce-backend/internal/database"
+	"ecommerce-backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// OrderReturnedQuantities builds the per-line returned quantities of orders from the returns
+// requested before they were claimed on the order
+func OrderReturnedQuantities() Migration {
+	return Migration{
+		ID: "0006_order_returned_quantities",
+		Run: func(ctx context.Context, db *database.Client) error {
+			pipeline := mongo.Pipeline{
+				{{Key: "$match", Value: bson.M{"status": bson.M{"$ne": models.ReturnStatusRejected}}}},
+				{{Key: "$unwind", Value: "$items"}},
+				{{Key: "$group", Value: bson.M{
+					"_id":      bson.D{{Key: "order_id", Value: "$order_id"}, {Key: "product_id", Value: "$items.product_id"}},
+					"quantity": bson.M{"$sum": "$items.quantity"},
+				}}},
+			}
+			cursor, err := db.GetCollection("returns").Aggregate(ctx, pipeline)
+			if err != nil {
+				return err
+			}
+			defer cursor.Close(ctx)
+
+			var lines []struct {
+				ID struct {
+					OrderID   primitive.ObjectID `bson:"order_id"`
+					ProductID primitive.ObjectID `bson:"product_id"`
+				} `bson:"_id"`
+				Quantity int `bson:"quantity"`
+			}
+			if err := cursor.All(ctx, &lines); err != nil {
+				return err
+			}
+
+			orders := db.GetCollection("orders")
+			for _, line := range lines {
+				_, err := orders.UpdateOne(ctx,
+					bson.M{"_id": line.ID.OrderID, "items.product_id": line.ID.ProductID},
+					bson.M{"$set": bson.M{"items.$.returned_quantity": line.Quantity}},
+				)
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+}
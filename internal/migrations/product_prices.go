@@ -0,0 +1,68 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+
+	"ecommerce-backend/internal/database"
+	"ecommerce-backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ProductPricesToMoney converts product prices stored as plain numbers in major units
+// into Money subdocuments in the store currency
+func ProductPricesToMoney(currency string) Migration {
+	return Migration{
+		ID: "0001_product_prices_to_money",
+		Run: func(ctx context.Context, db *database.Client) error {
+			collection := db.GetCollection("products")
+
+			cursor, err := collection.Find(ctx, bson.M{"price": bson.M{"$type": "number"}})
+			if err != nil {
+				return err
+			}
+			defer cursor.Close(ctx)
+
+			for cursor.Next(ctx) {
+				var product struct {
+					ID    primitive.ObjectID `bson:"_id"`
+					Price bson.RawValue      `bson:"price"`
+				}
+				if err := cursor.Decode(&product); err != nil {
+					return err
+				}
+
+				price, err := toMoney(product.Price, currency)
+				if err != nil {
+					return fmt.Errorf("product %s: %w", product.ID.Hex(), err)
+				}
+
+				// Only rewrite the price if it was not changed since it was read
+				filter := bson.M{"_id": product.ID, "price": product.Price}
+				if _, err := collection.UpdateOne(ctx, filter, bson.M{"$set": bson.M{"price": price}}); err != nil {
+					return err
+				}
+			}
+			return cursor.Err()
+		},
+	}
+}
+
+// toMoney converts a numeric BSON value in major units to Money
+func toMoney(value bson.RawValue, currency string) (models.Money, error) {
+	switch value.Type {
+	case bsontype.Double:
+		return models.MoneyFromMajor(value.Double(), currency), nil
+	case bsontype.Int32:
+		return models.ParseMoney(fmt.Sprint(value.Int32()), currency)
+	case bsontype.Int64:
+		return models.ParseMoney(fmt.Sprint(value.Int64()), currency)
+	case bsontype.Decimal128:
+		return models.ParseMoney(value.Decimal128().String(), currency)
+	default:
+		return models.Money{}, fmt.Errorf("unsupported price type %s", value.Type)
+	}
+}
@@ -0,0 +1,49 @@
+package migrations
+
+import (
+	"testing"
+
+	"ecommerce-backend/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func rawValue(t *testing.T, v interface{}) bson.RawValue {
+	data, err := bson.Marshal(bson.M{"price": v})
+	require.NoError(t, err)
+	return bson.Raw(data).Lookup("price")
+}
+
+func TestToMoney(t *testing.T) {
+	decimal, err := primitive.ParseDecimal128("12.345")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		value    interface{}
+		currency string
+		expected models.Money
+	}{
+		{name: "double", value: 19.99, currency: "USD", expected: models.NewMoney(1999, "USD")},
+		{name: "double half cent rounds up", value: 1.005, currency: "USD", expected: models.NewMoney(101, "USD")},
+		{name: "int32", value: int32(25), currency: "USD", expected: models.NewMoney(2500, "USD")},
+		{name: "int64", value: int64(1500), currency: "JPY", expected: models.NewMoney(1500, "JPY")},
+		{name: "decimal128", value: decimal, currency: "eur", expected: models.NewMoney(1235, "EUR")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			money, err := toMoney(rawValue(t, tt.value), tt.currency)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, money)
+		})
+	}
+}
+
+func TestToMoney_UnsupportedType(t *testing.T) {
+	_, err := toMoney(rawValue(t, "19.99"), "USD")
+	assert.Error(t, err)
+}
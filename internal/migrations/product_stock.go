@@ -0,0 +1,28 @@
+package migrations
+
+import (
+	"context"
+
+	"ecommerce-backend/internal/database"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// ProductStock gives products created before stock was tracked a stock count, so that
+// checkout keeps selling them: initial units for products on sale and none for the rest
+func ProductStock(initial int) Migration {
+	return Migration{
+		ID: "0005_product_stock",
+		Run: func(ctx context.Context, db *database.Client) error {
+			collection := db.GetCollection("products")
+			untracked := bson.M{"stock": bson.M{"$exists": false}}
+
+			onSale := bson.M{"stock": bson.M{"$exists": false}, "in_stock": true}
+			if _, err := collection.UpdateMany(ctx, onSale, bson.M{"$set": bson.M{"stock": initial}}); err != nil {
+				return err
+			}
+			_, err := collection.UpdateMany(ctx, untracked, bson.M{"$set": bson.M{"stock": 0}})
+			return err
+		},
+	}
+}
@@ -0,0 +1,32 @@
+package migrations
+
+import (
+	"context"
+
+	"ecommerce-backend/internal/database"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// PromotionUsageCounts builds the per-user promotion usage counters from the redemptions
+// recorded before checkout claimed them
+func PromotionUsageCounts() Migration {
+	return Migration{
+		ID: "0004_promotion_usage_counts",
+		Run: func(ctx context.Context, db *database.Client) error {
+			pipeline := mongo.Pipeline{
+				{{Key: "$group", Value: bson.M{
+					"_id":   bson.D{{Key: "promotion_id", Value: "$promotion_id"}, {Key: "user_id", Value: "$user_id"}},
+					"count": bson.M{"$sum": 1},
+				}}},
+				{{Key: "$merge", Value: bson.M{"into": "promotion_usage", "whenMatched": "replace"}}},
+			}
+			cursor, err := db.GetCollection("promotion_redemptions").Aggregate(ctx, pipeline)
+			if err != nil {
+				return err
+			}
+			return cursor.Close(ctx)
+		},
+	}
+}
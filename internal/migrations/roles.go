@@ -0,0 +1,68 @@
+package migrations
+
+import (
+	"context"
+	"time"
+
+	"ecommerce-backend/internal/database"
+	"ecommerce-backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// defaultRoles are created by SeedRoles. Only admin and user are built in; the staff
+// roles are a starting point that admins may change or delete.
+var defaultRoles = []models.RoleDefinition{
+	{Name: models.RoleAdmin, Description: "Full access", BuiltIn: true},
+	{Name: models.RoleUser, Description: "Customer", BuiltIn: true},
+	{
+		Name:        "catalog_editor",
+		Description: "Manages products and the homepage slider",
+		Permissions: []models.Permission{models.PermDashboardRead, models.PermProductsWrite, models.PermSlidersWrite},
+	},
+	{
+		Name:        "marketing",
+		Description: "Manages promotions and the homepage slider",
+		Permissions: []models.Permission{models.PermDashboardRead, models.PermPromotionsWrite, models.PermSlidersWrite},
+	},
+	{
+		Name:        "support",
+		Description: "Handles returns and refunds",
+		Permissions: []models.Permission{models.PermDashboardRead, models.PermReturnsManage, models.PermOrdersRefund},
+	},
+}
+
+// SeedRoles creates the built-in and default staff roles, leaving existing roles as they are
+func SeedRoles() Migration {
+	return Migration{
+		ID: "0003_seed_roles",
+		Run: func(ctx context.Context, db *database.Client) error {
+			collection := db.GetCollection("roles")
+			now := time.Now()
+
+			for _, role := range defaultRoles {
+				permissions := role.Permissions
+				if permissions == nil {
+					permissions = []models.Permission{}
+				}
+				_, err := collection.UpdateOne(ctx,
+					bson.M{"name": role.Name},
+					bson.M{"$setOnInsert": bson.M{
+						"name":        role.Name,
+						"description": role.Description,
+						"permissions": permissions,
+						"built_in":    role.BuiltIn,
+						"created_at":  now,
+						"updated_at":  now,
+					}},
+					options.Update().SetUpsert(true),
+				)
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+}
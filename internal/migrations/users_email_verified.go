@@ -0,0 +1,24 @@
+package migrations
+
+import (
+	"context"
+
+	"ecommerce-backend/internal/database"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// ExistingUsersEmailVerified marks users registered before email verification existed
+// as verified, so that they are not blocked from checkout
+func ExistingUsersEmailVerified() Migration {
+	return Migration{
+		ID: "0002_existing_users_email_verified",
+		Run: func(ctx context.Context, db *database.Client) error {
+			_, err := db.GetCollection("users").UpdateMany(ctx,
+				bson.M{"email_verified": bson.M{"$exists": false}},
+				bson.M{"$set": bson.M{"email_verified": true}},
+			)
+			return err
+		},
+	}
+}
@@ -0,0 +1,114 @@
+package models
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Address validation errors
+var (
+	ErrAddressIncomplete = errors.New("Address must include a full name, first line, city and country")
+	ErrInvalidCountry    = errors.New("Invalid country code")
+	ErrRegionRequired    = errors.New("Region is required for this country")
+	ErrPostcodeRequired  = errors.New("Postcode is required for this country")
+	ErrInvalidPostcode   = errors.New("Invalid postcode for this country")
+)
+
+// MaxSavedAddresses is the maximum number of addresses in a user's address book
+const MaxSavedAddresses = 20
+
+// Address represents a postal address used for shipping and billing
+type Address struct {
+	FullName string `json:"full_name,omitempty" bson:"full_name,omitempty" validate:"omitempty,max=100"`
+	Line1    string `json:"line1,omitempty" bson:"line1,omitempty" validate:"omitempty,max=200"`
+	Line2    string `json:"line2,omitempty" bson:"line2,omitempty" validate:"omitempty,max=200"`
+	City     string `json:"city,omitempty" bson:"city,omitempty" validate:"omitempty,max=100"`
+	Region   string `json:"region,omitempty" bson:"region,omitempty" validate:"omitempty,max=100"`
+	Postcode string `json:"postcode,omitempty" bson:"postcode,omitempty" validate:"omitempty,max=20"`
+	Country  string `json:"country" bson:"country" validate:"required,len=2,alpha"` // ISO 3166-1 alpha-2
+	Phone    string `json:"phone,omitempty" bson:"phone,omitempty" validate:"omitempty,max=30"`
+}
+
+// addressFormat describes the postal conventions of a country
+type addressFormat struct {
+	postcode       *regexp.Regexp // nil when the country has no postcodes or no fixed format
+	regionRequired bool
+}
+
+// addressFormats lists the countries with known postcode formats. Other countries
+// accept any postcode.
+var addressFormats = map[string]addressFormat{
+	"US": {postcode: regexp.MustCompile(`^\d{5}(-\d{4})?$`), regionRequired: true},
+	"CA": {postcode: regexp.MustCompile(`^[A-Z]\d[A-Z] ?\d[A-Z]\d$`), regionRequired: true},
+	"AU": {postcode: regexp.MustCompile(`^\d{4}$`), regionRequired: true},
+	"GB": {postcode: regexp.MustCompile(`^[A-Z]{1,2}\d[A-Z\d]? ?\d[A-Z]{2}$`)},
+	"IE": {postcode: regexp.MustCompile(`^[A-Z\d]{3} ?[A-Z\d]{4}$`)},
+	"NL": {postcode: regexp.MustCompile(`^\d{4} ?[A-Z]{2}$`)},
+	"DE": {postcode: regexp.MustCompile(`^\d{5}$`)},
+	"FR": {postcode: regexp.MustCompile(`^\d{5}$`)},
+	"ES": {postcode: regexp.MustCompile(`^\d{5}$`)},
+	"IT": {postcode: regexp.MustCompile(`^\d{5}$`)},
+	"JP": {postcode: regexp.MustCompile(`^\d{3}-?\d{4}$`)},
+	"IN": {postcode: regexp.MustCompile(`^\d{6}$`)},
+}
+
+// postcodeOptional lists countries where addresses may omit the postcode
+var postcodeOptional = map[string]bool{
+	"IE": true, "HK": true, "AE": true,
+}
+
+// Normalized returns a copy with the country and region upper-cased and spaces trimmed
+func (a Address) Normalized() Address {
+	a.Country = strings.ToUpper(strings.TrimSpace(a.Country))
+	a.Region = strings.TrimSpace(a.Region)
+	a.Postcode = strings.ToUpper(strings.TrimSpace(a.Postcode))
+	return a
+}
+
+// Validate checks that a normalized address is complete enough to deliver to and that
+// its postcode and region follow the country's format
+func (a Address) Validate() error {
+	if strings.TrimSpace(a.FullName) == "" || strings.TrimSpace(a.Line1) == "" || strings.TrimSpace(a.City) == "" {
+		return ErrAddressIncomplete
+	}
+	if len(a.Country) != 2 || strings.ToUpper(a.Country) != a.Country {
+		return ErrInvalidCountry
+	}
+
+	format, known := addressFormats[a.Country]
+	if format.regionRequired && a.Region == "" {
+		return ErrRegionRequired
+	}
+	if a.Postcode == "" {
+		if known && !postcodeOptional[a.Country] {
+			return ErrPostcodeRequired
+		}
+		return nil
+	}
+	if format.postcode != nil && !format.postcode.MatchString(a.Postcode) {
+		return ErrInvalidPostcode
+	}
+	return nil
+}
+
+// SavedAddress represents an address in a user's address book
+type SavedAddress struct {
+	ID        primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	UserID    primitive.ObjectID `json:"user_id" bson:"user_id"`
+	Label     string             `json:"label" bson:"label"`
+	Address   Address            `json:"address" bson:"address"`
+	IsDefault bool               `json:"is_default" bson:"is_default"`
+	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt time.Time          `json:"updated_at" bson:"updated_at"`
+}
+
+// SavedAddressRequest represents the request payload for creating or replacing a saved address
+type SavedAddressRequest struct {
+	Label     string  `json:"label" validate:"required,min=1,max=50"`
+	Address   Address `json:"address" validate:"required"`
+	IsDefault bool    `json:"is_default"`
+}
@@ -0,0 +1,41 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddress_Validate(t *testing.T) {
+	base := Address{FullName: "Ada Lovelace", Line1: "1 Main St", City: "Springfield"}
+	with := func(country, region, postcode string) Address {
+		a := base
+		a.Country, a.Region, a.Postcode = country, region, postcode
+		return a.Normalized()
+	}
+
+	tests := []struct {
+		name     string
+		address  Address
+		expected error
+	}{
+		{"US zip", with("us", "IL", "62701"), nil},
+		{"US zip+4", with("US", "IL", "62701-1234"), nil},
+		{"US missing state", with("US", "", "62701"), ErrRegionRequired},
+		{"US bad zip", with("US", "IL", "6270"), ErrInvalidPostcode},
+		{"GB postcode", with("GB", "", "sw1a 1aa"), nil},
+		{"GB bad postcode", with("GB", "", "12345"), ErrInvalidPostcode},
+		{"CA postcode", with("CA", "ON", "K1A 0B1"), nil},
+		{"DE missing postcode", with("DE", "", ""), ErrPostcodeRequired},
+		{"IE postcode optional", with("IE", "", ""), nil},
+		{"unknown country accepts any postcode", with("NO", "", "0150"), nil},
+		{"invalid country", with("USA", "", ""), ErrInvalidCountry},
+		{"incomplete", Address{Country: "US", Region: "IL", Postcode: "62701"}, ErrAddressIncomplete},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.address.Validate())
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// APIKey lets an integration call the admin API on behalf of the admin who issued it,
+// limited to the key's permissions. Only the SHA-256 digest of the key is stored.
+type APIKey struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Name        string             `bson:"name" json:"name"`
+	Prefix      string             `bson:"prefix" json:"prefix"` // Start of the key, to tell keys apart
+	KeyHash     string             `bson:"key_hash" json:"-"`
+	Permissions []Permission       `bson:"permissions" json:"permissions"`
+	CreatedBy   primitive.ObjectID `bson:"created_by" json:"created_by"`
+	CreatedAt   time.Time          `bson:"created_at" json:"created_at"`
+	ExpiresAt   *time.Time         `bson:"expires_at,omitempty" json:"expires_at,omitempty"` // Never expires when nil
+	LastUsedAt  *time.Time         `bson:"last_used_at,omitempty" json:"last_used_at,omitempty"`
+	LastUsedIP  string             `bson:"last_used_ip,omitempty" json:"last_used_ip,omitempty"`
+	RevokedAt   *time.Time         `bson:"revoked_at,omitempty" json:"revoked_at,omitempty"`
+}
+
+// CreateAPIKeyRequest represents the request payload for issuing an API key
+type CreateAPIKeyRequest struct {
+	Name        string       `json:"name" validate:"required,min=2,max=100"`
+	Permissions []Permission `json:"permissions" validate:"required,min=1"`
+	ExpiresAt   *time.Time   `json:"expires_at,omitempty"`
+}
+
+// APIKeyCreatedResponse carries a new key. The key itself is only ever shown here.
+type APIKeyCreatedResponse struct {
+	APIKey APIKey `json:"api_key"`
+	Key    string `json:"key"`
+}
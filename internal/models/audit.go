@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// AuditEntry records one change made through the admin API. Entries are only ever inserted.
+type AuditEntry struct {
+	ID         primitive.ObjectID     `bson:"_id,omitempty" json:"id"`
+	ActorID    primitive.ObjectID     `bson:"actor_id" json:"actor_id"`
+	ActorEmail string                 `bson:"actor_email" json:"actor_email"`
+	APIKeyID   string                 `bson:"api_key_id,omitempty" json:"api_key_id,omitempty"` // Set when the actor used an API key
+	Action     string                 `bson:"action" json:"action"`                             // Method and route, e.g. "PUT /api/admin/products/:id"
+	Resource   string                 `bson:"resource" json:"resource"`
+	ResourceID string                 `bson:"resource_id,omitempty" json:"resource_id,omitempty"`
+	Changes    map[string]AuditChange `bson:"changes,omitempty" json:"changes,omitempty"` // Changed fields only
+	Status     int                    `bson:"status" json:"status"`
+	IP         string                 `bson:"ip" json:"ip"`
+	RequestID  string                 `bson:"request_id" json:"request_id"`
+	CreatedAt  time.Time              `bson:"created_at" json:"created_at"`
+}
+
+// AuditChange holds a field's value before and after a change. Either is nil when the
+// document was created or deleted.
+type AuditChange struct {
+	Before interface{} `bson:"before" json:"before"`
+	After  interface{} `bson:"after" json:"after"`
+}
+
+// AuditListResponse represents the response for listing audit entries
+type AuditListResponse struct {
+	Entries []AuditEntry `json:"entries"`
+	Total   int64        `json:"total"`
+	Page    int          `json:"page"`
+	Limit   int          `json:"limit"`
+}
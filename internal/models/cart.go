@@ -0,0 +1,58 @@
+package models
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// CartItemRequest represents a single product and quantity sent by the client
+type CartItemRequest struct {
+	ProductID string `json:"product_id" validate:"required,objectid"`
+	Quantity  int    `json:"quantity" validate:"required,min=1,max=1000"`
+}
+
+// CartRequest represents a cart submitted for pricing or checkout
+type CartRequest struct {
+	Items    []CartItemRequest `json:"items" validate:"required,min=1,dive"`
+	Codes    []string          `json:"codes,omitempty"`
+	Currency string            `json:"currency,omitempty" validate:"omitempty,currency"` // Defaults to the Accept-Currency header, then the store currency
+}
+
+// CartLine represents a priced line in a cart, resolved from the products collection
+type CartLine struct {
+	ProductID primitive.ObjectID `json:"product_id" bson:"product_id"`
+	Name      string             `json:"name" bson:"name"`
+	Category  ProductCategory    `json:"category" bson:"category"`
+	Quantity  int                `json:"quantity" bson:"quantity"`
+	UnitPrice Money              `json:"unit_price" bson:"unit_price"`
+	Weight    float64            `json:"weight" bson:"weight"` // Kilograms per unit
+	TaxClass  TaxClass           `json:"tax_class" bson:"tax_class"`
+}
+
+// Total returns the line total before discounts
+func (l CartLine) Total() Money {
+	return l.UnitPrice.Times(l.Quantity)
+}
+
+// Cart represents a set of priced lines belonging to a user
+type Cart struct {
+	UserID primitive.ObjectID `json:"user_id"`
+	Lines  []CartLine         `json:"lines"`
+}
+
+// Subtotal returns the sum of all line totals before discounts
+func (c Cart) Subtotal() Money {
+	var subtotal Money
+	for _, line := range c.Lines {
+		subtotal = subtotal.Add(line.Total())
+	}
+	return subtotal
+}
+
+// Weight returns the total weight of the cart in kilograms
+func (c Cart) Weight() float64 {
+	var weight float64
+	for _, line := range c.Lines {
+		weight += line.Weight * float64(line.Quantity)
+	}
+	return weight
+}
@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ExchangeRate converts prices from the store currency into another currency
+type ExchangeRate struct {
+	ID        primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Base      string             `json:"base" bson:"base"`         // Store currency the rate converts from
+	Currency  string             `json:"currency" bson:"currency"` // Currency the rate converts to
+	Rate      float64            `json:"rate" bson:"rate"`         // Units of Currency per unit of Base
+	UpdatedBy primitive.ObjectID `json:"updated_by" bson:"updated_by"`
+	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt time.Time          `json:"updated_at" bson:"updated_at"`
+}
+
+// ExchangeRateRequest represents the request payload for setting an exchange rate
+type ExchangeRateRequest struct {
+	Rate float64 `json:"rate" validate:"required,gt=0"`
+}
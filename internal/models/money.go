@@ -0,0 +1,156 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidAmount is returned when a decimal amount cannot be parsed
+var ErrInvalidAmount = errors.New("invalid amount")
+
+// currencyExponents lists the number of minor unit digits of supported ISO 4217 currencies
+var currencyExponents = map[string]int{
+	"AED": 2, "AUD": 2, "BHD": 3, "BRL": 2, "CAD": 2, "CHF": 2, "CLP": 0, "CNY": 2,
+	"CZK": 2, "DKK": 2, "EUR": 2, "GBP": 2, "HKD": 2, "HUF": 2, "IDR": 2, "ILS": 2,
+	"INR": 2, "ISK": 0, "JOD": 3, "JPY": 0, "KRW": 0, "KWD": 3, "MXN": 2, "MYR": 2,
+	"NOK": 2, "NZD": 2, "OMR": 3, "PHP": 2, "PLN": 2, "SAR": 2, "SEK": 2, "SGD": 2,
+	"THB": 2, "TND": 3, "TRY": 2, "USD": 2, "VND": 0, "ZAR": 2,
+}
+
+// IsValidCurrency checks if a currency code is a supported ISO 4217 code
+func IsValidCurrency(code string) bool {
+	_, ok := currencyExponents[code]
+	return ok
+}
+
+// CurrencyExponent returns the number of minor unit digits of a currency (2 when unknown)
+func CurrencyExponent(code string) int {
+	if exponent, ok := currencyExponents[strings.ToUpper(code)]; ok {
+		return exponent
+	}
+	return 2
+}
+
+// Money represents an exact amount as an integer number of minor units (e.g. cents)
+// in an ISO 4217 currency. It is stored and serialized as {"amount": 1999, "currency": "USD"}.
+type Money struct {
+	Amount   int64  `json:"amount" bson:"amount"`
+	Currency string `json:"currency" bson:"currency" validate:"omitempty,currency"`
+}
+
+// NewMoney creates an amount from minor units
+func NewMoney(amount int64, currency string) Money {
+	return Money{Amount: amount, Currency: strings.ToUpper(currency)}
+}
+
+// ParseMoney parses a decimal amount in major units (e.g. "19.99") exactly,
+// rounding half away from zero to the currency's minor unit
+func ParseMoney(amount, currency string) (Money, error) {
+	value, ok := new(big.Rat).SetString(strings.TrimSpace(amount))
+	if !ok {
+		return Money{}, ErrInvalidAmount
+	}
+
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(CurrencyExponent(currency))), nil)
+	value.Mul(value, new(big.Rat).SetInt(scale))
+
+	// Round half away from zero: add or subtract one half, then truncate
+	half := big.NewRat(1, 2)
+	if value.Sign() < 0 {
+		value.Sub(value, half)
+	} else {
+		value.Add(value, half)
+	}
+	minor := new(big.Int).Quo(value.Num(), value.Denom())
+	if !minor.IsInt64() {
+		return Money{}, ErrInvalidAmount
+	}
+	return NewMoney(minor.Int64(), currency), nil
+}
+
+// MoneyFromMajor converts a float amount in major units to Money. The float is read
+// through its shortest decimal form, so 1.005 becomes 1.01 rather than 1.00.
+func MoneyFromMajor(amount float64, currency string) Money {
+	money, err := ParseMoney(strconv.FormatFloat(amount, 'f', -1, 64), currency)
+	if err != nil {
+		return NewMoney(int64(math.Round(amount*math.Pow10(CurrencyExponent(currency)))), currency)
+	}
+	return money
+}
+
+// Major returns the amount in major units, for display and external APIs only
+func (m Money) Major() float64 {
+	return float64(m.Amount) / math.Pow10(CurrencyExponent(m.Currency))
+}
+
+// IsZero reports whether the amount is zero
+func (m Money) IsZero() bool {
+	return m.Amount == 0
+}
+
+// IsPositive reports whether the amount is greater than zero
+func (m Money) IsPositive() bool {
+	return m.Amount > 0
+}
+
+// Add returns m + o. A zero value without a currency takes the other operand's currency.
+func (m Money) Add(o Money) Money {
+	return Money{Amount: m.Amount + o.Amount, Currency: m.currencyWith(o)}
+}
+
+// Sub returns m - o
+func (m Money) Sub(o Money) Money {
+	return Money{Amount: m.Amount - o.Amount, Currency: m.currencyWith(o)}
+}
+
+// Times returns the amount multiplied by a quantity
+func (m Money) Times(quantity int) Money {
+	return Money{Amount: m.Amount * int64(quantity), Currency: m.Currency}
+}
+
+// MulRate returns the amount multiplied by a factor, rounded half away from zero to the
+// nearest minor unit. It is used for percentages, tax rates and exchange rates.
+func (m Money) MulRate(factor float64) Money {
+	return Money{Amount: int64(math.Round(float64(m.Amount) * factor)), Currency: m.Currency}
+}
+
+// Convert returns the amount in another currency at an exchange rate (units of the target
+// currency per unit of m's currency), rounded to the target's minor unit
+func (m Money) Convert(currency string, rate float64) Money {
+	scale := math.Pow10(CurrencyExponent(currency) - CurrencyExponent(m.Currency))
+	return NewMoney(int64(math.Round(float64(m.Amount)*rate*scale)), currency)
+}
+
+// Min returns the smaller of m and o
+func (m Money) Min(o Money) Money {
+	if o.Amount < m.Amount {
+		return Money{Amount: o.Amount, Currency: m.currencyWith(o)}
+	}
+	return Money{Amount: m.Amount, Currency: m.currencyWith(o)}
+}
+
+// Max returns the larger of m and o
+func (m Money) Max(o Money) Money {
+	if o.Amount > m.Amount {
+		return Money{Amount: o.Amount, Currency: m.currencyWith(o)}
+	}
+	return Money{Amount: m.Amount, Currency: m.currencyWith(o)}
+}
+
+// String formats the amount in major units followed by the currency, e.g. "19.99 USD"
+func (m Money) String() string {
+	exponent := CurrencyExponent(m.Currency)
+	return strings.TrimSpace(fmt.Sprintf("%.*f %s", exponent, m.Major(), m.Currency))
+}
+
+// currencyWith picks the currency of a binary operation
+func (m Money) currencyWith(o Money) string {
+	if m.Currency == "" {
+		return o.Currency
+	}
+	return m.Currency
+}
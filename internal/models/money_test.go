@@ -0,0 +1,60 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMoney(t *testing.T) {
+	tests := []struct {
+		name     string
+		amount   string
+		currency string
+		expected Money
+	}{
+		{name: "two decimals", amount: "19.99", currency: "USD", expected: NewMoney(1999, "USD")},
+		{name: "half rounds away from zero", amount: "1.005", currency: "USD", expected: NewMoney(101, "USD")},
+		{name: "negative half rounds away from zero", amount: "-1.005", currency: "USD", expected: NewMoney(-101, "USD")},
+		{name: "zero decimal currency", amount: "1500.4", currency: "JPY", expected: NewMoney(1500, "JPY")},
+		{name: "three decimal currency", amount: "2.5", currency: "KWD", expected: NewMoney(2500, "KWD")},
+		{name: "currency is upper-cased", amount: "10", currency: "eur", expected: NewMoney(1000, "EUR")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			money, err := ParseMoney(tt.amount, tt.currency)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, money)
+		})
+	}
+
+	_, err := ParseMoney("abc", "USD")
+	assert.ErrorIs(t, err, ErrInvalidAmount)
+}
+
+func TestMoneyArithmetic(t *testing.T) {
+	a := NewMoney(1999, "USD")
+	b := NewMoney(501, "USD")
+
+	assert.Equal(t, NewMoney(2500, "USD"), a.Add(b))
+	assert.Equal(t, NewMoney(1498, "USD"), a.Sub(b))
+	assert.Equal(t, NewMoney(5997, "USD"), a.Times(3))
+	assert.Equal(t, NewMoney(200, "USD"), a.MulRate(0.1))
+	assert.Equal(t, b, a.Min(b))
+	assert.Equal(t, a, a.Max(b))
+	assert.Equal(t, a, Money{}.Add(a))
+}
+
+func TestMoneyConvert(t *testing.T) {
+	assert.Equal(t, NewMoney(1838, "EUR"), NewMoney(1999, "USD").Convert("EUR", 0.9195))
+	assert.Equal(t, NewMoney(2999, "JPY"), NewMoney(1999, "USD").Convert("JPY", 150.02))
+	assert.Equal(t, NewMoney(1333, "USD"), NewMoney(2000, "JPY").Convert("USD", 0.006665))
+}
+
+func TestMoneyString(t *testing.T) {
+	assert.Equal(t, "19.99 USD", NewMoney(1999, "USD").String())
+	assert.Equal(t, "1500 JPY", NewMoney(1500, "JPY").String())
+	assert.Equal(t, "2.500 KWD", NewMoney(2500, "KWD").String())
+}
@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Identity links a user to their account at an OpenID Connect provider
+type Identity struct {
+	Provider string    `bson:"provider" json:"provider"`
+	Subject  string    `bson:"subject" json:"-"` // The provider's stable user ID (sub claim)
+	LinkedAt time.Time `bson:"linked_at" json:"linked_at"`
+}
+
+// OIDCState holds the secrets of an OpenID Connect login between the redirect to the
+// provider and the callback. Only the state's hash is stored.
+type OIDCState struct {
+	ID           primitive.ObjectID `bson:"_id,omitempty"`
+	StateHash    string             `bson:"state_hash"`
+	Provider     string             `bson:"provider"`
+	Nonce        string             `bson:"nonce"`
+	CodeVerifier string             `bson:"code_verifier"` // PKCE verifier matching the challenge sent to the provider
+	ExpiresAt    time.Time          `bson:"expires_at"`
+	CreatedAt    time.Time          `bson:"created_at"`
+}
+
+// OIDCLoginResponse carries the provider URL the user is sent to
+type OIDCLoginResponse struct {
+	AuthorizationURL string `json:"authorization_url"`
+}
+
+// OIDCCallbackRequest represents the code and state the provider redirected back with
+type OIDCCallbackRequest struct {
+	Code  string `json:"code" validate:"required"`
+	State string `json:"state" validate:"required"`
+}
@@ -0,0 +1,93 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// OrderStatus represents the fulfilment state of an order
+type OrderStatus string
+
+const (
+	OrderStatusPending   OrderStatus = "pending"
+	OrderStatusPaid      OrderStatus = "paid"
+	OrderStatusCancelled OrderStatus = "cancelled"
+)
+
+// PaymentStatus represents the payment state of an order
+type PaymentStatus string
+
+const (
+	PaymentStatusPending           PaymentStatus = "pending"
+	PaymentStatusAuthorized        PaymentStatus = "authorized"
+	PaymentStatusPaid              PaymentStatus = "paid"
+	PaymentStatusFailed            PaymentStatus = "failed"
+	PaymentStatusPartiallyRefunded PaymentStatus = "partially_refunded"
+	PaymentStatusRefunded          PaymentStatus = "refunded"
+	PaymentStatusAmountMismatch    PaymentStatus = "amount_mismatch" // Paid an amount other than the order total; needs review
+)
+
+// OrderItem represents a product line captured at order time
+type OrderItem struct {
+	ProductID primitive.ObjectID `json:"product_id" bson:"product_id"`
+	Name      string             `json:"name" bson:"name"`
+	Category  ProductCategory    `json:"category" bson:"category"`
+	Quantity  int                `json:"quantity" bson:"quantity"`
+	UnitPrice Money              `json:"unit_price" bson:"unit_price"`
+	Total     Money              `json:"total" bson:"total"`
+	Returned  int                `json:"returned_quantity" bson:"returned_quantity,omitempty"` // Units claimed by returns that were not rejected
+}
+
+// Order represents a placed order
+type Order struct {
+	ID              primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	UserID          primitive.ObjectID `json:"user_id" bson:"user_id"`
+	Items           []OrderItem        `json:"items" bson:"items"`
+	Currency        string             `json:"currency" bson:"currency"`
+	ExchangeRate    float64            `json:"exchange_rate" bson:"exchange_rate"` // Units of Currency per unit of the store currency when placed
+	Subtotal        Money              `json:"subtotal" bson:"subtotal"`
+	Discount        Money              `json:"discount" bson:"discount"`
+	ShippingCost    Money              `json:"shipping_cost" bson:"shipping_cost"`
+	Tax             Money              `json:"tax" bson:"tax"`
+	Total           Money              `json:"total" bson:"total"`
+	Promotions      []AppliedPromotion `json:"promotions,omitempty" bson:"promotions,omitempty"`
+	ShippingAddress *Address           `json:"shipping_address,omitempty" bson:"shipping_address,omitempty"` // Snapshot taken at checkout
+	BillingAddress  *Address           `json:"billing_address,omitempty" bson:"billing_address,omitempty"`   // Snapshot taken at checkout
+	ShippingMethod  *ShippingQuote     `json:"shipping_method,omitempty" bson:"shipping_method,omitempty"`
+	TaxBreakdown    *TaxBreakdown      `json:"tax_breakdown,omitempty" bson:"tax_breakdown,omitempty"`
+	Status          OrderStatus        `json:"status" bson:"status"`
+	PaymentStatus   PaymentStatus      `json:"payment_status" bson:"payment_status"`
+	PaymentProvider string             `json:"payment_provider" bson:"payment_provider"`
+	PaymentIntentID string             `json:"payment_intent_id" bson:"payment_intent_id"`
+	AmountPaid      Money              `json:"amount_paid" bson:"amount_paid"`
+	AmountRefunded  Money              `json:"amount_refunded" bson:"amount_refunded"`
+	PaidAt          *time.Time         `json:"paid_at,omitempty" bson:"paid_at,omitempty"`
+	CancelledAt     *time.Time         `json:"cancelled_at,omitempty" bson:"cancelled_at,omitempty"` // Set when an unpaid order is cancelled and its stock given back
+	CreatedAt       time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt       time.Time          `json:"updated_at" bson:"updated_at"`
+}
+
+// CheckoutRequest represents the request payload for placing an order
+type CheckoutRequest struct {
+	CartRequest
+	ShippingAddress   *Address `json:"shipping_address,omitempty"`
+	ShippingAddressID string   `json:"shipping_address_id,omitempty" validate:"omitempty,objectid"` // Saved address, instead of ShippingAddress
+	ShippingMethodID  string   `json:"shipping_method_id,omitempty" validate:"required_with=ShippingAddress ShippingAddressID,omitempty,objectid"`
+	BillingAddress    *Address `json:"billing_address,omitempty"`
+	BillingAddressID  string   `json:"billing_address_id,omitempty" validate:"omitempty,objectid"` // Saved address, instead of BillingAddress
+}
+
+// CheckoutResponse represents the placed order and the payment details the client needs
+type CheckoutResponse struct {
+	Order        Order  `json:"order"`
+	ClientSecret string `json:"client_secret"`
+}
+
+// OrderListResponse represents the response for listing orders
+type OrderListResponse struct {
+	Orders []Order `json:"orders"`
+	Total  int64   `json:"total"`
+	Page   int     `json:"page"`
+	Limit  int     `json:"limit"`
+}
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// PaymentEventType represents the kind of webhook event a payment provider sends
+type PaymentEventType string
+
+const (
+	PaymentEventAuthorized PaymentEventType = "payment.authorized"
+	PaymentEventSucceeded  PaymentEventType = "payment.succeeded"
+	PaymentEventFailed     PaymentEventType = "payment.failed"
+	PaymentEventRefunded   PaymentEventType = "payment.refunded"
+)
+
+// PaymentEvent records a verified webhook event. The ID combines the provider
+// name and the provider's event ID so redelivered events are deduplicated.
+type PaymentEvent struct {
+	ID          string             `json:"id" bson:"_id"`
+	Provider    string             `json:"provider" bson:"provider"`
+	EventID     string             `json:"event_id" bson:"event_id"`
+	Type        PaymentEventType   `json:"type" bson:"type"`
+	IntentID    string             `json:"intent_id" bson:"intent_id"`
+	Amount      Money              `json:"amount" bson:"amount"`
+	OrderID     primitive.ObjectID `json:"order_id,omitempty" bson:"order_id,omitempty"`
+	Processed   bool               `json:"processed" bson:"processed"`
+	ReceivedAt  time.Time          `json:"received_at" bson:"received_at"`
+	ProcessedAt *time.Time         `json:"processed_at,omitempty" bson:"processed_at,omitempty"`
+}
@@ -53,13 +53,19 @@ func IsValidCategory(category string) bool {
 type Product struct {
 	ID            primitive.ObjectID `json:"id" bson:"_id,omitempty"`
 	Name          string             `json:"name" bson:"name" validate:"required,min=2,max=100"`
-	Price         float64            `json:"price" bson:"price" validate:"required,gt=0"`
-	Category      ProductCategory    `json:"category" bson:"category" validate:"required"`
+	Price         Money              `json:"price" bson:"price"`                       // Base price in the store currency
+	Prices        []Money            `json:"prices,omitempty" bson:"prices,omitempty"` // Explicit prices in other currencies
+	Category      ProductCategory    `json:"category" bson:"category" validate:"required,category"`
 	ImageURL      string             `json:"image_url" bson:"image_url"`
 	Description   string             `json:"description" bson:"description" validate:"required,min=10,max=1000"`
 	Specification string             `json:"specification" bson:"specification"`
 	Material      string             `json:"material" bson:"material"`
 	InStock       bool               `json:"in_stock" bson:"in_stock"`
+	Stock         int                `json:"stock" bson:"stock"`
+	SoldOut       bool               `json:"-" bson:"sold_out,omitempty"` // Taken off sale by checkout selling the last unit, not by an admin
+	Weight        float64            `json:"weight" bson:"weight"`        // Kilograms
+	Dimensions    *Dimensions        `json:"dimensions,omitempty" bson:"dimensions,omitempty"`
+	TaxClass      TaxClass           `json:"tax_class" bson:"tax_class"`
 	CreatedBy     primitive.ObjectID `json:"created_by" bson:"created_by"`
 	CreatedAt     time.Time          `json:"created_at" bson:"created_at"`
 	UpdatedAt     time.Time          `json:"updated_at" bson:"updated_at"`
@@ -67,39 +73,54 @@ type Product struct {
 
 // CreateProductRequest represents the request payload for creating a product
 type CreateProductRequest struct {
-	Name          string  `json:"name" validate:"required,min=2,max=100"`
-	Price         float64 `json:"price" validate:"required,gt=0"`
-	Category      string  `json:"category" validate:"required"`
-	Description   string  `json:"description" validate:"required,min=10,max=1000"`
-	Specification string  `json:"specification"`
-	Material      string  `json:"material"`
-	InStock       bool    `json:"in_stock"`
+	Name          string      `json:"name" validate:"required,min=2,max=100"`
+	Price         Money       `json:"price"`
+	Prices        []Money     `json:"prices,omitempty"`
+	Category      string      `json:"category" validate:"required,category"`
+	Description   string      `json:"description" validate:"required,min=10,max=1000"`
+	Specification string      `json:"specification"`
+	Material      string      `json:"material"`
+	InStock       bool        `json:"in_stock"`
+	Stock         int         `json:"stock" validate:"required_if=InStock true,gte=0"` // Required for products on sale
+	Weight        float64     `json:"weight" validate:"gte=0"`
+	Dimensions    *Dimensions `json:"dimensions,omitempty"`
+	TaxClass      string      `json:"tax_class,omitempty"`
 }
 
 // UpdateProductRequest represents the request payload for updating a product
 type UpdateProductRequest struct {
-	Name          *string  `json:"name,omitempty" validate:"omitempty,min=2,max=100"`
-	Price         *float64 `json:"price,omitempty" validate:"omitempty,gt=0"`
-	Category      *string  `json:"category,omitempty"`
-	Description   *string  `json:"description,omitempty" validate:"omitempty,min=10,max=1000"`
-	Specification *string  `json:"specification,omitempty"`
-	Material      *string  `json:"material,omitempty"`
-	InStock       *bool    `json:"in_stock,omitempty"`
+	Name          *string     `json:"name,omitempty" validate:"omitempty,min=2,max=100"`
+	Price         *Money      `json:"price,omitempty"`
+	Prices        []Money     `json:"prices,omitempty"` // Replaces all explicit prices; an empty list removes them
+	Category      *string     `json:"category,omitempty" validate:"omitempty,category"`
+	Description   *string     `json:"description,omitempty" validate:"omitempty,min=10,max=1000"`
+	Specification *string     `json:"specification,omitempty"`
+	Material      *string     `json:"material,omitempty"`
+	InStock       *bool       `json:"in_stock,omitempty"`
+	Stock         *int        `json:"stock,omitempty" validate:"omitempty,gte=0"`
+	Weight        *float64    `json:"weight,omitempty" validate:"omitempty,gte=0"`
+	Dimensions    *Dimensions `json:"dimensions,omitempty"`
+	TaxClass      *string     `json:"tax_class,omitempty"`
 }
 
 // ProductResponse represents the response payload for product operations
 type ProductResponse struct {
-	ID            string    `json:"id"`
-	Name          string    `json:"name"`
-	Price         float64   `json:"price"`
-	Category      string    `json:"category"`
-	ImageURL      string    `json:"image_url"`
-	Description   string    `json:"description"`
-	Specification string    `json:"specification"`
-	Material      string    `json:"material"`
-	InStock       bool      `json:"in_stock"`
-	CreatedAt     time.Time `json:"created_at"`
-	UpdatedAt     time.Time `json:"updated_at"`
+	ID            string      `json:"id"`
+	Name          string      `json:"name"`
+	Price         Money       `json:"price"`
+	Prices        []Money     `json:"prices,omitempty"`
+	Category      string      `json:"category"`
+	ImageURL      string      `json:"image_url"`
+	Description   string      `json:"description"`
+	Specification string      `json:"specification"`
+	Material      string      `json:"material"`
+	InStock       bool        `json:"in_stock"`
+	Stock         int         `json:"stock"`
+	Weight        float64     `json:"weight"`
+	Dimensions    *Dimensions `json:"dimensions,omitempty"`
+	TaxClass      string      `json:"tax_class"`
+	CreatedAt     time.Time   `json:"created_at"`
+	UpdatedAt     time.Time   `json:"updated_at"`
 }
 
 // ToResponse converts a Product to ProductResponse
@@ -123,12 +144,17 @@ func (p *Product) ToResponseWithBaseURL(baseURL string) ProductResponse {
 		ID:            p.ID.Hex(),
 		Name:          p.Name,
 		Price:         p.Price,
+		Prices:        p.Prices,
 		Category:      string(p.Category),
 		ImageURL:      imageURL,
 		Description:   p.Description,
 		Specification: p.Specification,
 		Material:      p.Material,
 		InStock:       p.InStock,
+		Stock:         p.Stock,
+		Weight:        p.Weight,
+		Dimensions:    p.Dimensions,
+		TaxClass:      string(p.TaxClass.OrDefault()),
 		CreatedAt:     p.CreatedAt,
 		UpdatedAt:     p.UpdatedAt,
 	}
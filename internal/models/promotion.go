@@ -0,0 +1,189 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// PromotionType represents the kind of discount a promotion grants
+type PromotionType string
+
+const (
+	PromotionPercentage   PromotionType = "percentage"
+	PromotionFixedAmount  PromotionType = "fixed_amount"
+	PromotionFreeShipping PromotionType = "free_shipping"
+	PromotionBuyXGetY     PromotionType = "buy_x_get_y"
+)
+
+// IsValid checks if the promotion type is valid
+func (t PromotionType) IsValid() bool {
+	switch t {
+	case PromotionPercentage, PromotionFixedAmount, PromotionFreeShipping, PromotionBuyXGetY:
+		return true
+	}
+	return false
+}
+
+// Promotion represents a discount code or an automatic promotion.
+// Promotions without a Code are applied automatically when eligible.
+type Promotion struct {
+	ID           primitive.ObjectID   `json:"id" bson:"_id,omitempty"`
+	Name         string               `json:"name" bson:"name"`
+	Description  string               `json:"description" bson:"description"`
+	Code         string               `json:"code,omitempty" bson:"code,omitempty"`
+	Type         PromotionType        `json:"type" bson:"type"`
+	Value        float64              `json:"value" bson:"value"`               // Percent (0-100) for percentage and buy-X-get-Y promotions
+	Amount       Money                `json:"amount" bson:"amount"`             // Discount of fixed amount promotions
+	BuyQuantity  int                  `json:"buy_quantity" bson:"buy_quantity"` // Buy-X-get-Y: units to buy
+	GetQuantity  int                  `json:"get_quantity" bson:"get_quantity"` // Buy-X-get-Y: units discounted by Value percent
+	MinCartValue Money                `json:"min_cart_value" bson:"min_cart_value"`
+	Categories   []ProductCategory    `json:"categories,omitempty" bson:"categories,omitempty"`
+	ProductIDs   []primitive.ObjectID `json:"product_ids,omitempty" bson:"product_ids,omitempty"`
+	UsageLimit   int                  `json:"usage_limit" bson:"usage_limit"`       // 0 means unlimited
+	PerUserLimit int                  `json:"per_user_limit" bson:"per_user_limit"` // 0 means unlimited
+	UsageCount   int                  `json:"usage_count" bson:"usage_count"`
+	StartsAt     *time.Time           `json:"starts_at,omitempty" bson:"starts_at,omitempty"`
+	EndsAt       *time.Time           `json:"ends_at,omitempty" bson:"ends_at,omitempty"`
+	Stackable    bool                 `json:"stackable" bson:"stackable"`
+	Priority     int                  `json:"priority" bson:"priority"`
+	IsActive     bool                 `json:"is_active" bson:"is_active"`
+	CreatedBy    primitive.ObjectID   `json:"created_by" bson:"created_by"`
+	CreatedAt    time.Time            `json:"created_at" bson:"created_at"`
+	UpdatedAt    time.Time            `json:"updated_at" bson:"updated_at"`
+}
+
+// IsAutomatic reports whether the promotion applies without a code
+func (p *Promotion) IsAutomatic() bool {
+	return p.Code == ""
+}
+
+// IsScoped reports whether the promotion only applies to some categories or products
+func (p *Promotion) IsScoped() bool {
+	return len(p.Categories) > 0 || len(p.ProductIDs) > 0
+}
+
+// AppliesTo checks if a cart line falls within the promotion's category or product scope
+func (p *Promotion) AppliesTo(line CartLine) bool {
+	if !p.IsScoped() {
+		return true
+	}
+	for _, category := range p.Categories {
+		if category == line.Category {
+			return true
+		}
+	}
+	for _, productID := range p.ProductIDs {
+		if productID == line.ProductID {
+			return true
+		}
+	}
+	return false
+}
+
+// IsWithinWindow checks if the promotion is valid at the given time
+func (p *Promotion) IsWithinWindow(now time.Time) bool {
+	if p.StartsAt != nil && now.Before(*p.StartsAt) {
+		return false
+	}
+	if p.EndsAt != nil && !now.Before(*p.EndsAt) {
+		return false
+	}
+	return true
+}
+
+// PromotionRedemption records a single use of a promotion by a user
+type PromotionRedemption struct {
+	ID          primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	PromotionID primitive.ObjectID `json:"promotion_id" bson:"promotion_id"`
+	UserID      primitive.ObjectID `json:"user_id" bson:"user_id"`
+	OrderID     primitive.ObjectID `json:"order_id,omitempty" bson:"order_id,omitempty"`
+	Discount    Money              `json:"discount" bson:"discount"`
+	CreatedAt   time.Time          `json:"created_at" bson:"created_at"`
+}
+
+// PromotionUsage counts a user's redemptions of a promotion, so that per-user limits can
+// be claimed atomically
+type PromotionUsage struct {
+	ID    PromotionUsageKey `json:"id" bson:"_id"`
+	Count int               `json:"count" bson:"count"`
+}
+
+// PromotionUsageKey identifies the usage of a promotion by one user
+type PromotionUsageKey struct {
+	PromotionID primitive.ObjectID `json:"promotion_id" bson:"promotion_id"`
+	UserID      primitive.ObjectID `json:"user_id" bson:"user_id"`
+}
+
+// CreatePromotionRequest represents the request payload for creating a promotion
+type CreatePromotionRequest struct {
+	Name         string     `json:"name" validate:"required,min=2,max=100"`
+	Description  string     `json:"description" validate:"max=500"`
+	Code         string     `json:"code,omitempty" validate:"omitempty,min=3,max=32,alphanum"`
+	Type         string     `json:"type" validate:"required"`
+	Value        float64    `json:"value" validate:"gte=0,lte=100"`
+	Amount       Money      `json:"amount"`
+	BuyQuantity  int        `json:"buy_quantity" validate:"gte=0"`
+	GetQuantity  int        `json:"get_quantity" validate:"gte=0"`
+	MinCartValue Money      `json:"min_cart_value"`
+	Categories   []string   `json:"categories,omitempty" validate:"dive,category"`
+	ProductIDs   []string   `json:"product_ids,omitempty" validate:"dive,objectid"`
+	UsageLimit   int        `json:"usage_limit" validate:"gte=0"`
+	PerUserLimit int        `json:"per_user_limit" validate:"gte=0"`
+	StartsAt     *time.Time `json:"starts_at,omitempty"`
+	EndsAt       *time.Time `json:"ends_at,omitempty"`
+	Stackable    bool       `json:"stackable"`
+	Priority     int        `json:"priority"`
+	IsActive     bool       `json:"is_active"`
+}
+
+// UpdatePromotionRequest represents the request payload for updating a promotion
+type UpdatePromotionRequest struct {
+	Name         *string    `json:"name,omitempty" validate:"omitempty,min=2,max=100"`
+	Description  *string    `json:"description,omitempty" validate:"omitempty,max=500"`
+	Value        *float64   `json:"value,omitempty" validate:"omitempty,gte=0,lte=100"`
+	Amount       *Money     `json:"amount,omitempty"`
+	BuyQuantity  *int       `json:"buy_quantity,omitempty" validate:"omitempty,gte=0"`
+	GetQuantity  *int       `json:"get_quantity,omitempty" validate:"omitempty,gte=0"`
+	MinCartValue *Money     `json:"min_cart_value,omitempty"`
+	Categories   []string   `json:"categories,omitempty" validate:"dive,category"`
+	ProductIDs   []string   `json:"product_ids,omitempty" validate:"dive,objectid"`
+	UsageLimit   *int       `json:"usage_limit,omitempty" validate:"omitempty,gte=0"`
+	PerUserLimit *int       `json:"per_user_limit,omitempty" validate:"omitempty,gte=0"`
+	StartsAt     *time.Time `json:"starts_at,omitempty"`
+	EndsAt       *time.Time `json:"ends_at,omitempty"`
+	Stackable    *bool      `json:"stackable,omitempty"`
+	Priority     *int       `json:"priority,omitempty"`
+	IsActive     *bool      `json:"is_active,omitempty"`
+}
+
+// AppliedPromotion describes a promotion applied to a cart and the discount it produced
+type AppliedPromotion struct {
+	PromotionID  primitive.ObjectID `json:"promotion_id" bson:"promotion_id"`
+	Name         string             `json:"name" bson:"name"`
+	Code         string             `json:"code,omitempty" bson:"code,omitempty"`
+	Type         PromotionType      `json:"type" bson:"type"`
+	Discount     Money              `json:"discount" bson:"discount"`
+	FreeShipping bool               `json:"free_shipping,omitempty" bson:"free_shipping,omitempty"`
+}
+
+// CartTotalsResponse represents the priced cart after promotions
+type CartTotalsResponse struct {
+	Lines         []CartLine         `json:"lines"`
+	Subtotal      Money              `json:"subtotal"`
+	Discount      Money              `json:"discount"`
+	Tax           Money              `json:"tax"`
+	Total         Money              `json:"total"`
+	TaxBreakdown  TaxBreakdown       `json:"tax_breakdown"`
+	FreeShipping  bool               `json:"free_shipping"`
+	Applied       []AppliedPromotion `json:"applied_promotions"`
+	RejectedCodes []string           `json:"rejected_codes,omitempty"`
+}
+
+// PromotionListResponse represents the response for listing promotions
+type PromotionListResponse struct {
+	Promotions []Promotion `json:"promotions"`
+	Total      int64       `json:"total"`
+	Page       int         `json:"page"`
+	Limit      int         `json:"limit"`
+}
@@ -0,0 +1,109 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ReturnStatus represents the state of a return request (RMA)
+type ReturnStatus string
+
+const (
+	ReturnStatusRequested ReturnStatus = "requested"
+	ReturnStatusApproved  ReturnStatus = "approved"
+	ReturnStatusRejected  ReturnStatus = "rejected"
+	ReturnStatusReceived  ReturnStatus = "received"
+	ReturnStatusRefunding ReturnStatus = "refunding" // Claimed by a refund in progress
+	ReturnStatusRefunded  ReturnStatus = "refunded"
+)
+
+// CanTransitionTo checks if a return may move from s to next
+func (s ReturnStatus) CanTransitionTo(next ReturnStatus) bool {
+	switch s {
+	case ReturnStatusRequested:
+		return next == ReturnStatusApproved || next == ReturnStatusRejected
+	case ReturnStatusApproved:
+		return next == ReturnStatusReceived || next == ReturnStatusRefunding
+	case ReturnStatusReceived:
+		return next == ReturnStatusRefunding
+	case ReturnStatusRefunding:
+		return next == ReturnStatusRefunded
+	}
+	return false
+}
+
+// ReturnItem represents a quantity of one order line being returned
+type ReturnItem struct {
+	ProductID primitive.ObjectID `json:"product_id" bson:"product_id"`
+	Name      string             `json:"name" bson:"name"`
+	Quantity  int                `json:"quantity" bson:"quantity"`
+	UnitPrice Money              `json:"unit_price" bson:"unit_price"`
+	Reason    string             `json:"reason" bson:"reason"`
+	Restocked bool               `json:"restocked" bson:"restocked"`
+}
+
+// ReturnEvent records a status change or action on a return
+type ReturnEvent struct {
+	Status    ReturnStatus       `json:"status" bson:"status"`
+	Action    string             `json:"action" bson:"action"`
+	ActorID   primitive.ObjectID `json:"actor_id" bson:"actor_id"`
+	ActorRole string             `json:"actor_role" bson:"actor_role"`
+	Note      string             `json:"note,omitempty" bson:"note,omitempty"`
+	Amount    *Money             `json:"amount,omitempty" bson:"amount,omitempty"`
+	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
+}
+
+// Return represents a return merchandise authorization for part of an order
+type Return struct {
+	ID             primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	OrderID        primitive.ObjectID `json:"order_id" bson:"order_id"`
+	UserID         primitive.ObjectID `json:"user_id" bson:"user_id"`
+	Items          []ReturnItem       `json:"items" bson:"items"`
+	Reason         string             `json:"reason" bson:"reason"`
+	Status         ReturnStatus       `json:"status" bson:"status"`
+	RefundedAmount Money              `json:"refunded_amount" bson:"refunded_amount"`
+	RefundIDs      []string           `json:"refund_ids,omitempty" bson:"refund_ids,omitempty"`
+	History        []ReturnEvent      `json:"history" bson:"history"`
+	CreatedAt      time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt      time.Time          `json:"updated_at" bson:"updated_at"`
+}
+
+// ReturnItemRequest represents a line the customer wants to return
+type ReturnItemRequest struct {
+	ProductID string `json:"product_id" validate:"required,objectid"`
+	Quantity  int    `json:"quantity" validate:"required,min=1"`
+	Reason    string `json:"reason" validate:"required,min=3,max=500"`
+}
+
+// CreateReturnRequest represents the request payload for requesting a return
+type CreateReturnRequest struct {
+	Items  []ReturnItemRequest `json:"items" validate:"required,min=1,dive"`
+	Reason string              `json:"reason" validate:"max=1000"`
+}
+
+// ReturnDecisionRequest represents an admin approval, rejection or receipt note
+type ReturnDecisionRequest struct {
+	Note string `json:"note" validate:"max=1000"`
+}
+
+// ReceiveReturnRequest represents marking returned items as received
+type ReceiveReturnRequest struct {
+	Note    string `json:"note" validate:"max=1000"`
+	Restock *bool  `json:"restock,omitempty"`
+}
+
+// RefundReturnRequest represents issuing a refund for a return.
+// When Amount is omitted the full value of the returned items is refunded.
+type RefundReturnRequest struct {
+	Amount *Money `json:"amount,omitempty"`
+	Note   string `json:"note" validate:"max=1000"`
+}
+
+// ReturnListResponse represents the response for listing returns
+type ReturnListResponse struct {
+	Returns []Return `json:"returns"`
+	Total   int64    `json:"total"`
+	Page    int      `json:"page"`
+	Limit   int      `json:"limit"`
+}
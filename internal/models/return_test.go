@@ -0,0 +1,30 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReturnStatus_CanTransitionTo(t *testing.T) {
+	tests := []struct {
+		from     ReturnStatus
+		to       ReturnStatus
+		expected bool
+	}{
+		{from: ReturnStatusRequested, to: ReturnStatusApproved, expected: true},
+		{from: ReturnStatusApproved, to: ReturnStatusRefunding, expected: true},
+		{from: ReturnStatusReceived, to: ReturnStatusRefunding, expected: true},
+		{from: ReturnStatusRefunding, to: ReturnStatusRefunded, expected: true},
+		{from: ReturnStatusApproved, to: ReturnStatusRefunded, expected: false},
+		{from: ReturnStatusRefunding, to: ReturnStatusRefunding, expected: false},
+		{from: ReturnStatusRefunding, to: ReturnStatusReceived, expected: false},
+		{from: ReturnStatusRefunded, to: ReturnStatusRefunding, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.from)+"->"+string(tt.to), func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.from.CanTransitionTo(tt.to))
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Permission names an action on the admin API
+type Permission string
+
+const (
+	PermDashboardRead   Permission = "dashboard:read"
+	PermProductsWrite   Permission = "products:write"
+	PermSlidersWrite    Permission = "sliders:write"
+	PermPromotionsWrite Permission = "promotions:write"
+	PermReturnsManage   Permission = "returns:manage"
+	PermOrdersRefund    Permission = "orders:refund"
+	PermShippingWrite   Permission = "shipping:write"
+	PermTaxWrite        Permission = "tax:write"
+	PermCurrencyWrite   Permission = "currency:write"
+	PermUsersManage     Permission = "users:manage"
+	PermRolesManage     Permission = "roles:manage"
+	PermAuditRead       Permission = "audit:read"
+	PermAPIKeysManage   Permission = "api_keys:manage"
+)
+
+// AllPermissions lists every permission. The admin role always has all of them.
+var AllPermissions = []Permission{
+	PermDashboardRead,
+	PermProductsWrite,
+	PermSlidersWrite,
+	PermPromotionsWrite,
+	PermReturnsManage,
+	PermOrdersRefund,
+	PermShippingWrite,
+	PermTaxWrite,
+	PermCurrencyWrite,
+	PermUsersManage,
+	PermRolesManage,
+	PermAuditRead,
+	PermAPIKeysManage,
+}
+
+// IsValid checks if the permission exists
+func (p Permission) IsValid() bool {
+	for _, permission := range AllPermissions {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// RoleDefinition bundles the permissions granted to users with a role. Built-in roles
+// (admin and user) cannot be changed or deleted.
+type RoleDefinition struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Name        Role               `bson:"name" json:"name"`
+	Description string             `bson:"description" json:"description"`
+	Permissions []Permission       `bson:"permissions" json:"permissions"`
+	BuiltIn     bool               `bson:"built_in" json:"built_in"`
+	CreatedAt   time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt   time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+// RoleRequest represents the request payload for creating or updating a role. Name is
+// ignored on update.
+type RoleRequest struct {
+	Name        string       `json:"name" validate:"omitempty,min=2,max=50"`
+	Description string       `json:"description" validate:"max=200"`
+	Permissions []Permission `json:"permissions" validate:"required"`
+}
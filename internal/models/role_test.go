@@ -0,0 +1,25 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPermission_IsValid(t *testing.T) {
+	tests := []struct {
+		permission Permission
+		expected   bool
+	}{
+		{permission: PermProductsWrite, expected: true},
+		{permission: PermRolesManage, expected: true},
+		{permission: "products:read", expected: false},
+		{permission: "", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.permission), func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.permission.IsValid())
+		})
+	}
+}
@@ -0,0 +1,19 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Session represents a signed-in device, identified by the ID (jti) of its JWT
+type Session struct {
+	ID        string             `json:"id" bson:"_id"`
+	UserID    primitive.ObjectID `json:"user_id" bson:"user_id"`
+	UserAgent string             `json:"user_agent" bson:"user_agent"`
+	IP        string             `json:"ip" bson:"ip"`
+	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
+	ExpiresAt time.Time          `json:"expires_at" bson:"expires_at"`
+	RevokedAt *time.Time         `json:"revoked_at,omitempty" bson:"revoked_at,omitempty"`
+	IsCurrent bool               `json:"is_current" bson:"-"`
+}
@@ -0,0 +1,116 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ShippingRateType represents how a shipping method is priced
+type ShippingRateType string
+
+const (
+	ShippingFlatRate          ShippingRateType = "flat_rate"
+	ShippingWeightBased       ShippingRateType = "weight_based"
+	ShippingFreeOverThreshold ShippingRateType = "free_over_threshold"
+)
+
+// IsValid checks if the shipping rate type is valid
+func (t ShippingRateType) IsValid() bool {
+	return t == ShippingFlatRate || t == ShippingWeightBased || t == ShippingFreeOverThreshold
+}
+
+// Dimensions represents a parcel's size in centimetres
+type Dimensions struct {
+	Length float64 `json:"length" bson:"length" validate:"gte=0"`
+	Width  float64 `json:"width" bson:"width" validate:"gte=0"`
+	Height float64 `json:"height" bson:"height" validate:"gte=0"`
+}
+
+// ShippingZone groups destinations that share shipping methods.
+// Empty Countries matches every country; Regions and PostcodePatterns narrow the match.
+// Postcode patterns are case-insensitive globs such as "SW1*" or "9021?".
+type ShippingZone struct {
+	ID               primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Name             string             `json:"name" bson:"name"`
+	Countries        []string           `json:"countries" bson:"countries"`
+	Regions          []string           `json:"regions,omitempty" bson:"regions,omitempty"`
+	PostcodePatterns []string           `json:"postcode_patterns,omitempty" bson:"postcode_patterns,omitempty"`
+	Priority         int                `json:"priority" bson:"priority"`
+	IsActive         bool               `json:"is_active" bson:"is_active"`
+	CreatedAt        time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt        time.Time          `json:"updated_at" bson:"updated_at"`
+}
+
+// WeightTier prices parcels up to MaxWeight kilograms
+type WeightTier struct {
+	MaxWeight float64 `json:"max_weight" bson:"max_weight" validate:"gt=0"`
+	Rate      Money   `json:"rate" bson:"rate"`
+}
+
+// ShippingMethod represents a way to ship to a zone and how it is priced
+type ShippingMethod struct {
+	ID            primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	ZoneID        primitive.ObjectID `json:"zone_id" bson:"zone_id"`
+	Name          string             `json:"name" bson:"name"`
+	Type          ShippingRateType   `json:"type" bson:"type"`
+	Rate          Money              `json:"rate" bson:"rate"` // Flat rate, or the rate below the free threshold
+	WeightTiers   []WeightTier       `json:"weight_tiers,omitempty" bson:"weight_tiers,omitempty"`
+	FreeThreshold Money              `json:"free_threshold" bson:"free_threshold"` // Cart value at which shipping becomes free
+	MinDays       int                `json:"min_days" bson:"min_days"`
+	MaxDays       int                `json:"max_days" bson:"max_days"`
+	IsActive      bool               `json:"is_active" bson:"is_active"`
+	CreatedAt     time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt     time.Time          `json:"updated_at" bson:"updated_at"`
+}
+
+// ShippingZoneRequest represents the request payload for creating or replacing a zone
+type ShippingZoneRequest struct {
+	Name             string   `json:"name" validate:"required,min=2,max=100"`
+	Countries        []string `json:"countries" validate:"dive,len=2,alpha"`
+	Regions          []string `json:"regions,omitempty" validate:"dive,min=1,max=100"`
+	PostcodePatterns []string `json:"postcode_patterns,omitempty" validate:"dive,min=1,max=20"`
+	Priority         int      `json:"priority"`
+	IsActive         bool     `json:"is_active"`
+}
+
+// ShippingMethodRequest represents the request payload for creating or replacing a method
+type ShippingMethodRequest struct {
+	ZoneID        string       `json:"zone_id" validate:"required,objectid"`
+	Name          string       `json:"name" validate:"required,min=2,max=100"`
+	Type          string       `json:"type" validate:"required"`
+	Rate          Money        `json:"rate"`
+	WeightTiers   []WeightTier `json:"weight_tiers,omitempty" validate:"dive"`
+	FreeThreshold Money        `json:"free_threshold"`
+	MinDays       int          `json:"min_days" validate:"gte=0"`
+	MaxDays       int          `json:"max_days" validate:"gte=0,gtefield=MinDays"`
+	IsActive      bool         `json:"is_active"`
+}
+
+// ShippingQuoteRequest represents a cart and destination to quote shipping for
+type ShippingQuoteRequest struct {
+	CartRequest
+	Address   *Address `json:"address,omitempty" validate:"required_without=AddressID"`
+	AddressID string   `json:"address_id,omitempty" validate:"omitempty,objectid"` // Saved address of the signed-in user
+}
+
+// ShippingQuote represents one available shipping method and its price
+type ShippingQuote struct {
+	MethodID     primitive.ObjectID `json:"method_id" bson:"method_id"`
+	ZoneID       primitive.ObjectID `json:"zone_id" bson:"zone_id"`
+	Name         string             `json:"name" bson:"name"`
+	Price        Money              `json:"price" bson:"price"`
+	MinDays      int                `json:"min_days" bson:"min_days"`
+	MaxDays      int                `json:"max_days" bson:"max_days"`
+	EarliestDate time.Time          `json:"earliest_date" bson:"earliest_date"`
+	LatestDate   time.Time          `json:"latest_date" bson:"latest_date"`
+}
+
+// ShippingQuoteResponse represents the shipping options for a cart
+type ShippingQuoteResponse struct {
+	Zone         *ShippingZone   `json:"zone"`
+	Weight       float64         `json:"weight"`
+	Subtotal     Money           `json:"subtotal"`
+	FreeShipping bool            `json:"free_shipping"`
+	Methods      []ShippingQuote `json:"methods"`
+}
@@ -0,0 +1,77 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TaxClass represents the tax treatment of a product
+type TaxClass string
+
+const (
+	TaxClassStandard TaxClass = "standard"
+	TaxClassReduced  TaxClass = "reduced"
+	TaxClassZero     TaxClass = "zero"
+)
+
+// IsValid checks if the tax class is valid
+func (c TaxClass) IsValid() bool {
+	return c == TaxClassStandard || c == TaxClassReduced || c == TaxClassZero
+}
+
+// OrDefault returns the class, or standard when it is unset
+func (c TaxClass) OrDefault() TaxClass {
+	if c == "" {
+		return TaxClassStandard
+	}
+	return c
+}
+
+// TaxRate represents the rate charged for a tax class in a country or region.
+// An empty Region applies to the whole country; a region-specific rate takes precedence.
+type TaxRate struct {
+	ID        primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Name      string             `json:"name" bson:"name"`
+	Country   string             `json:"country" bson:"country"`
+	Region    string             `json:"region,omitempty" bson:"region,omitempty"`
+	Class     TaxClass           `json:"class" bson:"class"`
+	Rate      float64            `json:"rate" bson:"rate"` // Percentage, e.g. 20 for 20%
+	IsActive  bool               `json:"is_active" bson:"is_active"`
+	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt time.Time          `json:"updated_at" bson:"updated_at"`
+}
+
+// TaxRateRequest represents the request payload for creating or replacing a tax rate
+type TaxRateRequest struct {
+	Name     string  `json:"name" validate:"required,min=2,max=100"`
+	Country  string  `json:"country" validate:"required,len=2,alpha"`
+	Region   string  `json:"region,omitempty" validate:"max=100"`
+	Class    string  `json:"class" validate:"required"`
+	Rate     float64 `json:"rate" validate:"gte=0,lte=100"`
+	IsActive bool    `json:"is_active"`
+}
+
+// TaxLine represents the tax charged on one cart line or on shipping.
+// Net, Tax and Gross are the amounts after discounts.
+type TaxLine struct {
+	ProductID   primitive.ObjectID `json:"product_id,omitempty" bson:"product_id,omitempty"`
+	Description string             `json:"description" bson:"description"`
+	Quantity    int                `json:"quantity" bson:"quantity"`
+	Class       TaxClass           `json:"class" bson:"class"`
+	Rate        float64            `json:"rate" bson:"rate"`
+	Net         Money              `json:"net" bson:"net"`
+	Tax         Money              `json:"tax" bson:"tax"`
+	Gross       Money              `json:"gross" bson:"gross"`
+}
+
+// TaxBreakdown represents the tax on a cart or order, kept on orders for invoicing
+type TaxBreakdown struct {
+	PricesIncludeTax bool      `json:"prices_include_tax" bson:"prices_include_tax"`
+	Country          string    `json:"country" bson:"country"`
+	Region           string    `json:"region,omitempty" bson:"region,omitempty"`
+	Lines            []TaxLine `json:"lines" bson:"lines"`
+	Net              Money     `json:"net" bson:"net"`
+	Tax              Money     `json:"tax" bson:"tax"`
+	Gross            Money     `json:"gross" bson:"gross"`
+}
@@ -8,15 +8,23 @@ import (
 
 // User represents a user in the system
 type User struct {
-	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
-	Email     string             `bson:"email" json:"email" validate:"required,email"`
-	Password  string             `bson:"password" json:"-"`
-	FirstName string             `bson:"first_name" json:"first_name" validate:"required,min=2,max=50"`
-	LastName  string             `bson:"last_name" json:"last_name" validate:"required,min=2,max=50"`
-	Role      Role               `bson:"role" json:"role"`
-	IsActive  bool               `bson:"is_active" json:"is_active"`
-	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
-	UpdatedAt time.Time          `bson:"updated_at" json:"updated_at"`
+	ID              primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Email           string             `bson:"email" json:"email" validate:"required,email"`
+	Password        string             `bson:"password" json:"-"`
+	FirstName       string             `bson:"first_name" json:"first_name" validate:"required,min=2,max=50"`
+	LastName        string             `bson:"last_name" json:"last_name" validate:"required,min=2,max=50"`
+	Role            Role               `bson:"role" json:"role"`
+	IsActive        bool               `bson:"is_active" json:"is_active"`
+	EmailVerified   bool               `bson:"email_verified" json:"email_verified"`
+	EmailVerifiedAt *time.Time         `bson:"email_verified_at,omitempty" json:"email_verified_at,omitempty"`
+	MFAEnabled      bool               `bson:"mfa_enabled" json:"mfa_enabled"`
+	MFASecret       string             `bson:"mfa_secret,omitempty" json:"-"`
+	MFAPending      string             `bson:"mfa_pending_secret,omitempty" json:"-"` // Secret awaiting its first code during enrolment
+	MFALastStep     int64              `bson:"mfa_last_step,omitempty" json:"-"`      // Time step of the last accepted code, to reject replays
+	RecoveryCodes   []string           `bson:"recovery_codes,omitempty" json:"-"`     // Hashes of the unused recovery codes
+	Identities      []Identity         `bson:"identities,omitempty" json:"identities,omitempty"`
+	CreatedAt       time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt       time.Time          `bson:"updated_at" json:"updated_at"`
 }
 
 // Role represents user roles
@@ -43,7 +51,6 @@ type RegisterRequest struct {
 	Password  string `json:"password" validate:"required,min=6"`
 	FirstName string `json:"first_name" validate:"required,min=2,max=50"`
 	LastName  string `json:"last_name" validate:"required,min=2,max=50"`
-	Role      string `json:"role,omitempty"`
 }
 
 // LoginRequest represents the request payload for user login
@@ -52,6 +59,69 @@ type LoginRequest struct {
 	Password string `json:"password" validate:"required"`
 }
 
+// UpdateProfileRequest represents the request payload for editing the user's names
+type UpdateProfileRequest struct {
+	FirstName *string `json:"first_name,omitempty" validate:"omitempty,min=2,max=50"`
+	LastName  *string `json:"last_name,omitempty" validate:"omitempty,min=2,max=50"`
+}
+
+// ChangePasswordRequest represents the request payload for changing the password
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" validate:"required"`
+	NewPassword     string `json:"new_password" validate:"required,min=6"`
+}
+
+// ChangeEmailRequest represents the request payload for starting an email change
+type ChangeEmailRequest struct {
+	NewEmail string `json:"new_email" validate:"required,email"`
+	Password string `json:"password" validate:"required"`
+}
+
+// ConfirmEmailChangeRequest represents the request payload for confirming an email change
+type ConfirmEmailChangeRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+// ForgotPasswordRequest represents the request payload for requesting a password reset link
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+// ResetPasswordRequest represents the request payload for setting a new password with a reset token
+type ResetPasswordRequest struct {
+	Token       string `json:"token" validate:"required"`
+	NewPassword string `json:"new_password" validate:"required,min=6"`
+}
+
+// MFASetupRequest represents the request payload for starting two-factor enrolment
+type MFASetupRequest struct {
+	Password string `json:"password" validate:"required"`
+}
+
+// MFACodeRequest represents a request confirmed with an authenticator code
+type MFACodeRequest struct {
+	Code string `json:"code" validate:"required,len=6,numeric"`
+}
+
+// MFADisableRequest represents the request payload for turning off two-factor authentication.
+// Code accepts an authenticator code or a recovery code.
+type MFADisableRequest struct {
+	Password string `json:"password" validate:"required"`
+	Code     string `json:"code" validate:"required"`
+}
+
+// MFALoginRequest represents the second step of a login with two-factor authentication.
+// Code accepts an authenticator code or a recovery code.
+type MFALoginRequest struct {
+	MFAToken string `json:"mfa_token" validate:"required"`
+	Code     string `json:"code" validate:"required"`
+}
+
+// UpdateUserRoleRequest represents the request payload for changing a user's role
+type UpdateUserRoleRequest struct {
+	Role Role `json:"role" validate:"required"`
+}
+
 // AuthResponse represents the response payload for authentication
 type AuthResponse struct {
 	Token string       `json:"token"`
@@ -64,28 +134,59 @@ type LoginResponse struct {
 	Role  Role   `json:"role"`
 }
 
+// MFAChallengeResponse is returned by login instead of a token when the account has
+// two-factor authentication enabled
+type MFAChallengeResponse struct {
+	MFARequired bool      `json:"mfa_required"`
+	MFAToken    string    `json:"mfa_token"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// MFASetupResponse carries a new secret for the user to add to their authenticator app
+type MFASetupResponse struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"` // otpauth:// URI to render as a QR code
+}
+
+// RecoveryCodesResponse carries recovery codes, shown only once
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
 // UserResponse represents a user response without sensitive data
 type UserResponse struct {
-	ID        primitive.ObjectID `json:"id"`
-	Email     string             `json:"email"`
-	FirstName string             `json:"first_name"`
-	LastName  string             `json:"last_name"`
-	Role      Role               `json:"role"`
-	IsActive  bool               `json:"is_active"`
-	CreatedAt time.Time          `json:"created_at"`
-	UpdatedAt time.Time          `json:"updated_at"`
+	ID            primitive.ObjectID `json:"id"`
+	Email         string             `json:"email"`
+	FirstName     string             `json:"first_name"`
+	LastName      string             `json:"last_name"`
+	Role          Role               `json:"role"`
+	IsActive      bool               `json:"is_active"`
+	EmailVerified bool               `json:"email_verified"`
+	MFAEnabled    bool               `json:"mfa_enabled"`
+	CreatedAt     time.Time          `json:"created_at"`
+	UpdatedAt     time.Time          `json:"updated_at"`
+}
+
+// UserListResponse represents the response for listing users
+type UserListResponse struct {
+	Users []UserResponse `json:"users"`
+	Total int64          `json:"total"`
+	Page  int            `json:"page"`
+	Limit int            `json:"limit"`
 }
 
 // ToResponse converts User to UserResponse
 func (u *User) ToResponse() UserResponse {
 	return UserResponse{
-		ID:        u.ID,
-		Email:     u.Email,
-		FirstName: u.FirstName,
-		LastName:  u.LastName,
-		Role:      u.Role,
-		IsActive:  u.IsActive,
-		CreatedAt: u.CreatedAt,
-		UpdatedAt: u.UpdatedAt,
+		ID:            u.ID,
+		Email:         u.Email,
+		FirstName:     u.FirstName,
+		LastName:      u.LastName,
+		Role:          u.Role,
+		IsActive:      u.IsActive,
+		EmailVerified: u.EmailVerified,
+		MFAEnabled:    u.MFAEnabled,
+		CreatedAt:     u.CreatedAt,
+		UpdatedAt:     u.UpdatedAt,
 	}
 }
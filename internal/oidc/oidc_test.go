@@ -0,0 +1,277 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"ecommerce-backend/internal/config"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockServer is a minimal OpenID Connect provider. Codes handed out by authorize are
+// redeemed at the token endpoint for an ID token signed with key.
+type mockServer struct {
+	*httptest.Server
+	key   *rsa.PrivateKey
+	kid   string
+	codes map[string]url.Values // Authorization request by code
+	// claims lets a test alter the ID token issued for a request
+	claims func(jwt.MapClaims)
+}
+
+func newMockServer(t *testing.T) *mockServer {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	m := &mockServer{key: key, kid: "key-1", codes: make(map[string]url.Values)}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 m.URL,
+			"authorization_endpoint": m.URL + "/authorize",
+			"token_endpoint":         m.URL + "/token",
+			"jwks_uri":               m.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": m.kid,
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(m.key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(m.key.E)).Bytes()),
+			}},
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		request, ok := m.codes[r.PostForm.Get("code")]
+		if !ok || CodeChallenge(r.PostForm.Get("code_verifier")) != request.Get("code_challenge") ||
+			r.PostForm.Get("redirect_uri") != request.Get("redirect_uri") {
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+		delete(m.codes, r.PostForm.Get("code"))
+
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"access_token": "access-token",
+			"token_type":   "Bearer",
+			"id_token":     m.idToken(t, request.Get("client_id"), request.Get("nonce")),
+		})
+	})
+	m.Server = httptest.NewServer(mux)
+	t.Cleanup(m.Close)
+	return m
+}
+
+// authorize simulates the user approving the request at the authorization URL
+func (m *mockServer) authorize(t *testing.T, authURL string) (code, state string) {
+	parsed, err := url.Parse(authURL)
+	require.NoError(t, err)
+	code = "code-" + parsed.Query().Get("state")
+	m.codes[code] = parsed.Query()
+	return code, parsed.Query().Get("state")
+}
+
+func (m *mockServer) idToken(t *testing.T, clientID, nonce string) string {
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss":            m.URL,
+		"sub":            "subject-1",
+		"aud":            clientID,
+		"exp":            now.Add(time.Hour).Unix(),
+		"iat":            now.Unix(),
+		"nonce":          nonce,
+		"email":          "user@example.com",
+		"email_verified": true,
+		"given_name":     "Jane",
+		"family_name":    "Doe",
+	}
+	if m.claims != nil {
+		m.claims(claims)
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = m.kid
+	signed, err := token.SignedString(m.key)
+	require.NoError(t, err)
+	return signed
+}
+
+func newTestProvider(m *mockServer) *Provider {
+	return NewProvider(config.OIDCProviderConfig{
+		Name:        "mock",
+		Issuer:      m.URL,
+		ClientID:    "client-1",
+		RedirectURL: "http://localhost:3000/callback",
+	}, m.Client())
+}
+
+func TestProvider_Flow(t *testing.T) {
+	m := newMockServer(t)
+	provider := newTestProvider(m)
+	ctx := context.Background()
+
+	verifier, err := NewCodeVerifier()
+	require.NoError(t, err)
+
+	authURL, err := provider.AuthCodeURL(ctx, "state-1", "nonce-1", CodeChallenge(verifier))
+	require.NoError(t, err)
+
+	parsed, err := url.Parse(authURL)
+	require.NoError(t, err)
+	assert.Equal(t, m.URL+"/authorize", parsed.Scheme+"://"+parsed.Host+parsed.Path)
+	assert.Equal(t, "code", parsed.Query().Get("response_type"))
+	assert.Equal(t, "client-1", parsed.Query().Get("client_id"))
+	assert.Equal(t, "openid email profile", parsed.Query().Get("scope"))
+	assert.Equal(t, "S256", parsed.Query().Get("code_challenge_method"))
+
+	code, state := m.authorize(t, authURL)
+	assert.Equal(t, "state-1", state)
+
+	token, err := provider.Exchange(ctx, code, verifier)
+	require.NoError(t, err)
+
+	claims, err := provider.VerifyIDToken(ctx, token.IDToken, "nonce-1")
+	require.NoError(t, err)
+	assert.Equal(t, "subject-1", claims.Subject)
+	assert.Equal(t, "user@example.com", claims.Email)
+	assert.True(t, bool(claims.EmailVerified))
+	assert.Equal(t, "Jane", claims.GivenName)
+}
+
+func TestProvider_ExchangeRejectsWrongVerifier(t *testing.T) {
+	m := newMockServer(t)
+	provider := newTestProvider(m)
+	ctx := context.Background()
+
+	verifier, err := NewCodeVerifier()
+	require.NoError(t, err)
+	authURL, err := provider.AuthCodeURL(ctx, "state-1", "nonce-1", CodeChallenge(verifier))
+	require.NoError(t, err)
+	code, _ := m.authorize(t, authURL)
+
+	_, err = provider.Exchange(ctx, code, "wrong-verifier")
+	assert.Error(t, err)
+}
+
+func TestProvider_VerifyIDToken(t *testing.T) {
+	other, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		nonce  string
+		claims func(jwt.MapClaims)
+		sign   func(m *mockServer, token *jwt.Token) (string, error)
+		valid  bool
+	}{
+		{name: "valid", nonce: "nonce-1", valid: true},
+		{name: "wrong nonce", nonce: "nonce-2"},
+		{name: "wrong audience", nonce: "nonce-1", claims: func(c jwt.MapClaims) { c["aud"] = "client-2" }},
+		{name: "wrong issuer", nonce: "nonce-1", claims: func(c jwt.MapClaims) { c["iss"] = "https://evil.example.com" }},
+		{name: "expired", nonce: "nonce-1", claims: func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-time.Hour).Unix() }},
+		{name: "missing expiry", nonce: "nonce-1", claims: func(c jwt.MapClaims) { delete(c, "exp") }},
+		{name: "missing subject", nonce: "nonce-1", claims: func(c jwt.MapClaims) { delete(c, "sub") }},
+		{
+			name:   "other audience authorized",
+			nonce:  "nonce-1",
+			claims: func(c jwt.MapClaims) { c["aud"] = []string{"client-1", "client-2"}; c["azp"] = "client-2" },
+		},
+		{
+			name:   "several audiences",
+			nonce:  "nonce-1",
+			claims: func(c jwt.MapClaims) { c["aud"] = []string{"client-1", "client-2"}; c["azp"] = "client-1" },
+			valid:  true,
+		},
+		{
+			name:  "string email_verified",
+			nonce: "nonce-1",
+			claims: func(c jwt.MapClaims) {
+				c["email_verified"] = "true"
+			},
+			valid: true,
+		},
+		{
+			name:  "unknown signing key",
+			nonce: "nonce-1",
+			sign: func(m *mockServer, token *jwt.Token) (string, error) {
+				return token.SignedString(other)
+			},
+		},
+		{
+			name:  "symmetric algorithm",
+			nonce: "nonce-1",
+			sign: func(m *mockServer, token *jwt.Token) (string, error) {
+				hmac := jwt.NewWithClaims(jwt.SigningMethodHS256, token.Claims)
+				hmac.Header["kid"] = m.kid
+				return hmac.SignedString([]byte("client-secret"))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newMockServer(t)
+			m.claims = tt.claims
+			provider := newTestProvider(m)
+
+			raw := m.idToken(t, "client-1", "nonce-1")
+			if tt.sign != nil {
+				parsed, _, err := jwt.NewParser().ParseUnverified(raw, jwt.MapClaims{})
+				require.NoError(t, err)
+				raw, err = tt.sign(m, parsed)
+				require.NoError(t, err)
+			}
+
+			claims, err := provider.VerifyIDToken(context.Background(), raw, tt.nonce)
+			if tt.valid {
+				require.NoError(t, err)
+				assert.Equal(t, "subject-1", claims.Subject)
+				assert.True(t, bool(claims.EmailVerified))
+			} else {
+				assert.ErrorIs(t, err, ErrInvalidIDToken)
+			}
+		})
+	}
+}
+
+func TestProvider_DiscoveryIssuerMismatch(t *testing.T) {
+	m := newMockServer(t)
+	provider := NewProvider(config.OIDCProviderConfig{Name: "mock", Issuer: m.URL + "/other", ClientID: "client-1"}, m.Client())
+
+	_, err := provider.AuthCodeURL(context.Background(), "state", "nonce", "challenge")
+	assert.Error(t, err)
+}
+
+func TestRegistry(t *testing.T) {
+	registry := NewRegistry([]config.OIDCProviderConfig{{Name: "google"}, {Name: "apple"}}, nil)
+
+	assert.Equal(t, []string{"apple", "google"}, registry.Names())
+
+	provider, err := registry.Get("google")
+	require.NoError(t, err)
+	assert.Equal(t, "google", provider.Name())
+
+	_, err = registry.Get("github")
+	assert.ErrorIs(t, err, ErrUnknownProvider)
+}
+
+func TestCodeChallenge(t *testing.T) {
+	// RFC 7636 appendix B
+	assert.Equal(t, "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM", CodeChallenge("dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"))
+}
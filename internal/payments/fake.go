@@ -0,0 +1,214 @@
+package payments
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"ecommerce-backend/internal/models"
+)
+
+const (
+	// FakeProviderName is the name the fake provider registers under
+	FakeProviderName = "fake"
+	// FakeSignatureHeader carries the webhook signature as "t=<unix>,v1=<hex hmac>"
+	FakeSignatureHeader = "X-Fake-Signature"
+	// fakeSignatureTolerance bounds how old a signed webhook may be
+	fakeSignatureTolerance = 5 * time.Minute
+)
+
+// FakeProvider is an in-memory payment provider for local development and tests.
+// It never moves money; webhooks are signed with HMAC-SHA256 like real gateways.
+type FakeProvider struct {
+	secret  []byte
+	now     func() time.Time
+	mu      sync.Mutex
+	intents map[string]*Intent
+}
+
+// fakeWebhookPayload is the JSON body the fake provider sends to the webhook.
+// Amounts are in minor units, as with most real gateways.
+type fakeWebhookPayload struct {
+	ID       string `json:"id"`
+	Type     string `json:"type"`
+	IntentID string `json:"intent_id"`
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
+	Created  int64  `json:"created"`
+}
+
+// NewFakeProvider creates a fake provider that signs webhooks with secret
+func NewFakeProvider(secret string) *FakeProvider {
+	return &FakeProvider{
+		secret:  []byte(secret),
+		now:     time.Now,
+		intents: make(map[string]*Intent),
+	}
+}
+
+// Name returns the provider name
+func (f *FakeProvider) Name() string {
+	return FakeProviderName
+}
+
+// CreateIntent creates an intent awaiting payment
+func (f *FakeProvider) CreateIntent(ctx context.Context, req IntentRequest) (*Intent, error) {
+	if !req.Amount.IsPositive() {
+		return nil, ErrInvalidAmount
+	}
+
+	intent := &Intent{
+		ID:             "pi_fake_" + randomHex(12),
+		ClientSecret:   "secret_fake_" + randomHex(16),
+		Amount:         req.Amount,
+		AmountCaptured: models.NewMoney(0, req.Amount.Currency),
+		AmountRefunded: models.NewMoney(0, req.Amount.Currency),
+		Status:         IntentRequiresPayment,
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.intents[intent.ID] = intent
+
+	copied := *intent
+	return &copied, nil
+}
+
+// Capture captures up to the intent amount
+func (f *FakeProvider) Capture(ctx context.Context, intentID string, amount models.Money) (*Intent, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	intent, ok := f.intents[intentID]
+	if !ok {
+		return nil, ErrIntentNotFound
+	}
+	if !amount.IsPositive() || amount.Currency != intent.Amount.Currency || amount.Amount > intent.Amount.Sub(intent.AmountCaptured).Amount {
+		return nil, ErrInvalidAmount
+	}
+
+	intent.AmountCaptured = intent.AmountCaptured.Add(amount)
+	intent.Status = IntentSucceeded
+
+	copied := *intent
+	return &copied, nil
+}
+
+// Refund refunds up to the captured amount that has not been refunded yet.
+// Intents unknown to this process (e.g. after a restart) are refunded as-is.
+func (f *FakeProvider) Refund(ctx context.Context, intentID string, amount models.Money, reason string) (*Refund, error) {
+	if !amount.IsPositive() {
+		return nil, ErrInvalidAmount
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if intent, ok := f.intents[intentID]; ok {
+		captured := intent.AmountCaptured.Max(intent.Amount)
+		if amount.Currency != intent.Amount.Currency || amount.Amount > captured.Sub(intent.AmountRefunded).Amount {
+			return nil, ErrInvalidAmount
+		}
+		intent.AmountRefunded = intent.AmountRefunded.Add(amount)
+	}
+
+	return &Refund{
+		ID:       "re_fake_" + randomHex(12),
+		IntentID: intentID,
+		Amount:   amount,
+		Reason:   reason,
+	}, nil
+}
+
+// VerifyWebhook checks the signature header and decodes the event
+func (f *FakeProvider) VerifyWebhook(payload []byte, header http.Header) (*Event, error) {
+	timestamp, signature, err := parseFakeSignature(header.Get(FakeSignatureHeader))
+	if err != nil {
+		return nil, ErrInvalidSignature
+	}
+
+	signedAt := time.Unix(timestamp, 0)
+	if age := f.now().Sub(signedAt); age > fakeSignatureTolerance || age < -fakeSignatureTolerance {
+		return nil, ErrInvalidSignature
+	}
+
+	expected := f.sign(payload, timestamp)
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return nil, ErrInvalidSignature
+	}
+
+	var body fakeWebhookPayload
+	if err := json.Unmarshal(payload, &body); err != nil {
+		return nil, fmt.Errorf("invalid webhook payload: %w", err)
+	}
+	if body.ID == "" || body.IntentID == "" {
+		return nil, fmt.Errorf("invalid webhook payload: missing event or intent ID")
+	}
+
+	return &Event{
+		ID:        body.ID,
+		Type:      models.PaymentEventType(body.Type),
+		IntentID:  body.IntentID,
+		Amount:    models.NewMoney(body.Amount, body.Currency),
+		CreatedAt: time.Unix(body.Created, 0),
+	}, nil
+}
+
+// SignPayload returns the signature header value for a payload, for simulating webhooks
+func (f *FakeProvider) SignPayload(payload []byte, at time.Time) string {
+	timestamp := at.Unix()
+	return fmt.Sprintf("t=%d,v1=%s", timestamp, f.sign(payload, timestamp))
+}
+
+// sign computes the hex HMAC over "<timestamp>.<payload>"
+func (f *FakeProvider) sign(payload []byte, timestamp int64) string {
+	mac := hmac.New(sha256.New, f.secret)
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// parseFakeSignature splits a "t=<unix>,v1=<hex>" header
+func parseFakeSignature(value string) (int64, string, error) {
+	var timestamp int64
+	var signature string
+	for _, part := range strings.Split(value, ",") {
+		key, val, found := strings.Cut(strings.TrimSpace(part), "=")
+		if !found {
+			continue
+		}
+		switch key {
+		case "t":
+			parsed, err := strconv.ParseInt(val, 10, 64)
+			if err != nil {
+				return 0, "", err
+			}
+			timestamp = parsed
+		case "v1":
+			signature = val
+		}
+	}
+	if timestamp == 0 || signature == "" {
+		return 0, "", ErrInvalidSignature
+	}
+	return timestamp, signature, nil
+}
+
+// randomHex returns n random bytes encoded as hex
+func randomHex(n int) string {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,109 @@
+package payments
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"ecommerce-backend/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFakeProvider_VerifyWebhook(t *testing.T) {
+	provider := NewFakeProvider("test-webhook-secret")
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	provider.now = func() time.Time { return now }
+
+	payload := []byte(`{"id":"evt_1","type":"payment.succeeded","intent_id":"pi_1","amount":4250,"currency":"USD","created":1717243200}`)
+
+	tests := []struct {
+		name      string
+		payload   []byte
+		signature string
+		wantErr   error
+	}{
+		{
+			name:      "valid signature",
+			payload:   payload,
+			signature: provider.SignPayload(payload, now),
+		},
+		{
+			name:      "tampered payload",
+			payload:   []byte(`{"id":"evt_1","type":"payment.succeeded","intent_id":"pi_1","amount":1,"currency":"USD","created":1717243200}`),
+			signature: provider.SignPayload(payload, now),
+			wantErr:   ErrInvalidSignature,
+		},
+		{
+			name:      "signed with another secret",
+			payload:   payload,
+			signature: NewFakeProvider("other-secret").SignPayload(payload, now),
+			wantErr:   ErrInvalidSignature,
+		},
+		{
+			name:      "signature too old",
+			payload:   payload,
+			signature: provider.SignPayload(payload, now.Add(-10*time.Minute)),
+			wantErr:   ErrInvalidSignature,
+		},
+		{
+			name:      "missing signature",
+			payload:   payload,
+			signature: "",
+			wantErr:   ErrInvalidSignature,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			header.Set(FakeSignatureHeader, tt.signature)
+
+			event, err := provider.VerifyWebhook(tt.payload, header)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Nil(t, event)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "evt_1", event.ID)
+			assert.Equal(t, models.PaymentEventSucceeded, event.Type)
+			assert.Equal(t, "pi_1", event.IntentID)
+			assert.Equal(t, models.NewMoney(4250, "USD"), event.Amount)
+		})
+	}
+}
+
+func TestFakeProvider_CaptureAndRefund(t *testing.T) {
+	provider := NewFakeProvider("secret")
+	ctx := context.Background()
+
+	usd := func(amount int64) models.Money { return models.NewMoney(amount, "USD") }
+
+	intent, err := provider.CreateIntent(ctx, IntentRequest{OrderID: "order-1", Amount: usd(10000)})
+	require.NoError(t, err)
+	assert.Equal(t, IntentRequiresPayment, intent.Status)
+	assert.NotEmpty(t, intent.ClientSecret)
+
+	_, err = provider.Capture(ctx, intent.ID, usd(15000))
+	assert.ErrorIs(t, err, ErrInvalidAmount)
+
+	captured, err := provider.Capture(ctx, intent.ID, usd(10000))
+	require.NoError(t, err)
+	assert.Equal(t, IntentSucceeded, captured.Status)
+
+	_, err = provider.Refund(ctx, intent.ID, usd(6000), "damaged")
+	require.NoError(t, err)
+
+	_, err = provider.Refund(ctx, intent.ID, usd(5000), "damaged")
+	assert.ErrorIs(t, err, ErrInvalidAmount)
+
+	_, err = provider.Capture(ctx, "pi_unknown", usd(1000))
+	assert.ErrorIs(t, err, ErrIntentNotFound)
+
+	_, err = provider.CreateIntent(ctx, IntentRequest{Amount: usd(0)})
+	assert.ErrorIs(t, err, ErrInvalidAmount)
+}
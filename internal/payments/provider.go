@@ -0,0 +1,114 @@
+package payments
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"ecommerce-backend/internal/models"
+)
+
+var (
+	// ErrUnknownProvider is returned when no provider is registered under a name
+	ErrUnknownProvider = errors.New("unknown payment provider")
+	// ErrInvalidSignature is returned when a webhook signature does not verify
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+	// ErrIntentNotFound is returned when a provider does not know a payment intent
+	ErrIntentNotFound = errors.New("payment intent not found")
+	// ErrInvalidAmount is returned when a capture or refund amount is out of range
+	ErrInvalidAmount = errors.New("invalid payment amount")
+)
+
+// IntentStatus represents the state of a payment intent at the provider
+type IntentStatus string
+
+const (
+	IntentRequiresPayment IntentStatus = "requires_payment"
+	IntentRequiresCapture IntentStatus = "requires_capture"
+	IntentSucceeded       IntentStatus = "succeeded"
+	IntentCancelled       IntentStatus = "cancelled"
+)
+
+// IntentRequest describes a payment the provider should prepare
+type IntentRequest struct {
+	OrderID string
+	Amount  models.Money
+}
+
+// Intent represents a payment intent at the provider
+type Intent struct {
+	ID             string
+	ClientSecret   string
+	Amount         models.Money
+	AmountCaptured models.Money
+	AmountRefunded models.Money
+	Status         IntentStatus
+}
+
+// Refund represents a refund issued by the provider
+type Refund struct {
+	ID       string
+	IntentID string
+	Amount   models.Money
+	Reason   string
+}
+
+// Event is a verified webhook event translated into our event types
+type Event struct {
+	ID        string
+	Type      models.PaymentEventType
+	IntentID  string
+	Amount    models.Money
+	CreatedAt time.Time
+}
+
+// PaymentProvider is implemented by every payment gateway integration
+type PaymentProvider interface {
+	// Name returns the identifier used in webhook URLs and stored on orders
+	Name() string
+	// CreateIntent prepares a payment the customer can complete on the client
+	CreateIntent(ctx context.Context, req IntentRequest) (*Intent, error)
+	// Capture captures a previously authorized amount
+	Capture(ctx context.Context, intentID string, amount models.Money) (*Intent, error)
+	// Refund returns some or all of a captured amount to the customer
+	Refund(ctx context.Context, intentID string, amount models.Money, reason string) (*Refund, error)
+	// VerifyWebhook checks the signature of a webhook request and parses its event
+	VerifyWebhook(payload []byte, header http.Header) (*Event, error)
+}
+
+// Registry looks up payment providers by name
+type Registry struct {
+	providers       map[string]PaymentProvider
+	defaultProvider string
+}
+
+// NewRegistry creates a registry that uses defaultProvider for new orders
+func NewRegistry(defaultProvider string, providers ...PaymentProvider) (*Registry, error) {
+	r := &Registry{
+		providers:       make(map[string]PaymentProvider),
+		defaultProvider: defaultProvider,
+	}
+	for _, p := range providers {
+		r.providers[p.Name()] = p
+	}
+	if _, ok := r.providers[defaultProvider]; !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownProvider, defaultProvider)
+	}
+	return r, nil
+}
+
+// Get returns the provider registered under name
+func (r *Registry) Get(name string) (PaymentProvider, error) {
+	p, ok := r.providers[name]
+	if !ok {
+		return nil, ErrUnknownProvider
+	}
+	return p, nil
+}
+
+// Default returns the provider used for new orders
+func (r *Registry) Default() (PaymentProvider, error) {
+	return r.Get(r.defaultProvider)
+}
@@ -0,0 +1,51 @@
+package payments
+
+import (
+	"ecommerce-backend/internal/models"
+)
+
+// NextPaymentStatus returns the payment status an order moves to when a webhook event
+// arrives. The boolean is false when the event must not change the order, either because
+// it was already applied or because it arrived out of order (e.g. a failure after success).
+//
+// A payment that does not match the order total, in amount or currency, leaves the order
+// flagged as an amount mismatch rather than paid. Refund events carry the total amount
+// refunded so far, so replaying them is harmless; refunds in another currency than the
+// order's are ignored.
+func NextPaymentStatus(current models.PaymentStatus, orderTotal models.Money, event Event) (models.PaymentStatus, bool) {
+	switch event.Type {
+	case models.PaymentEventAuthorized:
+		if current == models.PaymentStatusPending || current == models.PaymentStatusFailed {
+			return models.PaymentStatusAuthorized, true
+		}
+	case models.PaymentEventSucceeded:
+		switch current {
+		case models.PaymentStatusPending, models.PaymentStatusAuthorized, models.PaymentStatusFailed:
+			if event.Amount != orderTotal {
+				return models.PaymentStatusAmountMismatch, true
+			}
+			return models.PaymentStatusPaid, true
+		}
+	case models.PaymentEventFailed:
+		if current == models.PaymentStatusPending || current == models.PaymentStatusAuthorized {
+			return models.PaymentStatusFailed, true
+		}
+	case models.PaymentEventRefunded:
+		if event.Amount.Currency != orderTotal.Currency {
+			break
+		}
+		switch current {
+		case models.PaymentStatusPaid, models.PaymentStatusPartiallyRefunded, models.PaymentStatusAmountMismatch:
+			return RefundStatus(orderTotal, event.Amount), true
+		}
+	}
+	return current, false
+}
+
+// RefundStatus returns the payment status for an order with the given refunded total
+func RefundStatus(orderTotal, refunded models.Money) models.PaymentStatus {
+	if refunded.Amount >= orderTotal.Amount {
+		return models.PaymentStatusRefunded
+	}
+	return models.PaymentStatusPartiallyRefunded
+}
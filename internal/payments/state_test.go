@@ -0,0 +1,41 @@
+package payments
+
+import (
+	"testing"
+
+	"ecommerce-backend/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNextPaymentStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		current  models.PaymentStatus
+		event    Event
+		expected models.PaymentStatus
+		changed  bool
+	}{
+		{"pending to paid", models.PaymentStatusPending, Event{Type: models.PaymentEventSucceeded, Amount: models.NewMoney(10000, "USD")}, models.PaymentStatusPaid, true},
+		{"underpayment is flagged", models.PaymentStatusPending, Event{Type: models.PaymentEventSucceeded, Amount: models.NewMoney(100, "USD")}, models.PaymentStatusAmountMismatch, true},
+		{"payment in another currency is flagged", models.PaymentStatusAuthorized, Event{Type: models.PaymentEventSucceeded, Amount: models.NewMoney(10000, "EUR")}, models.PaymentStatusAmountMismatch, true},
+		{"pending to authorized", models.PaymentStatusPending, Event{Type: models.PaymentEventAuthorized}, models.PaymentStatusAuthorized, true},
+		{"failed then retried successfully", models.PaymentStatusFailed, Event{Type: models.PaymentEventSucceeded, Amount: models.NewMoney(10000, "USD")}, models.PaymentStatusPaid, true},
+		{"duplicate success is a no-op", models.PaymentStatusPaid, Event{Type: models.PaymentEventSucceeded, Amount: models.NewMoney(10000, "USD")}, models.PaymentStatusPaid, false},
+		{"failure after success is ignored", models.PaymentStatusPaid, Event{Type: models.PaymentEventFailed}, models.PaymentStatusPaid, false},
+		{"partial refund", models.PaymentStatusPaid, Event{Type: models.PaymentEventRefunded, Amount: models.NewMoney(4000, "USD")}, models.PaymentStatusPartiallyRefunded, true},
+		{"full refund", models.PaymentStatusPartiallyRefunded, Event{Type: models.PaymentEventRefunded, Amount: models.NewMoney(10000, "USD")}, models.PaymentStatusRefunded, true},
+		{"refund of a mismatched payment", models.PaymentStatusAmountMismatch, Event{Type: models.PaymentEventRefunded, Amount: models.NewMoney(100, "USD")}, models.PaymentStatusPartiallyRefunded, true},
+		{"refund in another currency is ignored", models.PaymentStatusPaid, Event{Type: models.PaymentEventRefunded, Amount: models.NewMoney(10000, "EUR")}, models.PaymentStatusPaid, false},
+		{"refund before payment is ignored", models.PaymentStatusPending, Event{Type: models.PaymentEventRefunded, Amount: models.NewMoney(10000, "USD")}, models.PaymentStatusPending, false},
+		{"unknown event is ignored", models.PaymentStatusPending, Event{Type: "payment.unknown"}, models.PaymentStatusPending, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next, changed := NextPaymentStatus(tt.current, models.NewMoney(10000, "USD"), tt.event)
+			assert.Equal(t, tt.expected, next)
+			assert.Equal(t, tt.changed, changed)
+		})
+	}
+}
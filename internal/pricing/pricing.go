@@ -0,0 +1,138 @@
+package pricing
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+
+	"ecommerce-backend/internal/models"
+)
+
+// ErrUnsupportedCurrency is returned when prices cannot be shown in the requested currency
+var ErrUnsupportedCurrency = errors.New("unsupported currency")
+
+// PriceList prices the catalogue in one currency. Products use their explicit price in
+// that currency when they have one; otherwise the base price is converted at the rate.
+type PriceList struct {
+	Base     string  // Store currency
+	Currency string  // Currency prices are shown and charged in
+	Rate     float64 // Units of Currency per unit of Base
+}
+
+// NewPriceList selects the exchange rate for a currency. The store currency is always
+// supported; any other currency needs an exchange rate from the store currency.
+func NewPriceList(base, currency string, rates []models.ExchangeRate) (PriceList, error) {
+	base = strings.ToUpper(base)
+	currency = strings.ToUpper(currency)
+	if currency == "" || currency == base {
+		return PriceList{Base: base, Currency: base, Rate: 1}, nil
+	}
+
+	for _, rate := range rates {
+		if strings.EqualFold(rate.Base, base) && strings.EqualFold(rate.Currency, currency) && rate.Rate > 0 {
+			return PriceList{Base: base, Currency: currency, Rate: rate.Rate}, nil
+		}
+	}
+	return PriceList{}, ErrUnsupportedCurrency
+}
+
+// Price returns the product's price in the list currency. It is false when the product
+// has no price in the store currency to convert from.
+func (l PriceList) Price(product models.Product) (models.Money, bool) {
+	for _, price := range product.Prices {
+		if strings.EqualFold(price.Currency, l.Currency) {
+			return models.NewMoney(price.Amount, l.Currency), true
+		}
+	}
+	if product.Price.Currency != l.Base {
+		return models.Money{}, false
+	}
+	return l.Convert(product.Price), true
+}
+
+// Convert converts an amount in the store currency into the list currency. Amounts in
+// any other currency are returned unchanged.
+func (l PriceList) Convert(m models.Money) models.Money {
+	if m.Currency != l.Base || l.Currency == l.Base {
+		return m
+	}
+	return m.Convert(l.Currency, l.Rate)
+}
+
+// Promotion converts the fixed amounts of a promotion into the list currency
+func (l PriceList) Promotion(p models.Promotion) models.Promotion {
+	p.Amount = l.Convert(p.Amount)
+	p.MinCartValue = l.Convert(p.MinCartValue)
+	return p
+}
+
+// ShippingMethod converts the rates and free shipping threshold of a method into the
+// list currency
+func (l PriceList) ShippingMethod(m models.ShippingMethod) models.ShippingMethod {
+	m.Rate = l.Convert(m.Rate)
+	m.FreeThreshold = l.Convert(m.FreeThreshold)
+
+	tiers := make([]models.WeightTier, len(m.WeightTiers))
+	for i, tier := range m.WeightTiers {
+		tier.Rate = l.Convert(tier.Rate)
+		tiers[i] = tier
+	}
+	m.WeightTiers = tiers
+	return m
+}
+
+// ParseAcceptCurrency returns the currencies of an Accept-Currency header, e.g.
+// "EUR, GBP;q=0.8", in order of preference. Entries with q=0 are dropped.
+func ParseAcceptCurrency(header string) []string {
+	type entry struct {
+		currency string
+		q        float64
+	}
+
+	var entries []entry
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(part, ";")
+		currency := strings.ToUpper(strings.TrimSpace(fields[0]))
+		if currency == "" {
+			continue
+		}
+
+		q := 1.0
+		for _, param := range fields[1:] {
+			key, value, found := strings.Cut(strings.TrimSpace(param), "=")
+			if found && strings.TrimSpace(key) == "q" {
+				if parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil {
+					q = parsed
+				}
+			}
+		}
+		if q > 0 {
+			entries = append(entries, entry{currency: currency, q: q})
+		}
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].q > entries[j].q
+	})
+
+	currencies := make([]string, len(entries))
+	for i, e := range entries {
+		currencies[i] = e.currency
+	}
+	return currencies
+}
+
+// Negotiate builds the price list for the first supported currency of the client's
+// preferences, falling back to the store currency when there are none
+func Negotiate(base string, preferred []string, rates []models.ExchangeRate) (PriceList, error) {
+	if len(preferred) == 0 {
+		return NewPriceList(base, base, rates)
+	}
+	for _, currency := range preferred {
+		if list, err := NewPriceList(base, currency, rates); err == nil {
+			return list, nil
+		}
+	}
+	return PriceList{}, ErrUnsupportedCurrency
+}
@@ -0,0 +1,96 @@
+package pricing
+
+import (
+	"testing"
+
+	"ecommerce-backend/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var rates = []models.ExchangeRate{
+	{Base: "USD", Currency: "EUR", Rate: 0.92},
+	{Base: "USD", Currency: "JPY", Rate: 150},
+	{Base: "GBP", Currency: "CHF", Rate: 1.1},
+}
+
+func TestNewPriceList(t *testing.T) {
+	list, err := NewPriceList("USD", "", rates)
+	require.NoError(t, err)
+	assert.Equal(t, PriceList{Base: "USD", Currency: "USD", Rate: 1}, list)
+
+	list, err = NewPriceList("usd", "eur", rates)
+	require.NoError(t, err)
+	assert.Equal(t, PriceList{Base: "USD", Currency: "EUR", Rate: 0.92}, list)
+
+	_, err = NewPriceList("USD", "CHF", rates)
+	assert.ErrorIs(t, err, ErrUnsupportedCurrency)
+}
+
+func TestPriceList_Price(t *testing.T) {
+	product := models.Product{
+		Price:  models.NewMoney(2000, "USD"),
+		Prices: []models.Money{models.NewMoney(1900, "EUR")},
+	}
+
+	tests := []struct {
+		name     string
+		list     PriceList
+		product  models.Product
+		expected models.Money
+		ok       bool
+	}{
+		{"store currency", PriceList{Base: "USD", Currency: "USD", Rate: 1}, product, models.NewMoney(2000, "USD"), true},
+		{"explicit price wins over conversion", PriceList{Base: "USD", Currency: "EUR", Rate: 0.92}, product, models.NewMoney(1900, "EUR"), true},
+		{"converted at the rate", PriceList{Base: "USD", Currency: "JPY", Rate: 150}, product, models.NewMoney(3000, "JPY"), true},
+		{"base price in another currency", PriceList{Base: "GBP", Currency: "CHF", Rate: 1.1}, product, models.Money{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			price, ok := tt.list.Price(tt.product)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.expected, price)
+		})
+	}
+}
+
+func TestPriceList_Conversions(t *testing.T) {
+	list := PriceList{Base: "USD", Currency: "EUR", Rate: 0.5}
+
+	promotion := list.Promotion(models.Promotion{
+		Amount:       models.NewMoney(1000, "USD"),
+		MinCartValue: models.NewMoney(5000, "USD"),
+	})
+	assert.Equal(t, models.NewMoney(500, "EUR"), promotion.Amount)
+	assert.Equal(t, models.NewMoney(2500, "EUR"), promotion.MinCartValue)
+
+	method := list.ShippingMethod(models.ShippingMethod{
+		Rate:        models.NewMoney(800, "USD"),
+		WeightTiers: []models.WeightTier{{MaxWeight: 1, Rate: models.NewMoney(400, "USD")}},
+	})
+	assert.Equal(t, models.NewMoney(400, "EUR"), method.Rate)
+	assert.Equal(t, models.NewMoney(200, "EUR"), method.WeightTiers[0].Rate)
+
+	// Amounts already in another currency are left alone
+	assert.Equal(t, models.NewMoney(100, "GBP"), list.Convert(models.NewMoney(100, "GBP")))
+}
+
+func TestParseAcceptCurrency(t *testing.T) {
+	assert.Equal(t, []string{"EUR", "GBP", "USD"}, ParseAcceptCurrency("gbp;q=0.8, EUR, usd;q=0.1, jpy;q=0"))
+	assert.Empty(t, ParseAcceptCurrency(""))
+}
+
+func TestNegotiate(t *testing.T) {
+	list, err := Negotiate("USD", []string{"CHF", "EUR"}, rates)
+	require.NoError(t, err)
+	assert.Equal(t, "EUR", list.Currency)
+
+	list, err = Negotiate("USD", nil, rates)
+	require.NoError(t, err)
+	assert.Equal(t, "USD", list.Currency)
+
+	_, err = Negotiate("USD", []string{"CHF"}, rates)
+	assert.ErrorIs(t, err, ErrUnsupportedCurrency)
+}
@@ -0,0 +1,207 @@
+package promotions
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"ecommerce-backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Context carries the inputs besides the cart that decide which promotions apply
+type Context struct {
+	Now       time.Time
+	Codes     []string                   // Codes entered by the customer
+	UserUsage map[primitive.ObjectID]int // Redemptions of each promotion by the current user
+}
+
+// Result is the outcome of evaluating promotions against a cart
+type Result struct {
+	Subtotal      models.Money
+	Discount      models.Money
+	Total         models.Money
+	FreeShipping  bool
+	Applied       []models.AppliedPromotion
+	RejectedCodes []string
+}
+
+// candidate is an eligible promotion together with the discount it would grant
+type candidate struct {
+	promotion models.Promotion
+	discount  models.Money
+}
+
+// NormalizeCode returns the canonical form of a promotion code
+func NormalizeCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// Evaluate applies the given promotions to a cart and returns the discounted totals.
+// It performs no I/O: callers load the promotions and the user's usage beforehand.
+//
+// Eligible promotions are ordered by priority (then by discount). The first one is
+// always applied; later ones are only added while every applied promotion is stackable.
+func Evaluate(cart models.Cart, promotions []models.Promotion, ctx Context) Result {
+	subtotal := cart.Subtotal()
+	result := Result{
+		Subtotal: subtotal,
+		Total:    subtotal,
+		Applied:  []models.AppliedPromotion{},
+	}
+
+	entered := make(map[string]bool, len(ctx.Codes))
+	for _, code := range ctx.Codes {
+		if code = NormalizeCode(code); code != "" {
+			entered[code] = true
+		}
+	}
+
+	var candidates []candidate
+	for _, promotion := range promotions {
+		if !isEligible(promotion, cart, subtotal, entered, ctx) {
+			continue
+		}
+		discount := discountFor(promotion, cart)
+		if !discount.IsPositive() && promotion.Type != models.PromotionFreeShipping {
+			continue
+		}
+		candidates = append(candidates, candidate{promotion: promotion, discount: discount})
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].promotion.Priority != candidates[j].promotion.Priority {
+			return candidates[i].promotion.Priority > candidates[j].promotion.Priority
+		}
+		return candidates[i].discount.Amount > candidates[j].discount.Amount
+	})
+
+	remaining := subtotal
+	applied := make(map[string]bool)
+	exclusive := false
+	for _, c := range candidates {
+		if len(result.Applied) > 0 && (exclusive || !c.promotion.Stackable) {
+			continue
+		}
+		if !c.promotion.Stackable {
+			exclusive = true
+		}
+
+		discount := c.discount.Min(remaining)
+		remaining = remaining.Sub(discount)
+
+		isFreeShipping := c.promotion.Type == models.PromotionFreeShipping
+		if isFreeShipping {
+			result.FreeShipping = true
+		}
+		result.Applied = append(result.Applied, models.AppliedPromotion{
+			PromotionID:  c.promotion.ID,
+			Name:         c.promotion.Name,
+			Code:         c.promotion.Code,
+			Type:         c.promotion.Type,
+			Discount:     discount,
+			FreeShipping: isFreeShipping,
+		})
+		if c.promotion.Code != "" {
+			applied[NormalizeCode(c.promotion.Code)] = true
+		}
+	}
+
+	for _, code := range ctx.Codes {
+		if normalized := NormalizeCode(code); normalized != "" && !applied[normalized] {
+			result.RejectedCodes = append(result.RejectedCodes, code)
+		}
+	}
+
+	result.Discount = subtotal.Sub(remaining)
+	result.Total = remaining
+	return result
+}
+
+// isEligible checks activity, validity window, usage limits, codes and minimum cart value
+func isEligible(p models.Promotion, cart models.Cart, subtotal models.Money, entered map[string]bool, ctx Context) bool {
+	if !p.IsActive || !p.Type.IsValid() || !p.IsWithinWindow(ctx.Now) {
+		return false
+	}
+	if !p.IsAutomatic() && !entered[NormalizeCode(p.Code)] {
+		return false
+	}
+	if p.UsageLimit > 0 && p.UsageCount >= p.UsageLimit {
+		return false
+	}
+	if p.PerUserLimit > 0 && ctx.UserUsage[p.ID] >= p.PerUserLimit {
+		return false
+	}
+	if p.MinCartValue.IsPositive() && subtotal.Amount < p.MinCartValue.Amount {
+		return false
+	}
+	return scopedSubtotal(p, cart).IsPositive()
+}
+
+// discountFor computes the discount a promotion grants on its scoped lines
+func discountFor(p models.Promotion, cart models.Cart) models.Money {
+	scoped := scopedSubtotal(p, cart)
+
+	switch p.Type {
+	case models.PromotionPercentage:
+		return scoped.MulRate(math.Min(p.Value, 100) / 100)
+	case models.PromotionFixedAmount:
+		if p.Amount.Currency != "" && p.Amount.Currency != scoped.Currency {
+			return models.NewMoney(0, scoped.Currency)
+		}
+		return p.Amount.Min(scoped)
+	case models.PromotionBuyXGetY:
+		return buyXGetYDiscount(p, cart)
+	}
+	return models.NewMoney(0, scoped.Currency)
+}
+
+// buyXGetYDiscount discounts the cheapest GetQuantity units of every BuyQuantity+GetQuantity
+// scoped units by Value percent (or makes them free when Value is zero)
+func buyXGetYDiscount(p models.Promotion, cart models.Cart) models.Money {
+	var discount models.Money
+	if p.BuyQuantity <= 0 || p.GetQuantity <= 0 {
+		return discount
+	}
+
+	var unitPrices []models.Money
+	for _, line := range cart.Lines {
+		if !p.AppliesTo(line) {
+			continue
+		}
+		for i := 0; i < line.Quantity; i++ {
+			unitPrices = append(unitPrices, line.UnitPrice)
+		}
+	}
+
+	freeUnits := len(unitPrices) / (p.BuyQuantity + p.GetQuantity) * p.GetQuantity
+	if freeUnits == 0 {
+		return discount
+	}
+
+	percent := p.Value
+	if percent <= 0 || percent > 100 {
+		percent = 100
+	}
+
+	sort.SliceStable(unitPrices, func(i, j int) bool {
+		return unitPrices[i].Amount < unitPrices[j].Amount
+	})
+	for _, price := range unitPrices[:freeUnits] {
+		discount = discount.Add(price.MulRate(percent / 100))
+	}
+	return discount
+}
+
+// scopedSubtotal sums the lines that fall within the promotion's scope
+func scopedSubtotal(p models.Promotion, cart models.Cart) models.Money {
+	var total models.Money
+	for _, line := range cart.Lines {
+		if p.AppliesTo(line) {
+			total = total.Add(line.Total())
+		}
+	}
+	return total
+}
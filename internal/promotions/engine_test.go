@@ -0,0 +1,175 @@
+package promotions
+
+import (
+	"testing"
+	"time"
+
+	"ecommerce-backend/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func usd(amount int64) models.Money {
+	return models.NewMoney(amount, "USD")
+}
+
+func testCart() models.Cart {
+	return models.Cart{
+		UserID: primitive.NewObjectID(),
+		Lines: []models.CartLine{
+			{ProductID: primitive.NewObjectID(), Name: "Phone", Category: models.CategoryElectronics, Quantity: 1, UnitPrice: usd(20000)},
+			{ProductID: primitive.NewObjectID(), Name: "Shirt", Category: models.CategoryClothing, Quantity: 3, UnitPrice: usd(2000)},
+		},
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	past := now.Add(-24 * time.Hour)
+	future := now.Add(24 * time.Hour)
+
+	tests := []struct {
+		name             string
+		promotions       []models.Promotion
+		codes            []string
+		userUsage        func(promotions []models.Promotion) map[primitive.ObjectID]int
+		expectedDiscount int64 // Minor units
+		expectedApplied  int
+		expectedRejected []string
+		freeShipping     bool
+	}{
+		{
+			name: "automatic percentage off whole cart",
+			promotions: []models.Promotion{
+				{ID: primitive.NewObjectID(), Type: models.PromotionPercentage, Value: 10, IsActive: true},
+			},
+			expectedDiscount: 2600,
+			expectedApplied:  1,
+		},
+		{
+			name: "code required but not entered",
+			promotions: []models.Promotion{
+				{ID: primitive.NewObjectID(), Code: "SAVE10", Type: models.PromotionPercentage, Value: 10, IsActive: true},
+			},
+			expectedDiscount: 0,
+			expectedApplied:  0,
+		},
+		{
+			name: "code matched case-insensitively",
+			promotions: []models.Promotion{
+				{ID: primitive.NewObjectID(), Code: "SAVE10", Type: models.PromotionFixedAmount, Amount: usd(1000), IsActive: true},
+			},
+			codes:            []string{"save10"},
+			expectedDiscount: 1000,
+			expectedApplied:  1,
+		},
+		{
+			name:             "unknown code is rejected",
+			codes:            []string{"NOPE"},
+			expectedDiscount: 0,
+			expectedRejected: []string{"NOPE"},
+		},
+		{
+			name: "category scoped percentage",
+			promotions: []models.Promotion{
+				{ID: primitive.NewObjectID(), Type: models.PromotionPercentage, Value: 50, Categories: []models.ProductCategory{models.CategoryClothing}, IsActive: true},
+			},
+			expectedDiscount: 3000,
+			expectedApplied:  1,
+		},
+		{
+			name: "minimum cart value not met",
+			promotions: []models.Promotion{
+				{ID: primitive.NewObjectID(), Type: models.PromotionFixedAmount, Amount: usd(500), MinCartValue: usd(50000), IsActive: true},
+			},
+			expectedDiscount: 0,
+		},
+		{
+			name: "outside validity window",
+			promotions: []models.Promotion{
+				{ID: primitive.NewObjectID(), Type: models.PromotionFixedAmount, Amount: usd(500), StartsAt: &future, IsActive: true},
+				{ID: primitive.NewObjectID(), Type: models.PromotionFixedAmount, Amount: usd(500), EndsAt: &past, IsActive: true},
+			},
+			expectedDiscount: 0,
+		},
+		{
+			name: "global usage limit reached",
+			promotions: []models.Promotion{
+				{ID: primitive.NewObjectID(), Type: models.PromotionFixedAmount, Amount: usd(500), UsageLimit: 3, UsageCount: 3, IsActive: true},
+			},
+			expectedDiscount: 0,
+		},
+		{
+			name: "per user limit reached",
+			promotions: []models.Promotion{
+				{ID: primitive.NewObjectID(), Type: models.PromotionFixedAmount, Amount: usd(500), PerUserLimit: 1, IsActive: true},
+			},
+			userUsage: func(promotions []models.Promotion) map[primitive.ObjectID]int {
+				return map[primitive.ObjectID]int{promotions[0].ID: 1}
+			},
+			expectedDiscount: 0,
+		},
+		{
+			name: "buy two get one free on clothing",
+			promotions: []models.Promotion{
+				{ID: primitive.NewObjectID(), Type: models.PromotionBuyXGetY, BuyQuantity: 2, GetQuantity: 1, Categories: []models.ProductCategory{models.CategoryClothing}, IsActive: true},
+			},
+			expectedDiscount: 2000,
+			expectedApplied:  1,
+		},
+		{
+			name: "stackable promotions combine",
+			promotions: []models.Promotion{
+				{ID: primitive.NewObjectID(), Type: models.PromotionFixedAmount, Amount: usd(1000), Stackable: true, IsActive: true},
+				{ID: primitive.NewObjectID(), Type: models.PromotionFreeShipping, Stackable: true, IsActive: true},
+			},
+			expectedDiscount: 1000,
+			expectedApplied:  2,
+			freeShipping:     true,
+		},
+		{
+			name: "non-stackable promotion with higher priority wins alone",
+			promotions: []models.Promotion{
+				{ID: primitive.NewObjectID(), Type: models.PromotionFixedAmount, Amount: usd(1000), Stackable: true, IsActive: true},
+				{ID: primitive.NewObjectID(), Type: models.PromotionFixedAmount, Amount: usd(1500), Priority: 5, IsActive: true},
+			},
+			expectedDiscount: 1500,
+			expectedApplied:  1,
+		},
+		{
+			name: "discount never exceeds subtotal",
+			promotions: []models.Promotion{
+				{ID: primitive.NewObjectID(), Type: models.PromotionFixedAmount, Amount: usd(25000), Stackable: true, IsActive: true},
+				{ID: primitive.NewObjectID(), Type: models.PromotionFixedAmount, Amount: usd(25000), Stackable: true, IsActive: true},
+			},
+			expectedDiscount: 26000,
+			expectedApplied:  2,
+		},
+		{
+			name: "inactive promotion ignored",
+			promotions: []models.Promotion{
+				{ID: primitive.NewObjectID(), Type: models.PromotionPercentage, Value: 10},
+			},
+			expectedDiscount: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := Context{Now: now, Codes: tt.codes}
+			if tt.userUsage != nil {
+				ctx.UserUsage = tt.userUsage(tt.promotions)
+			}
+
+			result := Evaluate(testCart(), tt.promotions, ctx)
+
+			assert.Equal(t, usd(26000), result.Subtotal)
+			assert.Equal(t, usd(tt.expectedDiscount), result.Discount)
+			assert.Equal(t, usd(26000-tt.expectedDiscount), result.Total)
+			assert.Len(t, result.Applied, tt.expectedApplied)
+			assert.Equal(t, tt.expectedRejected, result.RejectedCodes)
+			assert.Equal(t, tt.freeShipping, result.FreeShipping)
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// sweepInterval is how often full buckets are dropped from the memory store
+const sweepInterval = time.Minute
+
+// memoryBucket remembers the policy a bucket was last used with, to know when it is full
+type memoryBucket struct {
+	Bucket
+	policy Policy
+}
+
+// MemoryStore keeps token buckets in process memory
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]memoryBucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// NewMemoryStore creates an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets: make(map[string]memoryBucket),
+		now:     time.Now,
+	}
+}
+
+// Take takes a token from the key's bucket
+func (s *MemoryStore) Take(ctx context.Context, key string, policy Policy) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+
+	var current *Bucket
+	if existing, ok := s.buckets[key]; ok {
+		current = &existing.Bucket
+	}
+	next, result := policy.Take(current, now)
+	s.buckets[key] = memoryBucket{Bucket: next, policy: policy}
+	return result, nil
+}
+
+// sweep drops buckets that have refilled, since a missing bucket starts full anyway
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < sweepInterval {
+		return
+	}
+	s.lastSweep = now
+
+	for key, b := range s.buckets {
+		if !now.Before(b.policy.FullAt(b.Bucket)) {
+			delete(s.buckets, key)
+		}
+	}
+}
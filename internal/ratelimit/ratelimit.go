@@ -0,0 +1,99 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// Policy describes a token bucket: Limit requests per Period on average, with bursts of
+// up to Burst requests
+type Policy struct {
+	Name   string        // Keeps the buckets of different route groups apart
+	Limit  int           // Requests per period; 0 disables the policy
+	Period time.Duration // Time to refill an empty bucket of Limit tokens
+	Burst  int           // Bucket capacity; defaults to Limit
+}
+
+// PerMinute returns a policy allowing limit requests per minute, all of which may be
+// spent at once
+func PerMinute(name string, limit int) Policy {
+	return Policy{Name: name, Limit: limit, Period: time.Minute}
+}
+
+// Enabled reports whether the policy limits anything
+func (p Policy) Enabled() bool {
+	return p.Limit > 0 && p.Period > 0
+}
+
+// Capacity returns the number of tokens a full bucket holds
+func (p Policy) Capacity() int {
+	if p.Burst > 0 {
+		return p.Burst
+	}
+	return p.Limit
+}
+
+// rate returns the tokens added per second
+func (p Policy) rate() float64 {
+	return float64(p.Limit) / p.Period.Seconds()
+}
+
+// Bucket is the state of one key's token bucket
+type Bucket struct {
+	Tokens    float64
+	UpdatedAt time.Time
+}
+
+// Result describes the outcome of taking a token
+type Result struct {
+	Allowed    bool
+	Limit      int           // Bucket capacity
+	Remaining  int           // Whole tokens left after this request
+	Reset      time.Duration // Time until the bucket is full again
+	RetryAfter time.Duration // Time until a token is available when not allowed
+}
+
+// Take refills the bucket for the time elapsed since its last update and takes one token
+// if there is one. A nil bucket starts full.
+func (p Policy) Take(b *Bucket, now time.Time) (Bucket, Result) {
+	capacity := float64(p.Capacity())
+	rate := p.rate()
+
+	tokens := capacity
+	if b != nil {
+		elapsed := now.Sub(b.UpdatedAt).Seconds()
+		if elapsed < 0 {
+			elapsed = 0
+		}
+		tokens = math.Min(capacity, b.Tokens+elapsed*rate)
+	}
+
+	result := Result{Limit: p.Capacity()}
+	if tokens >= 1 {
+		tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = seconds((1 - tokens) / rate)
+	}
+	result.Remaining = int(math.Floor(tokens))
+	result.Reset = seconds((capacity - tokens) / rate)
+
+	return Bucket{Tokens: tokens, UpdatedAt: now}, result
+}
+
+// FullAt returns when the bucket will be full again, after which it can be forgotten
+func (p Policy) FullAt(b Bucket) time.Time {
+	missing := float64(p.Capacity()) - b.Tokens
+	return b.UpdatedAt.Add(seconds(missing / p.rate()))
+}
+
+// Store keeps token buckets. The in-memory store suits a single instance; deployments
+// running several instances need a store shared between them.
+type Store interface {
+	Take(ctx context.Context, key string, policy Policy) (Result, error)
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
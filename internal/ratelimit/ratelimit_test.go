@@ -0,0 +1,85 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPolicy_Take(t *testing.T) {
+	policy := PerMinute("test", 3)
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	// A new bucket starts full and allows a burst of the whole limit
+	var bucket *Bucket
+	for i := 0; i < 3; i++ {
+		next, result := policy.Take(bucket, now)
+		bucket = &next
+		assert.True(t, result.Allowed)
+		assert.Equal(t, 3, result.Limit)
+		assert.Equal(t, 2-i, result.Remaining)
+	}
+
+	next, result := policy.Take(bucket, now)
+	bucket = &next
+	assert.False(t, result.Allowed)
+	assert.Equal(t, 0, result.Remaining)
+	assert.Equal(t, 20*time.Second, result.RetryAfter)
+	assert.Equal(t, time.Minute, result.Reset)
+
+	// One token is back after a third of the period
+	next, result = policy.Take(bucket, now.Add(20*time.Second))
+	bucket = &next
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 0, result.Remaining)
+
+	// The bucket never holds more than its capacity
+	_, result = policy.Take(bucket, now.Add(time.Hour))
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 2, result.Remaining)
+}
+
+func TestPolicy_Burst(t *testing.T) {
+	policy := Policy{Name: "test", Limit: 60, Period: time.Minute, Burst: 5}
+
+	assert.Equal(t, 5, policy.Capacity())
+	_, result := policy.Take(nil, time.Now())
+	assert.Equal(t, 5, result.Limit)
+	assert.Equal(t, 4, result.Remaining)
+	assert.Equal(t, time.Second, result.Reset)
+}
+
+func TestPolicy_Enabled(t *testing.T) {
+	assert.True(t, PerMinute("test", 10).Enabled())
+	assert.False(t, PerMinute("test", 0).Enabled())
+	assert.False(t, Policy{Name: "test", Limit: 10}.Enabled())
+}
+
+func TestMemoryStore(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	store := NewMemoryStore()
+	store.now = func() time.Time { return now }
+	policy := PerMinute("test", 1)
+
+	result, err := store.Take(context.Background(), "a", policy)
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+
+	result, err = store.Take(context.Background(), "a", policy)
+	require.NoError(t, err)
+	assert.False(t, result.Allowed)
+
+	// Keys have their own buckets
+	result, err = store.Take(context.Background(), "b", policy)
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+
+	// Refilled buckets are dropped
+	now = now.Add(2 * time.Minute)
+	_, err = store.Take(context.Background(), "c", policy)
+	require.NoError(t, err)
+	assert.Len(t, store.buckets, 1)
+}
@@ -7,18 +7,32 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
+	"ecommerce-backend/internal/apikeys"
+	"ecommerce-backend/internal/audit"
 	"ecommerce-backend/internal/config"
 	"ecommerce-backend/internal/database"
+	apperrors "ecommerce-backend/internal/errors"
 	"ecommerce-backend/internal/handlers"
 	"ecommerce-backend/internal/logger"
+	"ecommerce-backend/internal/mail"
 	"ecommerce-backend/internal/middleware"
+	"ecommerce-backend/internal/migrations"
+	"ecommerce-backend/internal/models"
+	"ecommerce-backend/internal/oidc"
+	"ecommerce-backend/internal/payments"
+	"ecommerce-backend/internal/ratelimit"
+	"ecommerce-backend/internal/sessions"
 	"ecommerce-backend/internal/utils"
+	"ecommerce-backend/internal/validation"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 	"github.com/joho/godotenv"
+	"go.mongodb.org/mongo-driver/bson"
 )
 
 // Server represents the HTTP server
@@ -27,6 +41,7 @@ type Server struct {
 	db     *database.Client
 	logger *slog.Logger
 	router *gin.Engine
+	orders *handlers.OrderHandler
 }
 
 // New creates a new server instance
@@ -36,8 +51,11 @@ func New() (*Server, error) {
 		slog.Info("No .env file found, using system environment variables")
 	}
 
-	cfg := config.Load()
-	log := logger.New()
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+	log := logger.New(cfg.Environment)
 
 	// Initialize database
 	db, err := database.NewClient(&cfg.Database)
@@ -45,61 +63,154 @@ func New() (*Server, error) {
 		return nil, fmt.Errorf("failed to initialize database: %w", err)
 	}
 
+	// Apply pending data migrations
+	migrateCtx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+	if err := migrations.Run(migrateCtx, db, log,
+		migrations.ProductPricesToMoney(cfg.Payment.Currency),
+		migrations.ExistingUsersEmailVerified(),
+		migrations.SeedRoles(),
+		migrations.PromotionUsageCounts(),
+		migrations.ProductStock(cfg.Catalog.InitialStock),
+		migrations.OrderReturnedQuantities(),
+	); err != nil {
+		return nil, fmt.Errorf("failed to apply migrations: %w", err)
+	}
+
 	// Initialize JWT manager
 	jwtManager := utils.NewJWTManager(&cfg.JWT)
 
+	// Initialize payment providers
+	paymentRegistry, err := payments.NewRegistry(cfg.Payment.Provider,
+		payments.NewFakeProvider(cfg.Payment.FakeWebhookSecret),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize payments: %w", err)
+	}
+
+	// Initialize sessions and outgoing mail
+	sessionStore := sessions.NewStore(db)
+	apiKeyStore := apikeys.NewStore(db)
+
+	// Rate limits are kept in memory, so each instance enforces its own
+	rateLimits := ratelimit.NewMemoryStore()
+	mailer, err := mail.NewSender(cfg.Mail.Driver, cfg.Mail.From, cfg.Mail.Dir, log)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize mail: %w", err)
+	}
+
+	// Initialize the audit log of admin changes
+	auditLogger := audit.NewLogger(db, log)
+
 	// Initialize handlers
-	authHandler := handlers.NewAuthHandler(db, jwtManager)
-	productHandler := handlers.NewProductHandler(db)
+	authHandler := handlers.NewAuthHandler(db, jwtManager, sessionStore, mailer, cfg.Mail, cfg.Auth, oidc.NewRegistry(cfg.OIDC.Providers, nil))
+	productHandler := handlers.NewProductHandler(db, cfg.Payment.Currency)
 	sliderHandler := handlers.NewSliderHandler(db)
+	promotionHandler := handlers.NewPromotionHandler(db, cfg.Payment.Currency)
+	cartHandler := handlers.NewCartHandler(db, cfg.Payment.Currency, cfg.Tax)
+	orderHandler := handlers.NewOrderHandler(db, paymentRegistry, cfg.Payment.Currency, cfg.Tax, cfg.Auth.RequireVerifiedEmail)
+	paymentHandler := handlers.NewPaymentHandler(db, paymentRegistry)
+	returnHandler := handlers.NewReturnHandler(db, paymentRegistry)
+	shippingHandler := handlers.NewShippingHandler(db, cfg.Payment.Currency)
+	taxHandler := handlers.NewTaxHandler(db)
+	currencyHandler := handlers.NewCurrencyHandler(db, cfg.Payment.Currency)
+	addressHandler := handlers.NewAddressHandler(db)
+	profileHandler := handlers.NewProfileHandler(db, sessionStore, mailer, cfg.Mail, cfg.Auth)
+	roleHandler := handlers.NewRoleHandler(db, sessionStore)
+	userHandler := handlers.NewUserHandler(db, sessionStore, mailer, cfg.Mail)
+	auditHandler := handlers.NewAuditHandler(auditLogger)
+	apiKeyHandler := handlers.NewAPIKeyHandler(apiKeyStore)
+	uploadHandler := handlers.NewUploadHandler("./uploads")
 
 	// Setup router
-	router := setupRouter(cfg, log, authHandler, productHandler, sliderHandler, jwtManager)
+	router := setupRouter(cfg, log, authHandler, productHandler, sliderHandler, promotionHandler, cartHandler, orderHandler, paymentHandler, returnHandler, shippingHandler, taxHandler, currencyHandler, addressHandler, profileHandler, roleHandler, userHandler, auditHandler, auditLogger, apiKeyHandler, uploadHandler, apiKeyStore, rateLimits, jwtManager, sessionStore)
 
 	return &Server{
 		config: cfg,
 		db:     db,
 		logger: log,
 		router: router,
+		orders: orderHandler,
 	}, nil
 }
 
 // setupRouter configures the HTTP router
-func setupRouter(cfg *config.Config, log *slog.Logger, authHandler *handlers.AuthHandler, productHandler *handlers.ProductHandler, sliderHandler *handlers.SliderHandler, jwtManager *utils.JWTManager) *gin.Engine {
+func setupRouter(cfg *config.Config, log *slog.Logger, authHandler *handlers.AuthHandler, productHandler *handlers.ProductHandler, sliderHandler *handlers.SliderHandler, promotionHandler *handlers.PromotionHandler, cartHandler *handlers.CartHandler, orderHandler *handlers.OrderHandler, paymentHandler *handlers.PaymentHandler, returnHandler *handlers.ReturnHandler, shippingHandler *handlers.ShippingHandler, taxHandler *handlers.TaxHandler, currencyHandler *handlers.CurrencyHandler, addressHandler *handlers.AddressHandler, profileHandler *handlers.ProfileHandler, roleHandler *handlers.RoleHandler, userHandler *handlers.UserHandler, auditHandler *handlers.AuditHandler, auditLogger *audit.Logger, apiKeyHandler *handlers.APIKeyHandler, uploadHandler *handlers.UploadHandler, apiKeyStore *apikeys.Store, rateLimits ratelimit.Store, jwtManager *utils.JWTManager, sessionStore *sessions.Store) *gin.Engine {
 	// Set Gin mode
-	if cfg.Server.Port == "8080" {
+	switch cfg.Environment {
+	case config.EnvDevelopment:
 		gin.SetMode(gin.DebugMode)
-	} else {
+	case config.EnvTest:
+		gin.SetMode(gin.TestMode)
+	default:
 		gin.SetMode(gin.ReleaseMode)
 	}
 
+	// Request binding validates the validate struct tags
+	binding.Validator = validation.NewStructValidator()
+
 	router := gin.New()
+	if err := router.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		log.Error("Invalid trusted proxies, trusting none", "error", err)
+		router.SetTrustedProxies(nil)
+	}
+
+	// Rate limits per route group
+	authLimit := middleware.RateLimit(rateLimits, ratelimit.PerMinute("auth", cfg.RateLimit.AuthPerMinute), middleware.ByIP)
+	publicLimit := middleware.RateLimit(rateLimits, ratelimit.PerMinute("public", cfg.RateLimit.PublicPerMinute), middleware.ByIP)
+	apiLimit := middleware.RateLimit(rateLimits, ratelimit.PerMinute("api", cfg.RateLimit.APIPerMinute), middleware.ByUser)
+
+	// Slider settings are a single document; exchange rates are keyed by currency
+	singleSettingsDocument := func(string) bson.M { return bson.M{} }
+	exchangeRateKey := func(currency string) bson.M {
+		return bson.M{"base": cfg.Payment.Currency, "currency": strings.ToUpper(currency)}
+	}
 
 	// Middleware
 	router.Use(gin.Recovery())
+	router.Use(middleware.RequestID())
 	router.Use(middleware.LoggingMiddleware(log))
-	router.Use(middleware.CORS())
+	router.Use(middleware.SecurityHeaders(cfg.Security))
+	// Errors are rendered for everything after this, including rejected CORS preflights
+	router.Use(middleware.ErrorHandler(cfg.Environment, log))
+	router.Use(middleware.CORS(cfg.CORS))
+	router.NoRoute(func(c *gin.Context) {
+		c.Error(apperrors.NotFound("Route not found"))
+	})
 
 	// Health check
 	router.GET("/health", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{"status": "ok", "timestamp": time.Now()})
 	})
 
-	// Serve static files for uploaded images
-	router.Static("/uploads", "./uploads")
+	// Serve uploaded images, without directory listings
+	router.GET("/uploads/*filepath", uploadHandler.ServeUpload)
+	router.HEAD("/uploads/*filepath", uploadHandler.ServeUpload)
 
 	// API routes
 	api := router.Group("/api")
 	{
 		// Auth routes
 		auth := api.Group("/auth")
+		auth.Use(authLimit)
 		{
 			auth.POST("/register", authHandler.Register)
 			auth.POST("/login", authHandler.Login)
+			auth.POST("/login/mfa", authHandler.VerifyMFA)                 // POST /api/auth/login/mfa (second step when two-factor is enabled)
+			auth.POST("/password/forgot", authHandler.ForgotPassword)      // POST /api/auth/password/forgot
+			auth.POST("/password/reset", authHandler.ResetPassword)        // POST /api/auth/password/reset
+			auth.GET("/verify", authHandler.VerifyEmail)                   // GET /api/auth/verify?token=
+			auth.POST("/email/confirm", profileHandler.ConfirmEmailChange) // POST /api/auth/email/confirm (token from the email change link)
+
+			// OpenID Connect sign in
+			auth.GET("/oidc/providers", authHandler.GetOIDCProviders)       // GET /api/auth/oidc/providers
+			auth.GET("/oidc/:provider/login", authHandler.OIDCLogin)        // GET /api/auth/oidc/:provider/login (authorization URL to redirect to)
+			auth.POST("/oidc/:provider/callback", authHandler.OIDCCallback) // POST /api/auth/oidc/:provider/callback (code and state from the redirect)
 		}
 
 		// Public product routes
 		products := api.Group("/products")
+		products.Use(publicLimit)
 		{
 			products.GET("/categories", productHandler.GetCategories) // GET /api/products/categories (must be before /:id)
 			products.GET("", productHandler.GetProducts)              // GET /api/products
@@ -107,22 +218,108 @@ func setupRouter(cfg *config.Config, log *slog.Logger, authHandler *handlers.Aut
 		}
 
 		// Public slider routes
-		api.GET("/sliders", sliderHandler.GetSliders) // GET /api/sliders (returns active slides with settings)
+		api.GET("/sliders", publicLimit, sliderHandler.GetSliders) // GET /api/sliders (returns active slides with settings)
+
+		// Public shipping quotes
+		api.POST("/shipping/quote", publicLimit, middleware.OptionalAuthMiddleware(jwtManager, sessionStore), shippingHandler.Quote) // POST /api/shipping/quote (saved addresses when signed in)
+
+		// Payment provider webhooks (authenticated by signature)
+		api.POST("/payments/webhook/:provider", paymentHandler.Webhook) // POST /api/payments/webhook/:provider
 
 		// Protected routes
 		protected := api.Group("")
-		protected.Use(middleware.AuthMiddleware(jwtManager))
+		protected.Use(middleware.AuthMiddleware(jwtManager, sessionStore), apiLimit)
 		{
+			protected.POST("/auth/verify/resend", authHandler.ResendVerification) // POST /api/auth/verify/resend (throttled)
 			protected.GET("/profile", authHandler.GetProfile)
+			protected.PATCH("/profile", profileHandler.UpdateProfile)           // PATCH /api/profile
+			protected.POST("/profile/password", profileHandler.ChangePassword)  // POST /api/profile/password (signs out other sessions)
+			protected.POST("/profile/email", profileHandler.RequestEmailChange) // POST /api/profile/email (sends a confirmation link)
+			protected.POST("/cart/price", cartHandler.PriceCart)                // POST /api/cart/price (totals with promotions applied)
 
-			// Admin routes
-			admin := protected.Group("/admin")
-			admin.Use(middleware.AdminMiddleware())
+			// Two-factor authentication
+			mfa := protected.Group("/profile/mfa")
 			{
-				admin.GET("/dashboard", authHandler.AdminDashboard)
+				mfa.POST("/setup", profileHandler.SetupMFA)                         // POST /api/profile/mfa/setup (secret and QR provisioning URI)
+				mfa.POST("/enable", profileHandler.EnableMFA)                       // POST /api/profile/mfa/enable (returns recovery codes)
+				mfa.POST("/disable", profileHandler.DisableMFA)                     // POST /api/profile/mfa/disable
+				mfa.POST("/recovery-codes", profileHandler.RegenerateRecoveryCodes) // POST /api/profile/mfa/recovery-codes
+			}
+
+			// Address book
+			addresses := protected.Group("/profile/addresses")
+			{
+				addresses.GET("", addressHandler.GetAddresses)         // GET /api/profile/addresses
+				addresses.POST("", addressHandler.CreateAddress)       // POST /api/profile/addresses
+				addresses.PUT("/:id", addressHandler.UpdateAddress)    // PUT /api/profile/addresses/:id
+				addresses.DELETE("/:id", addressHandler.DeleteAddress) // DELETE /api/profile/addresses/:id
+			}
+
+			// Order routes
+			orders := protected.Group("/orders")
+			{
+				orders.POST("", orderHandler.Checkout)                    // POST /api/orders (checkout)
+				orders.GET("", orderHandler.GetOrders)                    // GET /api/orders
+				orders.GET("/:id", orderHandler.GetOrder)                 // GET /api/orders/:id
+				orders.POST("/:id/returns", returnHandler.CreateReturn)   // POST /api/orders/:id/returns
+				orders.GET("/:id/returns", returnHandler.GetOrderReturns) // GET /api/orders/:id/returns
+			}
+
+			// Admin routes, which integrations can also call with an API key
+			admin := api.Group("/admin")
+			admin.Use(middleware.APIKeyMiddleware(apiKeyStore), middleware.AuthMiddleware(jwtManager, sessionStore), apiLimit)
+			admin.Use(middleware.AdminMiddleware(cfg.Auth.RequireAdminMFA))
+			{
+				admin.GET("/dashboard", middleware.RequirePermission(models.PermDashboardRead), authHandler.AdminDashboard)
+
+				// Audit log of admin changes
+				adminAudit := admin.Group("/audit")
+				adminAudit.Use(middleware.RequirePermission(models.PermAuditRead))
+				{
+					adminAudit.GET("", auditHandler.GetAuditLog) // GET /api/admin/audit?actor=&resource=&resource_id=&from=&to=&page=&limit=
+				}
+
+				// Admin user management
+				adminUsers := admin.Group("/users")
+				adminUsers.Use(middleware.RequirePermission(models.PermUsersManage))
+				adminUsers.Use(auditLogger.Track("user", "users", "id", audit.ByObjectID))
+				{
+					adminUsers.GET("", userHandler.GetUsers)                               // GET /api/admin/users?q=&role=&active=&page=&limit=
+					adminUsers.GET("/:id", userHandler.GetUser)                            // GET /api/admin/users/:id
+					adminUsers.PUT("/:id/role", userHandler.UpdateUserRole)                // PUT /api/admin/users/:id/role (signs the user out)
+					adminUsers.POST("/:id/activate", userHandler.ActivateUser)             // POST /api/admin/users/:id/activate
+					adminUsers.POST("/:id/deactivate", userHandler.DeactivateUser)         // POST /api/admin/users/:id/deactivate (revokes all sessions)
+					adminUsers.POST("/:id/password-reset", userHandler.ForcePasswordReset) // POST /api/admin/users/:id/password-reset (invalidates the password)
+					adminUsers.POST("/:id/unlock", authHandler.UnlockUser)                 // POST /api/admin/users/:id/unlock (clear failed logins)
+					adminUsers.GET("/:id/orders", userHandler.GetUserOrders)               // GET /api/admin/users/:id/orders
+					adminUsers.GET("/:id/sessions", userHandler.GetUserSessions)           // GET /api/admin/users/:id/sessions
+				}
+
+				// Admin API keys for integrations
+				adminAPIKeys := admin.Group("/api-keys")
+				adminAPIKeys.Use(middleware.RequirePermission(models.PermAPIKeysManage))
+				adminAPIKeys.Use(auditLogger.Track("api_key", "api_keys", "id", audit.ByObjectID))
+				{
+					adminAPIKeys.GET("", apiKeyHandler.GetAPIKeys)          // GET /api/admin/api-keys
+					adminAPIKeys.POST("", apiKeyHandler.CreateAPIKey)       // POST /api/admin/api-keys (the key is only shown in this response)
+					adminAPIKeys.DELETE("/:id", apiKeyHandler.RevokeAPIKey) // DELETE /api/admin/api-keys/:id
+				}
+
+				// Admin roles and permissions
+				adminRoles := admin.Group("/roles")
+				adminRoles.Use(middleware.RequirePermission(models.PermRolesManage))
+				adminRoles.Use(auditLogger.Track("role", "roles", "name", audit.ByField("name")))
+				{
+					adminRoles.GET("", roleHandler.GetRoles)            // GET /api/admin/roles (with every available permission)
+					adminRoles.POST("", roleHandler.CreateRole)         // POST /api/admin/roles
+					adminRoles.PUT("/:name", roleHandler.UpdateRole)    // PUT /api/admin/roles/:name
+					adminRoles.DELETE("/:name", roleHandler.DeleteRole) // DELETE /api/admin/roles/:name
+				}
 
 				// Admin product management
 				adminProducts := admin.Group("/products")
+				adminProducts.Use(middleware.RequirePermission(models.PermProductsWrite))
+				adminProducts.Use(auditLogger.Track("product", "products", "id", audit.ByObjectID))
 				{
 					adminProducts.POST("", productHandler.CreateProduct)                // POST /api/admin/products
 					adminProducts.PUT("/:id", productHandler.UpdateProduct)             // PUT /api/admin/products/:id
@@ -132,18 +329,91 @@ func setupRouter(cfg *config.Config, log *slog.Logger, authHandler *handlers.Aut
 
 				// Admin slider management
 				adminSliders := admin.Group("/sliders")
+				adminSliders.Use(middleware.RequirePermission(models.PermSlidersWrite))
+				adminSliders.Use(auditLogger.Track("slider", "slider", "id", audit.ByObjectID))
 				{
-					adminSliders.GET("", sliderHandler.GetAllSliders)           // GET /api/admin/sliders (list all images)
+					adminSliders.GET("", sliderHandler.GetAllSliders)            // GET /api/admin/sliders (list all images)
 					adminSliders.POST("/image", sliderHandler.UploadSliderImage) // POST /api/admin/sliders/image (upload image)
-					adminSliders.DELETE("/:id", sliderHandler.DeleteSlider)     // DELETE /api/admin/sliders/:id (delete image)
+					adminSliders.DELETE("/:id", sliderHandler.DeleteSlider)      // DELETE /api/admin/sliders/:id (delete image)
 				}
 
 				// Admin slider settings
 				adminSettings := admin.Group("/slider-settings")
+				adminSettings.Use(middleware.RequirePermission(models.PermSlidersWrite))
+				adminSettings.Use(auditLogger.Track("slider_settings", "slider_settings", "", singleSettingsDocument))
 				{
 					adminSettings.GET("", sliderHandler.GetSliderSettings)    // GET /api/admin/slider-settings
 					adminSettings.PUT("", sliderHandler.UpdateSliderSettings) // PUT /api/admin/slider-settings
 				}
+
+				// Admin promotion management
+				adminPromotions := admin.Group("/promotions")
+				adminPromotions.Use(middleware.RequirePermission(models.PermPromotionsWrite))
+				adminPromotions.Use(auditLogger.Track("promotion", "promotions", "id", audit.ByObjectID))
+				{
+					adminPromotions.GET("", promotionHandler.GetPromotions)          // GET /api/admin/promotions
+					adminPromotions.POST("", promotionHandler.CreatePromotion)       // POST /api/admin/promotions
+					adminPromotions.GET("/:id", promotionHandler.GetPromotion)       // GET /api/admin/promotions/:id
+					adminPromotions.PUT("/:id", promotionHandler.UpdatePromotion)    // PUT /api/admin/promotions/:id
+					adminPromotions.DELETE("/:id", promotionHandler.DeletePromotion) // DELETE /api/admin/promotions/:id
+				}
+
+				// Admin returns (RMA) workflow
+				adminReturns := admin.Group("/returns")
+				adminReturns.Use(middleware.RequirePermission(models.PermReturnsManage))
+				adminReturns.Use(auditLogger.Track("return", "returns", "id", audit.ByObjectID))
+				{
+					adminReturns.GET("", returnHandler.GetReturns)                 // GET /api/admin/returns
+					adminReturns.GET("/:id", returnHandler.GetReturn)              // GET /api/admin/returns/:id
+					adminReturns.POST("/:id/approve", returnHandler.ApproveReturn) // POST /api/admin/returns/:id/approve
+					adminReturns.POST("/:id/reject", returnHandler.RejectReturn)   // POST /api/admin/returns/:id/reject
+					adminReturns.POST("/:id/receive", returnHandler.ReceiveReturn) // POST /api/admin/returns/:id/receive
+				}
+
+				// Refunds need their own permission on top of managing returns
+				adminRefunds := admin.Group("/returns")
+				adminRefunds.Use(middleware.RequirePermission(models.PermReturnsManage, models.PermOrdersRefund))
+				adminRefunds.Use(auditLogger.Track("return", "returns", "id", audit.ByObjectID))
+				{
+					adminRefunds.POST("/:id/refund", returnHandler.RefundReturn) // POST /api/admin/returns/:id/refund
+				}
+
+				// Admin shipping zones and methods
+				adminShipping := admin.Group("/shipping")
+				adminShipping.Use(middleware.RequirePermission(models.PermShippingWrite))
+				trackZones := auditLogger.Track("shipping_zone", "shipping_zones", "id", audit.ByObjectID)
+				trackMethods := auditLogger.Track("shipping_method", "shipping_methods", "id", audit.ByObjectID)
+				{
+					adminShipping.GET("/zones", shippingHandler.GetZones)                            // GET /api/admin/shipping/zones
+					adminShipping.POST("/zones", trackZones, shippingHandler.CreateZone)             // POST /api/admin/shipping/zones
+					adminShipping.PUT("/zones/:id", trackZones, shippingHandler.UpdateZone)          // PUT /api/admin/shipping/zones/:id
+					adminShipping.DELETE("/zones/:id", trackZones, shippingHandler.DeleteZone)       // DELETE /api/admin/shipping/zones/:id
+					adminShipping.GET("/methods", shippingHandler.GetMethods)                        // GET /api/admin/shipping/methods
+					adminShipping.POST("/methods", trackMethods, shippingHandler.CreateMethod)       // POST /api/admin/shipping/methods
+					adminShipping.PUT("/methods/:id", trackMethods, shippingHandler.UpdateMethod)    // PUT /api/admin/shipping/methods/:id
+					adminShipping.DELETE("/methods/:id", trackMethods, shippingHandler.DeleteMethod) // DELETE /api/admin/shipping/methods/:id
+				}
+
+				// Admin tax rates
+				adminTax := admin.Group("/tax/rates")
+				adminTax.Use(middleware.RequirePermission(models.PermTaxWrite))
+				adminTax.Use(auditLogger.Track("tax_rate", "tax_rates", "id", audit.ByObjectID))
+				{
+					adminTax.GET("", taxHandler.GetTaxRates)          // GET /api/admin/tax/rates
+					adminTax.POST("", taxHandler.CreateTaxRate)       // POST /api/admin/tax/rates
+					adminTax.PUT("/:id", taxHandler.UpdateTaxRate)    // PUT /api/admin/tax/rates/:id
+					adminTax.DELETE("/:id", taxHandler.DeleteTaxRate) // DELETE /api/admin/tax/rates/:id
+				}
+
+				// Admin exchange rates from the store currency
+				adminRates := admin.Group("/exchange-rates")
+				adminRates.Use(middleware.RequirePermission(models.PermCurrencyWrite))
+				adminRates.Use(auditLogger.Track("exchange_rate", "exchange_rates", "currency", exchangeRateKey))
+				{
+					adminRates.GET("", currencyHandler.GetExchangeRates)                // GET /api/admin/exchange-rates
+					adminRates.PUT("/:currency", currencyHandler.SetExchangeRate)       // PUT /api/admin/exchange-rates/:currency
+					adminRates.DELETE("/:currency", currencyHandler.DeleteExchangeRate) // DELETE /api/admin/exchange-rates/:currency
+				}
 			}
 		}
 	}
@@ -163,16 +433,22 @@ func (s *Server) Start() error {
 
 	// Start server in a goroutine
 	go func() {
-		s.logger.Info("Starting server", "port", s.config.Server.Port)
+		s.logger.Info("Starting server", "port", s.config.Server.Port, "environment", s.config.Environment)
 		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 			s.logger.Error("Failed to start server", "error", err)
 		}
 	}()
 
+	// Cancel unpaid orders in the background so that their stock goes back on sale
+	expiryCtx, stopExpiry := context.WithCancel(context.Background())
+	defer stopExpiry()
+	go s.expireOrders(expiryCtx)
+
 	// Wait for interrupt signal to gracefully shutdown the server
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
+	stopExpiry()
 
 	s.logger.Info("Shutting down server...")
 
@@ -194,3 +470,29 @@ func (s *Server) Start() error {
 	s.logger.Info("Server exited")
 	return nil
 }
+
+// expireOrders cancels orders left unpaid for longer than the payment timeout, once a
+// minute until ctx is done. Several instances may run it; each order is only cancelled once.
+func (s *Server) expireOrders(ctx context.Context) {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		runCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		expired, err := s.orders.ExpireOrders(runCtx, time.Now().Add(-s.config.Payment.OrderTimeout))
+		cancel()
+		if err != nil {
+			s.logger.Error("Failed to expire unpaid orders", "error", err)
+			continue
+		}
+		if expired > 0 {
+			s.logger.Info("Expired unpaid orders", "count", expired)
+		}
+	}
+}
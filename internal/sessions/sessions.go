@@ -0,0 +1,95 @@
+package sessions
+
+import (
+	"context"
+	"time"
+
+	"ecommerce-backend/internal/database"
+	"ecommerce-backend/internal/models"
+	"ecommerce-backend/internal/utils"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Store records issued tokens so they can be listed and revoked before they expire
+type Store struct {
+	db *database.Client
+}
+
+// NewStore creates a new session store
+func NewStore(db *database.Client) *Store {
+	return &Store{db: db}
+}
+
+// Create records the session of a newly issued token
+func (s *Store) Create(ctx context.Context, userID primitive.ObjectID, claims *utils.Claims, userAgent, ip string) error {
+	session := models.Session{
+		ID:        claims.ID,
+		UserID:    userID,
+		UserAgent: userAgent,
+		IP:        ip,
+		CreatedAt: claims.IssuedAt.Time,
+		ExpiresAt: claims.ExpiresAt.Time,
+	}
+	_, err := s.db.GetCollection("sessions").InsertOne(ctx, session)
+	return err
+}
+
+// IsActive reports whether a session exists, has not expired and has not been revoked
+func (s *Store) IsActive(ctx context.Context, sessionID string) (bool, error) {
+	filter := bson.M{
+		"_id":        sessionID,
+		"revoked_at": bson.M{"$exists": false},
+		"expires_at": bson.M{"$gt": time.Now()},
+	}
+	err := s.db.GetCollection("sessions").FindOne(ctx, filter).Err()
+	if err == mongo.ErrNoDocuments {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// List returns the user's active sessions, most recent first
+func (s *Store) List(ctx context.Context, userID primitive.ObjectID) ([]models.Session, error) {
+	filter := bson.M{
+		"user_id":    userID,
+		"revoked_at": bson.M{"$exists": false},
+		"expires_at": bson.M{"$gt": time.Now()},
+	}
+	findOptions := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+	cursor, err := s.db.GetCollection("sessions").Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	sessions := []models.Session{}
+	if err := cursor.All(ctx, &sessions); err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+// RevokeUsers revokes every active session of the given users
+func (s *Store) RevokeUsers(ctx context.Context, userIDs []primitive.ObjectID) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+	filter := bson.M{"user_id": bson.M{"$in": userIDs}, "revoked_at": bson.M{"$exists": false}}
+	_, err := s.db.GetCollection("sessions").UpdateMany(ctx, filter, bson.M{"$set": bson.M{"revoked_at": time.Now()}})
+	return err
+}
+
+// RevokeAll revokes every active session of the user except the given one, which may
+// be empty to revoke them all
+func (s *Store) RevokeAll(ctx context.Context, userID primitive.ObjectID, exceptID string) error {
+	filter := bson.M{"user_id": userID, "revoked_at": bson.M{"$exists": false}}
+	if exceptID != "" {
+		filter["_id"] = bson.M{"$ne": exceptID}
+	}
+	_, err := s.db.GetCollection("sessions").UpdateMany(ctx, filter, bson.M{"$set": bson.M{"revoked_at": time.Now()}})
+	return err
+}
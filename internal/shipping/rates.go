@@ -0,0 +1,167 @@
+package shipping
+
+import (
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"ecommerce-backend/internal/models"
+)
+
+// Parcel describes what is being shipped
+type Parcel struct {
+	Weight       float64      // Kilograms
+	Subtotal     models.Money // Cart value after discounts, used for free shipping thresholds
+	FreeShipping bool         // Set when a promotion grants free shipping
+}
+
+// MatchZone returns the highest priority active zone covering the address, or nil.
+// Between zones of equal priority the most specific one wins (postcode, then region,
+// then country, then catch-all).
+func MatchZone(zones []models.ShippingZone, address models.Address) *models.ShippingZone {
+	address = address.Normalized()
+
+	sorted := make([]models.ShippingZone, len(zones))
+	copy(sorted, zones)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Priority != sorted[j].Priority {
+			return sorted[i].Priority > sorted[j].Priority
+		}
+		return specificity(sorted[i]) > specificity(sorted[j])
+	})
+
+	for i := range sorted {
+		if sorted[i].IsActive && zoneCovers(sorted[i], address) {
+			return &sorted[i]
+		}
+	}
+	return nil
+}
+
+// Quote prices each active method of the zone for the parcel, cheapest first.
+// Methods that cannot carry the parcel (e.g. heavier than every weight tier) are left out.
+func Quote(zone models.ShippingZone, methods []models.ShippingMethod, parcel Parcel, now time.Time) []models.ShippingQuote {
+	quotes := []models.ShippingQuote{}
+	for _, method := range methods {
+		if !method.IsActive || method.ZoneID != zone.ID {
+			continue
+		}
+
+		price, ok := MethodPrice(method, parcel)
+		if !ok {
+			continue
+		}
+
+		quotes = append(quotes, models.ShippingQuote{
+			MethodID:     method.ID,
+			ZoneID:       zone.ID,
+			Name:         method.Name,
+			Price:        price,
+			MinDays:      method.MinDays,
+			MaxDays:      method.MaxDays,
+			EarliestDate: addBusinessDays(now, method.MinDays),
+			LatestDate:   addBusinessDays(now, method.MaxDays),
+		})
+	}
+
+	sort.SliceStable(quotes, func(i, j int) bool {
+		return quotes[i].Price.Amount < quotes[j].Price.Amount
+	})
+	return quotes
+}
+
+// MethodPrice returns the price of a method for the parcel and whether the method applies
+func MethodPrice(method models.ShippingMethod, parcel Parcel) (models.Money, bool) {
+	var price models.Money
+
+	switch method.Type {
+	case models.ShippingFlatRate:
+		price = method.Rate
+	case models.ShippingFreeOverThreshold:
+		price = method.Rate
+		if parcel.Subtotal.Amount >= method.FreeThreshold.Amount {
+			price = models.NewMoney(0, price.Currency)
+		}
+	case models.ShippingWeightBased:
+		tiers := make([]models.WeightTier, len(method.WeightTiers))
+		copy(tiers, method.WeightTiers)
+		sort.Slice(tiers, func(i, j int) bool {
+			return tiers[i].MaxWeight < tiers[j].MaxWeight
+		})
+
+		found := false
+		for _, tier := range tiers {
+			if parcel.Weight <= tier.MaxWeight {
+				price = tier.Rate
+				found = true
+				break
+			}
+		}
+		if !found {
+			return price, false
+		}
+	default:
+		return price, false
+	}
+
+	if parcel.FreeShipping {
+		price = models.NewMoney(0, price.Currency)
+	}
+	return price, true
+}
+
+// zoneCovers checks the country, region and postcode rules of a zone
+func zoneCovers(zone models.ShippingZone, address models.Address) bool {
+	if len(zone.Countries) > 0 && !containsFold(zone.Countries, address.Country) {
+		return false
+	}
+	if len(zone.Regions) > 0 && !containsFold(zone.Regions, address.Region) {
+		return false
+	}
+	if len(zone.PostcodePatterns) > 0 {
+		postcode := strings.ReplaceAll(address.Postcode, " ", "")
+		for _, pattern := range zone.PostcodePatterns {
+			pattern = strings.ToUpper(strings.ReplaceAll(pattern, " ", ""))
+			if matched, err := path.Match(pattern, postcode); err == nil && matched {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// specificity ranks how narrowly a zone is defined
+func specificity(zone models.ShippingZone) int {
+	switch {
+	case len(zone.PostcodePatterns) > 0:
+		return 3
+	case len(zone.Regions) > 0:
+		return 2
+	case len(zone.Countries) > 0:
+		return 1
+	}
+	return 0
+}
+
+// containsFold reports whether values contains target, ignoring case
+func containsFold(values []string, target string) bool {
+	for _, value := range values {
+		if strings.EqualFold(strings.TrimSpace(value), target) {
+			return true
+		}
+	}
+	return false
+}
+
+// addBusinessDays adds days to t, skipping Saturdays and Sundays
+func addBusinessDays(t time.Time, days int) time.Time {
+	for days > 0 {
+		t = t.AddDate(0, 0, 1)
+		if t.Weekday() != time.Saturday && t.Weekday() != time.Sunday {
+			days--
+		}
+	}
+	return t
+}
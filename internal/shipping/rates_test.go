@@ -0,0 +1,92 @@
+package shipping
+
+import (
+	"testing"
+	"time"
+
+	"ecommerce-backend/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func usd(amount int64) models.Money {
+	return models.NewMoney(amount, "USD")
+}
+
+func TestMatchZone(t *testing.T) {
+	london := models.ShippingZone{ID: primitive.NewObjectID(), Name: "London", Countries: []string{"GB"}, PostcodePatterns: []string{"SW*", "EC1?"}, Priority: 10, IsActive: true}
+	uk := models.ShippingZone{ID: primitive.NewObjectID(), Name: "UK", Countries: []string{"GB"}, Priority: 5, IsActive: true}
+	california := models.ShippingZone{ID: primitive.NewObjectID(), Name: "California", Countries: []string{"US"}, Regions: []string{"CA"}, IsActive: true}
+	world := models.ShippingZone{ID: primitive.NewObjectID(), Name: "World", IsActive: true}
+	closed := models.ShippingZone{ID: primitive.NewObjectID(), Name: "Closed", Countries: []string{"FR"}, Priority: 100}
+
+	zones := []models.ShippingZone{world, uk, london, california, closed}
+
+	tests := []struct {
+		name     string
+		address  models.Address
+		expected string
+	}{
+		{"postcode pattern wins by priority", models.Address{Country: "gb", Postcode: "sw1a 1aa"}, "London"},
+		{"single character wildcard", models.Address{Country: "GB", Postcode: "EC1A"}, "London"},
+		{"country fallback", models.Address{Country: "GB", Postcode: "M1 1AE"}, "UK"},
+		{"region match", models.Address{Country: "US", Region: "ca"}, "California"},
+		{"region mismatch falls through", models.Address{Country: "US", Region: "NY"}, "World"},
+		{"inactive zone ignored", models.Address{Country: "FR"}, "World"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			zone := MatchZone(zones, tt.address)
+			require.NotNil(t, zone)
+			assert.Equal(t, tt.expected, zone.Name)
+		})
+	}
+
+	assert.Nil(t, MatchZone([]models.ShippingZone{uk}, models.Address{Country: "DE"}))
+}
+
+func TestQuote(t *testing.T) {
+	zone := models.ShippingZone{ID: primitive.NewObjectID(), IsActive: true}
+	methods := []models.ShippingMethod{
+		{ID: primitive.NewObjectID(), ZoneID: zone.ID, Name: "Express", Type: models.ShippingFlatRate, Rate: usd(1500), MinDays: 1, MaxDays: 2, IsActive: true},
+		{ID: primitive.NewObjectID(), ZoneID: zone.ID, Name: "Standard", Type: models.ShippingFreeOverThreshold, Rate: usd(500), FreeThreshold: usd(5000), MinDays: 3, MaxDays: 5, IsActive: true},
+		{ID: primitive.NewObjectID(), ZoneID: zone.ID, Name: "Freight", Type: models.ShippingWeightBased, WeightTiers: []models.WeightTier{{MaxWeight: 30, Rate: usd(4000)}, {MaxWeight: 10, Rate: usd(2000)}}, IsActive: true},
+		{ID: primitive.NewObjectID(), ZoneID: zone.ID, Name: "Disabled", Type: models.ShippingFlatRate, Rate: usd(100)},
+		{ID: primitive.NewObjectID(), ZoneID: primitive.NewObjectID(), Name: "Other zone", Type: models.ShippingFlatRate, Rate: usd(100), IsActive: true},
+	}
+	friday := time.Date(2024, 5, 31, 10, 0, 0, 0, time.UTC)
+
+	t.Run("below threshold and light parcel", func(t *testing.T) {
+		quotes := Quote(zone, methods, Parcel{Weight: 2, Subtotal: usd(3000)}, friday)
+		require.Len(t, quotes, 3)
+		assert.Equal(t, "Standard", quotes[0].Name)
+		assert.Equal(t, usd(500), quotes[0].Price)
+		assert.Equal(t, "Express", quotes[1].Name)
+		assert.Equal(t, "Freight", quotes[2].Name)
+		assert.Equal(t, usd(2000), quotes[2].Price)
+		assert.Equal(t, time.Date(2024, 6, 3, 10, 0, 0, 0, time.UTC), quotes[1].EarliestDate)
+		assert.Equal(t, time.Date(2024, 6, 4, 10, 0, 0, 0, time.UTC), quotes[1].LatestDate)
+	})
+
+	t.Run("free over threshold", func(t *testing.T) {
+		quotes := Quote(zone, methods, Parcel{Weight: 2, Subtotal: usd(5000)}, friday)
+		require.NotEmpty(t, quotes)
+		assert.Equal(t, "Standard", quotes[0].Name)
+		assert.Equal(t, usd(0), quotes[0].Price)
+	})
+
+	t.Run("too heavy for weight tiers", func(t *testing.T) {
+		quotes := Quote(zone, methods, Parcel{Weight: 31, Subtotal: usd(3000)}, friday)
+		require.Len(t, quotes, 2)
+	})
+
+	t.Run("free shipping promotion", func(t *testing.T) {
+		quotes := Quote(zone, methods, Parcel{Weight: 2, Subtotal: usd(3000), FreeShipping: true}, friday)
+		for _, quote := range quotes {
+			assert.Equal(t, usd(0), quote.Price)
+		}
+	})
+}
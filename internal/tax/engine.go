@@ -0,0 +1,132 @@
+package tax
+
+import (
+	"strings"
+
+	"ecommerce-backend/internal/models"
+)
+
+// Input describes what is being taxed
+type Input struct {
+	Lines            []models.CartLine
+	Discount         models.Money // Order level discount, shared across lines by value
+	Shipping         models.Money // Taxed at the standard rate
+	Address          models.Address
+	PricesIncludeTax bool // Whether catalogue prices and shipping rates already include tax
+}
+
+// RateFor returns the percentage rate for a tax class at the address.
+// A rate for the address's region wins over a country-wide rate; zero-rated goods
+// and destinations without a configured rate are not taxed.
+func RateFor(rates []models.TaxRate, address models.Address, class models.TaxClass) float64 {
+	class = class.OrDefault()
+	if class == models.TaxClassZero {
+		return 0
+	}
+
+	address = address.Normalized()
+	rate, found := 0.0, false
+	for _, r := range rates {
+		if !r.IsActive || r.Class != class || !strings.EqualFold(r.Country, address.Country) {
+			continue
+		}
+		if r.Region != "" {
+			if strings.EqualFold(r.Region, address.Region) {
+				return r.Rate
+			}
+			continue
+		}
+		if !found {
+			rate, found = r.Rate, true
+		}
+	}
+	return rate
+}
+
+// Calculate returns the per-line tax breakdown for the input
+func Calculate(rates []models.TaxRate, in Input) models.TaxBreakdown {
+	address := in.Address.Normalized()
+	breakdown := models.TaxBreakdown{
+		PricesIncludeTax: in.PricesIncludeTax,
+		Country:          address.Country,
+		Region:           address.Region,
+		Lines:            []models.TaxLine{},
+	}
+
+	amounts := allocateDiscount(in.Lines, in.Discount)
+	for i, line := range in.Lines {
+		class := line.TaxClass.OrDefault()
+		breakdown.Lines = append(breakdown.Lines, taxLine(models.TaxLine{
+			ProductID:   line.ProductID,
+			Description: line.Name,
+			Quantity:    line.Quantity,
+			Class:       class,
+			Rate:        RateFor(rates, address, class),
+		}, amounts[i], in.PricesIncludeTax))
+	}
+
+	if in.Shipping.IsPositive() {
+		breakdown.Lines = append(breakdown.Lines, taxLine(models.TaxLine{
+			Description: "Shipping",
+			Quantity:    1,
+			Class:       models.TaxClassStandard,
+			Rate:        RateFor(rates, address, models.TaxClassStandard),
+		}, in.Shipping, in.PricesIncludeTax))
+	}
+
+	currency := in.Shipping.Currency
+	if len(in.Lines) > 0 {
+		currency = in.Lines[0].UnitPrice.Currency
+	}
+	breakdown.Net = models.NewMoney(0, currency)
+	breakdown.Tax = models.NewMoney(0, currency)
+	breakdown.Gross = models.NewMoney(0, currency)
+	for _, line := range breakdown.Lines {
+		breakdown.Net = breakdown.Net.Add(line.Net)
+		breakdown.Tax = breakdown.Tax.Add(line.Tax)
+		breakdown.Gross = breakdown.Gross.Add(line.Gross)
+	}
+
+	return breakdown
+}
+
+// taxLine fills in the net, tax and gross amounts of a line charged amount
+func taxLine(line models.TaxLine, amount models.Money, inclusive bool) models.TaxLine {
+	if inclusive {
+		line.Gross = amount
+		line.Net = amount.MulRate(1 / (1 + line.Rate/100))
+		line.Tax = line.Gross.Sub(line.Net)
+	} else {
+		line.Net = amount
+		line.Tax = amount.MulRate(line.Rate / 100)
+		line.Gross = line.Net.Add(line.Tax)
+	}
+	return line
+}
+
+// allocateDiscount shares the discount across lines in proportion to their totals.
+// The last line absorbs the rounding remainder so the amounts add up exactly.
+func allocateDiscount(lines []models.CartLine, discount models.Money) []models.Money {
+	amounts := make([]models.Money, len(lines))
+
+	var subtotal models.Money
+	for _, line := range lines {
+		subtotal = subtotal.Add(line.Total())
+	}
+	discount = discount.Min(subtotal)
+
+	remaining := discount
+	for i, line := range lines {
+		share := models.NewMoney(0, line.UnitPrice.Currency)
+		if subtotal.IsPositive() {
+			share = discount.MulRate(float64(line.Total().Amount) / float64(subtotal.Amount))
+		}
+		if i == len(lines)-1 {
+			share = remaining
+		}
+		share = share.Min(line.Total())
+		remaining = remaining.Sub(share)
+		amounts[i] = line.Total().Sub(share)
+	}
+	return amounts
+}
@@ -0,0 +1,104 @@
+package tax
+
+import (
+	"testing"
+
+	"ecommerce-backend/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var testRates = []models.TaxRate{
+	{Country: "GB", Class: models.TaxClassStandard, Rate: 20, IsActive: true},
+	{Country: "GB", Class: models.TaxClassReduced, Rate: 5, IsActive: true},
+	{Country: "US", Class: models.TaxClassStandard, Rate: 0, IsActive: true},
+	{Country: "US", Region: "CA", Class: models.TaxClassStandard, Rate: 7.25, IsActive: true},
+	{Country: "DE", Class: models.TaxClassStandard, Rate: 19},
+}
+
+func usd(amount int64) models.Money {
+	return models.NewMoney(amount, "USD")
+}
+
+func TestRateFor(t *testing.T) {
+	tests := []struct {
+		name     string
+		address  models.Address
+		class    models.TaxClass
+		expected float64
+	}{
+		{"country standard rate", models.Address{Country: "gb"}, models.TaxClassStandard, 20},
+		{"unset class is standard", models.Address{Country: "GB"}, "", 20},
+		{"reduced rate", models.Address{Country: "GB"}, models.TaxClassReduced, 5},
+		{"zero rated", models.Address{Country: "GB"}, models.TaxClassZero, 0},
+		{"region rate wins", models.Address{Country: "US", Region: "ca"}, models.TaxClassStandard, 7.25},
+		{"country rate outside region", models.Address{Country: "US", Region: "OR"}, models.TaxClassStandard, 0},
+		{"inactive rate ignored", models.Address{Country: "DE"}, models.TaxClassStandard, 0},
+		{"no rate configured", models.Address{Country: "FR"}, models.TaxClassStandard, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, RateFor(testRates, tt.address, tt.class))
+		})
+	}
+}
+
+func TestCalculate(t *testing.T) {
+	lines := []models.CartLine{
+		{ProductID: primitive.NewObjectID(), Name: "Lamp", Quantity: 2, UnitPrice: usd(3000), TaxClass: models.TaxClassStandard},
+		{ProductID: primitive.NewObjectID(), Name: "Book", Quantity: 1, UnitPrice: usd(4000), TaxClass: models.TaxClassZero},
+	}
+
+	t.Run("exclusive prices with discount and shipping", func(t *testing.T) {
+		breakdown := Calculate(testRates, Input{
+			Lines:    lines,
+			Discount: usd(1000),
+			Shipping: usd(500),
+			Address:  models.Address{Country: "GB"},
+		})
+
+		require.Len(t, breakdown.Lines, 3)
+		assert.Equal(t, usd(5400), breakdown.Lines[0].Net)
+		assert.Equal(t, usd(1080), breakdown.Lines[0].Tax)
+		assert.Equal(t, usd(3600), breakdown.Lines[1].Net)
+		assert.Equal(t, usd(0), breakdown.Lines[1].Tax)
+		assert.Equal(t, "Shipping", breakdown.Lines[2].Description)
+		assert.Equal(t, usd(100), breakdown.Lines[2].Tax)
+		assert.Equal(t, usd(9500), breakdown.Net)
+		assert.Equal(t, usd(1180), breakdown.Tax)
+		assert.Equal(t, usd(10680), breakdown.Gross)
+	})
+
+	t.Run("inclusive prices", func(t *testing.T) {
+		breakdown := Calculate(testRates, Input{
+			Lines:            lines[:1],
+			Address:          models.Address{Country: "GB"},
+			PricesIncludeTax: true,
+		})
+
+		require.Len(t, breakdown.Lines, 1)
+		assert.True(t, breakdown.PricesIncludeTax)
+		assert.Equal(t, usd(6000), breakdown.Lines[0].Gross)
+		assert.Equal(t, usd(5000), breakdown.Lines[0].Net)
+		assert.Equal(t, usd(1000), breakdown.Tax)
+		assert.Equal(t, usd(6000), breakdown.Gross)
+	})
+
+	t.Run("discount shares add up", func(t *testing.T) {
+		three := []models.CartLine{
+			{Quantity: 1, UnitPrice: usd(1000)},
+			{Quantity: 1, UnitPrice: usd(1000)},
+			{Quantity: 1, UnitPrice: usd(1000)},
+		}
+		amounts := allocateDiscount(three, usd(1000))
+
+		var total models.Money
+		for _, amount := range amounts {
+			total = total.Add(amount)
+		}
+		assert.Equal(t, usd(2000), total)
+	})
+}
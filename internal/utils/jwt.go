@@ -12,9 +12,11 @@ import (
 
 // Claims represents JWT claims
 type Claims struct {
-	UserID string `json:"user_id"`
-	Email  string `json:"email"`
-	Role   string `json:"role"`
+	UserID      string   `json:"user_id"`
+	Email       string   `json:"email"`
+	Role        string   `json:"role"`
+	Permissions []string `json:"permissions,omitempty"` // Granted by the role when the token was issued
+	MFA         bool     `json:"mfa,omitempty"`         // Whether the login was completed with a second factor
 	jwt.RegisteredClaims
 }
 
@@ -34,19 +36,40 @@ func NewJWTManager(cfg *config.JWTConfig) *JWTManager {
 
 // GenerateToken generates a new JWT token
 func (j *JWTManager) GenerateToken(userID, email, role string) (string, error) {
-	claims := Claims{
-		UserID: userID,
-		Email:  email,
-		Role:   role,
+	token, _, err := j.IssueToken(userID, email, role, nil, false)
+	return token, err
+}
+
+// IssueToken generates a new JWT token and returns its claims. Each token has a unique
+// ID (jti) that identifies the session it belongs to. permissions are those of the
+// user's role, and mfa records whether the user passed a second factor.
+func (j *JWTManager) IssueToken(userID, email, role string, permissions []string, mfa bool) (string, *Claims, error) {
+	tokenID, err := GenerateRandomToken()
+	if err != nil {
+		return "", nil, err
+	}
+
+	now := time.Now()
+	claims := &Claims{
+		UserID:      userID,
+		Email:       email,
+		Role:        role,
+		Permissions: permissions,
+		MFA:         mfa,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(j.expiration)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			NotBefore: jwt.NewNumericDate(time.Now()),
+			ID:        tokenID,
+			ExpiresAt: jwt.NewNumericDate(now.Add(j.expiration)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
 		},
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(j.secret))
+	signed, err := token.SignedString([]byte(j.secret))
+	if err != nil {
+		return "", nil, err
+	}
+	return signed, claims, nil
 }
 
 // ValidateToken validates a JWT token
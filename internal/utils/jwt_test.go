@@ -117,3 +117,25 @@ func TestJWTManager_ValidateToken(t *testing.T) {
 		})
 	}
 }
+
+func TestJWTManager_IssueToken(t *testing.T) {
+	jwtManager := NewJWTManager(&config.JWTConfig{Secret: "test-secret-key", Expiration: time.Hour})
+
+	token, claims, err := jwtManager.IssueToken("507f1f77bcf86cd799439011", "test@example.com", "user", nil, false)
+	require.NoError(t, err)
+	assert.NotEmpty(t, claims.ID)
+
+	validated, err := jwtManager.ValidateToken(token)
+	require.NoError(t, err)
+	assert.Equal(t, claims.ID, validated.ID)
+	assert.False(t, validated.MFA)
+
+	token, other, err := jwtManager.IssueToken("507f1f77bcf86cd799439011", "test@example.com", "support", []string{"returns:manage"}, true)
+	require.NoError(t, err)
+	assert.NotEqual(t, claims.ID, other.ID)
+
+	validated, err = jwtManager.ValidateToken(token)
+	require.NoError(t, err)
+	assert.True(t, validated.MFA)
+	assert.Equal(t, []string{"returns:manage"}, validated.Permissions)
+}
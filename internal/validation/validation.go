@@ -0,0 +1,88 @@
+package validation
+
+import (
+	"reflect"
+	"strings"
+
+	"ecommerce-backend/internal/models"
+
+	"github.com/go-playground/validator/v10"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// rules are the store's own validate tags
+var rules = map[string]validator.Func{
+	"category": func(fl validator.FieldLevel) bool {
+		return models.IsValidCategory(fl.Field().String())
+	},
+	"objectid": func(fl validator.FieldLevel) bool {
+		return primitive.IsValidObjectID(fl.Field().String())
+	},
+	"currency": func(fl validator.FieldLevel) bool {
+		return models.IsValidCurrency(strings.ToUpper(fl.Field().String()))
+	},
+}
+
+// New returns a validator for the validate struct tags that reports fields by their
+// JSON names and knows the category, objectid and currency rules
+func New() *validator.Validate {
+	v := validator.New()
+	v.RegisterTagNameFunc(jsonName)
+	for tag, fn := range rules {
+		if err := v.RegisterValidation(tag, fn); err != nil {
+			panic(err)
+		}
+	}
+	return v
+}
+
+// StructValidator validates request bodies as gin binds them, in place of gin's
+// default validator which only reads binding tags
+type StructValidator struct {
+	validate *validator.Validate
+}
+
+// NewStructValidator creates a StructValidator; install it with binding.Validator
+func NewStructValidator() *StructValidator {
+	return &StructValidator{
+		validate: New(),
+	}
+}
+
+// ValidateStruct validates a bound struct, or each struct of a bound slice
+func (v *StructValidator) ValidateStruct(obj any) error {
+	value := reflect.ValueOf(obj)
+	for value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+
+	switch value.Kind() {
+	case reflect.Struct:
+		return v.validate.Struct(value.Interface())
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			if err := v.ValidateStruct(value.Index(i).Interface()); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Engine returns the underlying validator
+func (v *StructValidator) Engine() any {
+	return v.validate
+}
+
+// jsonName names a field by its JSON key. Untagged fields, such as embedded request
+// structs, keep their Go name.
+func jsonName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "-" {
+		return ""
+	}
+	return name
+}
@@ -0,0 +1,110 @@
+package validation
+
+import (
+	"encoding/json"
+	"testing"
+
+	apperrors "ecommerce-backend/internal/errors"
+	"ecommerce-backend/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStructValidator(t *testing.T) {
+	const productID = "65f1c2a4e4b0a1b2c3d4e5f6"
+
+	tests := []struct {
+		name            string
+		body            string
+		target          any
+		expectedDetails []apperrors.FieldError
+	}{
+		{
+			name:   "valid cart",
+			body:   `{"items": [{"product_id": "` + productID + `", "quantity": 2}], "currency": "eur"}`,
+			target: &models.CartRequest{},
+		},
+		{
+			name:   "invalid product ID and quantity",
+			body:   `{"items": [{"product_id": "abc", "quantity": 0}]}`,
+			target: &models.CartRequest{},
+			expectedDetails: []apperrors.FieldError{
+				{Field: "items[0].product_id", Rule: "objectid", Message: "must be a valid ID"},
+				{Field: "items[0].quantity", Rule: "required", Message: "is required"},
+			},
+		},
+		{
+			name:   "unsupported currency",
+			body:   `{"items": [{"product_id": "` + productID + `", "quantity": 1}], "currency": "XYZ"}`,
+			target: &models.CartRequest{},
+			expectedDetails: []apperrors.FieldError{
+				{Field: "currency", Rule: "currency", Message: "must be a supported ISO 4217 currency code"},
+			},
+		},
+		{
+			name:   "embedded cart fields keep their JSON path",
+			body:   `{"items": [], "shipping_address_id": "` + productID + `"}`,
+			target: &models.CheckoutRequest{},
+			expectedDetails: []apperrors.FieldError{
+				{Field: "items", Rule: "min", Message: "must contain at least 1 item"},
+				{Field: "shipping_method_id", Rule: "required_with", Message: "is required"},
+			},
+		},
+		{
+			name:   "slide duration out of range",
+			body:   `{"slide_duration": 31}`,
+			target: &models.UpdateSliderSettingsRequest{},
+			expectedDetails: []apperrors.FieldError{
+				{Field: "slide_duration", Rule: "max", Message: "must be at most 30"},
+			},
+		},
+		{
+			name:   "unknown category",
+			body:   `{"name": "Lamp", "category": "garden", "description": "A lamp for the desk"}`,
+			target: &models.CreateProductRequest{},
+			expectedDetails: []apperrors.FieldError{
+				{Field: "category", Rule: "category", Message: "must be a valid product category"},
+			},
+		},
+		{
+			name:   "product on sale without stock",
+			body:   `{"name": "Lamp", "category": "home", "description": "A lamp for the desk", "in_stock": true}`,
+			target: &models.CreateProductRequest{},
+			expectedDetails: []apperrors.FieldError{
+				{Field: "stock", Rule: "required_if", Message: "is required"},
+			},
+		},
+		{
+			name:   "nested money currency",
+			body:   `{"name": "Lamp", "category": "home", "description": "A lamp for the desk", "price": {"amount": 1999, "currency": "dollars"}}`,
+			target: &models.CreateProductRequest{},
+			expectedDetails: []apperrors.FieldError{
+				{Field: "price.currency", Rule: "currency", Message: "must be a supported ISO 4217 currency code"},
+			},
+		},
+	}
+
+	v := NewStructValidator()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.NoError(t, json.Unmarshal([]byte(tt.body), tt.target))
+
+			err := v.ValidateStruct(tt.target)
+			if tt.expectedDetails == nil {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Equal(t, tt.expectedDetails, apperrors.Validation(err).Details)
+		})
+	}
+}
+
+func TestStructValidator_Slices(t *testing.T) {
+	v := NewStructValidator()
+
+	assert.NoError(t, v.ValidateStruct(nil))
+	assert.NoError(t, v.ValidateStruct([]models.CartItemRequest{{ProductID: "65f1c2a4e4b0a1b2c3d4e5f6", Quantity: 1}}))
+	assert.Error(t, v.ValidateStruct([]models.CartItemRequest{{ProductID: "65f1c2a4e4b0a1b2c3d4e5f6", Quantity: 1}, {Quantity: 1}}))
+}
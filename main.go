@@ -1,12 +1,22 @@
 package main
 
 import (
+	"fmt"
 	"log"
+	"os"
+	"text/tabwriter"
 
+	"ecommerce-backend/internal/config"
 	"ecommerce-backend/internal/server"
+
+	"github.com/joho/godotenv"
 )
 
 func main() {
+	if len(os.Args) > 1 {
+		os.Exit(runCommand(os.Args[1:]))
+	}
+
 	// Create and start server
 	srv, err := server.New()
 	if err != nil {
@@ -18,3 +28,36 @@ func main() {
 		log.Fatal("Failed to start server:", err)
 	}
 }
+
+// runCommand runs a command-line subcommand and returns the exit code
+func runCommand(args []string) int {
+	if len(args) == 2 && args[0] == "config" && args[1] == "check" {
+		return configCheck()
+	}
+
+	fmt.Fprintln(os.Stderr, "Usage: ecommerce-backend [config check]")
+	return 2
+}
+
+// configCheck prints the effective configuration with secrets redacted, then every
+// problem found. It exits non-zero when the server would refuse to start.
+func configCheck() int {
+	_ = godotenv.Load()
+
+	cfg, err := config.Load()
+	if cfg != nil {
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "KEY\tVALUE\tSOURCE")
+		for _, setting := range cfg.Settings() {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", setting.Key, setting.Value, setting.Source)
+		}
+		w.Flush()
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "\nConfiguration is invalid:\n%v\n", err)
+		return 1
+	}
+	fmt.Println("\nConfiguration is valid")
+	return 0
+}